@@ -0,0 +1,575 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/junghwan16/test-server/internal/config"
+	"github.com/junghwan16/test-server/internal/identity/handler"
+	"github.com/junghwan16/test-server/internal/shared/csrf"
+	"github.com/junghwan16/test-server/internal/shared/domain"
+	"github.com/junghwan16/test-server/internal/shared/infrastructure/metrics"
+	"github.com/junghwan16/test-server/internal/shared/infrastructure/ratelimit"
+	"github.com/junghwan16/test-server/internal/shared/problem"
+)
+
+// newTestServer starts the full HTTP stack on in-memory storage; opts adjust the config
+func newTestServer(t *testing.T, opts ...func(*config.Config)) *httptest.Server {
+	t.Helper()
+
+	cfg := &config.Config{
+		Storage:   config.StorageConfig{Backend: "memory"},
+		Session:   config.SessionConfig{TTL: 3600},
+		RateLimit: config.RateLimitConfig{RequestsPerSecond: 1000, Burst: 1000},
+		Server:    config.ServerConfig{RequestTimeout: 10 * time.Second, ProxyHeader: "x-forwarded-for"},
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	eventBus := domain.NewSimpleEventBus()
+	eventsHandler := handler.NewEventsHandler(10, time.Second, false)
+	eventBus.Subscribe(eventsHandler.HandleEvent)
+	m := metrics.New()
+	eventBus.Subscribe(countEvents(m))
+
+	router, err := newRouter(cfg, logger, newMemoryStores(eventBus), ratelimit.NewMemory(), eventsHandler, m)
+	if err != nil {
+		t.Fatalf("failed to build router: %v", err)
+	}
+	srv := httptest.NewServer(router)
+	t.Cleanup(func() {
+		eventsHandler.Close()
+		srv.Close()
+	})
+	return srv
+}
+
+// newTestClient keeps cookies and, like a browser app, echoes the CSRF
+// cookie in the X-CSRF-Token header
+func newTestClient(t *testing.T) *http.Client {
+	t.Helper()
+	jar, _ := cookiejar.New(nil)
+	return &http.Client{Jar: jar, Transport: &csrfTransport{jar: jar}}
+}
+
+// newPlainClient keeps cookies but sends no CSRF token
+func newPlainClient(t *testing.T) *http.Client {
+	t.Helper()
+	jar, _ := cookiejar.New(nil)
+	return &http.Client{Jar: jar}
+}
+
+type csrfTransport struct {
+	jar http.CookieJar
+}
+
+func (t *csrfTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	for _, c := range t.jar.Cookies(r.URL) {
+		if c.Name == csrf.CookieName {
+			r = r.Clone(r.Context())
+			r.Header.Set(csrf.Header, c.Value)
+		}
+	}
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func postJSON(t *testing.T, c *http.Client, url string, body any) *http.Response {
+	t.Helper()
+	data, _ := json.Marshal(body)
+	resp, err := c.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("POST %s: %v", url, err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func get(t *testing.T, c *http.Client, url string) *http.Response {
+	t.Helper()
+	resp, err := c.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestE2E_SignupLoginLogout(t *testing.T) {
+	// Given: 외부 서비스 없이 실행 중인 서버
+	srv := newTestServer(t)
+	c := newTestClient(t)
+	creds := map[string]string{"email": "e2e@example.com", "password": "password123"}
+
+	// When: 가입 후 로그인
+	if resp := postJSON(t, c, srv.URL+"/v1/auth/signup", creds); resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201 on signup, got %d", resp.StatusCode)
+	}
+	if resp := postJSON(t, c, srv.URL+"/v1/auth/signup", creds); resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected 409 on duplicate signup, got %d", resp.StatusCode)
+	}
+	if resp := postJSON(t, c, srv.URL+"/v1/auth/login", creds); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 on login, got %d", resp.StatusCode)
+	}
+
+	// Then: 세션으로 내 정보 조회 가능
+	resp := get(t, c, srv.URL+"/v1/me")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 on /me, got %d", resp.StatusCode)
+	}
+	var me handler.Envelope[handler.UserResponse]
+	json.NewDecoder(resp.Body).Decode(&me)
+	if me.Data.Email != "e2e@example.com" {
+		t.Errorf("expected email e2e@example.com, got %v", me.Data.Email)
+	}
+
+	// And: 로그아웃 후에는 인증 실패
+	postJSON(t, c, srv.URL+"/v1/auth/logout", nil)
+	if resp := get(t, c, srv.URL+"/v1/me"); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401 after logout, got %d", resp.StatusCode)
+	}
+}
+
+func TestE2E_PasswordReset(t *testing.T) {
+	// Given: 가입된 사용자
+	srv := newTestServer(t)
+	c := newTestClient(t)
+	postJSON(t, c, srv.URL+"/v1/auth/signup", map[string]string{"email": "reset@example.com", "password": "password123"})
+
+	// When: 재설정 토큰을 받아 비밀번호 변경
+	resp := postJSON(t, c, srv.URL+"/v1/password/reset/request", map[string]string{"email": "reset@example.com"})
+	var body handler.Envelope[handler.TokenResponse]
+	json.NewDecoder(resp.Body).Decode(&body)
+	if body.Data.Token == "" {
+		t.Fatal("expected reset token")
+	}
+	resp = postJSON(t, c, srv.URL+"/v1/password/reset/confirm", map[string]string{
+		"token":        body.Data.Token,
+		"new_password": "newpassword123",
+	})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 on reset, got %d", resp.StatusCode)
+	}
+
+	// Then: 새 비밀번호로만 로그인 가능하고 토큰은 재사용 불가
+	if resp := postJSON(t, c, srv.URL+"/v1/auth/login", map[string]string{"email": "reset@example.com", "password": "password123"}); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401 with old password, got %d", resp.StatusCode)
+	}
+	if resp := postJSON(t, c, srv.URL+"/v1/auth/login", map[string]string{"email": "reset@example.com", "password": "newpassword123"}); resp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200 with new password, got %d", resp.StatusCode)
+	}
+	resp = postJSON(t, c, srv.URL+"/v1/password/reset/confirm", map[string]string{
+		"token":        body.Data.Token,
+		"new_password": "anotherpassword123",
+	})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 on token reuse, got %d", resp.StatusCode)
+	}
+}
+
+func TestE2E_ProblemDetails(t *testing.T) {
+	srv := newTestServer(t)
+	c := newTestClient(t)
+
+	t.Run("필드별 검증 오류", func(t *testing.T) {
+		// When: 이메일과 비밀번호가 모두 잘못된 가입
+		resp := postJSON(t, c, srv.URL+"/v1/auth/signup", map[string]string{"email": "not-an-email", "password": "short"})
+
+		// Then: problem+json 으로 두 필드가 함께 보고됨
+		var p problem.Problem
+		decodeProblem(t, resp, &p)
+		if p.Status != http.StatusBadRequest || p.Code != "validation_failed" {
+			t.Errorf("expected 400 validation_failed, got %d %s", p.Status, p.Code)
+		}
+		codes := map[string]string{}
+		for _, e := range p.Errors {
+			codes[e.Field] = e.Code
+		}
+		if codes["email"] != "invalid_email" || codes["password"] != "password_too_short" {
+			t.Errorf("expected email and password field errors, got %+v", p.Errors)
+		}
+	})
+
+	t.Run("중복 가입과 요청 ID", func(t *testing.T) {
+		// Given: 가입된 이메일
+		creds := map[string]string{"email": "problem@example.com", "password": "password123"}
+		postJSON(t, c, srv.URL+"/v1/auth/signup", creds)
+
+		// When: 프록시가 붙인 요청 ID 와 함께 재가입
+		data, _ := json.Marshal(creds)
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/v1/auth/signup", bytes.NewReader(data))
+		req.Header.Set("X-Request-ID", "req-123")
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Fatalf("POST signup: %v", err)
+		}
+		defer resp.Body.Close()
+
+		// Then: 안정적인 코드와 요청 ID
+		var p problem.Problem
+		decodeProblem(t, resp, &p)
+		if p.Status != http.StatusConflict || p.Code != "email_already_registered" {
+			t.Errorf("expected 409 email_already_registered, got %d %s", p.Status, p.Code)
+		}
+		if p.RequestID != "req-123" || p.Instance != "/v1/auth/signup" {
+			t.Errorf("expected request ID and instance, got %q %q", p.RequestID, p.Instance)
+		}
+	})
+
+	t.Run("인증 실패", func(t *testing.T) {
+		// When: 세션 없이 내 정보 조회
+		resp := get(t, newTestClient(t), srv.URL+"/v1/me")
+
+		// Then: unauthenticated
+		var p problem.Problem
+		decodeProblem(t, resp, &p)
+		if p.Status != http.StatusUnauthorized || p.Code != "unauthenticated" {
+			t.Errorf("expected 401 unauthenticated, got %d %s", p.Status, p.Code)
+		}
+	})
+}
+
+func TestE2E_Versioning(t *testing.T) {
+	srv := newTestServer(t)
+	c := newTestClient(t)
+
+	t.Run("버전 경로", func(t *testing.T) {
+		// When: /v1 경로로 가입
+		resp := postJSON(t, c, srv.URL+"/v1/auth/signup", map[string]string{"email": "v1@example.com", "password": "password123"})
+
+		// Then: data 로 감싼 사용자와 버전 헤더, 폐기 표시는 없음
+		var body handler.Envelope[handler.UserResponse]
+		json.NewDecoder(resp.Body).Decode(&body)
+		if resp.StatusCode != http.StatusCreated || body.Data.Email != "v1@example.com" {
+			t.Errorf("expected 201 with user data, got %d %+v", resp.StatusCode, body)
+		}
+		if resp.Header.Get("API-Version") != "1" || resp.Header.Get("Deprecation") != "" {
+			t.Errorf("expected API-Version 1 without deprecation, got %q %q", resp.Header.Get("API-Version"), resp.Header.Get("Deprecation"))
+		}
+	})
+
+	t.Run("버전 없는 경로", func(t *testing.T) {
+		// When: 접두사 없이 로그인
+		resp := postJSON(t, c, srv.URL+"/auth/login", map[string]string{"email": "v1@example.com", "password": "password123"})
+
+		// Then: 최신 버전으로 처리되고 폐기 예정으로 표시됨
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200, got %d", resp.StatusCode)
+		}
+		if resp.Header.Get("Deprecation") != "true" || resp.Header.Get("Link") != `</v1/auth/login>; rel="successor-version"` {
+			t.Errorf("expected deprecation headers, got %q %q", resp.Header.Get("Deprecation"), resp.Header.Get("Link"))
+		}
+	})
+
+	t.Run("지원하지 않는 버전", func(t *testing.T) {
+		for name, req := range map[string]func() *http.Request{
+			"경로": func() *http.Request {
+				r, _ := http.NewRequest(http.MethodGet, srv.URL+"/v9/me", nil)
+				return r
+			},
+			"헤더": func() *http.Request {
+				r, _ := http.NewRequest(http.MethodGet, srv.URL+"/me", nil)
+				r.Header.Set("API-Version", "9")
+				return r
+			},
+		} {
+			t.Run(name, func(t *testing.T) {
+				// When: 없는 버전 요청
+				resp, err := c.Do(req())
+				if err != nil {
+					t.Fatalf("GET: %v", err)
+				}
+				defer resp.Body.Close()
+
+				// Then: unsupported_api_version
+				var p problem.Problem
+				decodeProblem(t, resp, &p)
+				if p.Status != http.StatusNotFound || p.Code != "unsupported_api_version" {
+					t.Errorf("expected 404 unsupported_api_version, got %d %s", p.Status, p.Code)
+				}
+			})
+		}
+	})
+}
+
+func TestE2E_RequestID(t *testing.T) {
+	srv := newTestServer(t)
+	c := newTestClient(t)
+
+	tests := []struct {
+		name   string
+		sent   string
+		reused bool
+	}{
+		{name: "전달된 ID 재사용", sent: "proxy-7f3a", reused: true},
+		{name: "ID 없음", sent: ""},
+		{name: "허용되지 않는 문자", sent: "bad id <script>"},
+		{name: "너무 긴 ID", sent: strings.Repeat("a", 129)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When: 요청 ID 헤더와 함께 요청
+			req, _ := http.NewRequest(http.MethodGet, srv.URL+"/health/live", nil)
+			if tt.sent != "" {
+				req.Header["X-Request-Id"] = []string{tt.sent}
+			}
+			resp, err := c.Do(req)
+			if err != nil {
+				t.Fatalf("GET: %v", err)
+			}
+			resp.Body.Close()
+
+			// Then: 올바른 ID 는 그대로, 아니면 새로 발급해 응답 헤더로 돌려줌
+			got := resp.Header.Get("X-Request-ID")
+			if tt.reused && got != tt.sent {
+				t.Errorf("expected %q echoed, got %q", tt.sent, got)
+			}
+			if !tt.reused {
+				if _, err := uuid.Parse(got); err != nil {
+					t.Errorf("expected a generated UUID, got %q", got)
+				}
+			}
+		})
+	}
+}
+
+func TestE2E_Metrics(t *testing.T) {
+	// Given: 가입한 사용자
+	srv := newTestServer(t)
+	c := newTestClient(t)
+	creds := map[string]string{"email": "metrics@example.com", "password": "password123"}
+	postJSON(t, c, srv.URL+"/v1/auth/signup", creds)
+
+	// When: 로그인 성공 한 번, 잘못된 비밀번호 한 번
+	postJSON(t, c, srv.URL+"/v1/auth/login", creds)
+	postJSON(t, c, srv.URL+"/v1/auth/login", map[string]string{"email": "metrics@example.com", "password": "wrongpassword"})
+
+	// Then: 도메인 이벤트로 집계된 로그인 수와 라우트별 HTTP 지표
+	resp := get(t, c, srv.URL+"/metrics")
+	body, _ := io.ReadAll(resp.Body)
+	for _, want := range []string{
+		`auth_logins_total{reason="",result="success"} 1`,
+		`auth_logins_total{reason="wrong_password",result="failure"} 1`,
+		`domain_events_total{type="identity.user.registered"} 1`,
+		`http_requests_total{method="POST",route="POST /v1/auth/login",status="200"} 1`,
+		`http_requests_total{method="POST",route="POST /v1/auth/login",status="401"} 1`,
+		`http_request_duration_seconds_count{method="POST",route="POST /v1/auth/signup",status="201"} 1`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("expected metrics to contain %s", want)
+		}
+	}
+}
+
+func TestE2E_RateLimit(t *testing.T) {
+	// Given: 가입한 사용자
+	srv := newTestServer(t)
+	c := newTestClient(t)
+	postJSON(t, c, srv.URL+"/v1/auth/signup", map[string]string{"email": "limited@example.com", "password": "password123"})
+	wrong := map[string]string{"email": "limited@example.com", "password": "wrongpassword"}
+
+	// When: 이메일별 한도를 넘도록 잘못된 비밀번호로 로그인
+	var resp *http.Response
+	for range routePolicies["login-email"].Limit.Burst + 1 {
+		resp = postJSON(t, c, srv.URL+"/v1/auth/login", wrong)
+	}
+
+	// Then: 429 problem 과 재시도 헤더, 지표
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", resp.StatusCode)
+	}
+	if resp.Header.Get("Retry-After") == "" || resp.Header.Get("RateLimit-Remaining") != "0" {
+		t.Errorf("expected Retry-After and RateLimit-Remaining 0, got %v", resp.Header)
+	}
+	var p problem.Problem
+	decodeProblem(t, resp, &p)
+	if p.Code != "rate_limited" {
+		t.Errorf("expected rate_limited, got %q", p.Code)
+	}
+
+	body, _ := io.ReadAll(get(t, c, srv.URL+"/metrics").Body)
+	if !strings.Contains(string(body), `http_rate_limited_total{policy="login-email"} 1`) {
+		t.Error("expected the rejection to be counted by policy")
+	}
+}
+
+func TestE2E_CORS(t *testing.T) {
+	// Given: SPA 출처를 허용한 서버
+	srv := newTestServer(t, func(cfg *config.Config) {
+		cfg.CORS = config.CORSConfig{AllowedOrigins: []string{"https://app.example.com"}, AllowCredentials: true}
+	})
+
+	// When: 로그인 라우트로 프리플라이트
+	req, _ := http.NewRequest("OPTIONS", srv.URL+"/v1/auth/login", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	req.Header.Set("Access-Control-Request-Headers", "Content-Type")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("OPTIONS: %v", err)
+	}
+	resp.Body.Close()
+
+	// Then: 라우터의 405 대신 204 와 허용 헤더, 보안 헤더
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", resp.StatusCode)
+	}
+	if resp.Header.Get("Access-Control-Allow-Methods") != "POST" || resp.Header.Get("Access-Control-Allow-Credentials") != "true" {
+		t.Errorf("expected POST with credentials allowed, got %v", resp.Header)
+	}
+	if resp.Header.Get("X-Content-Type-Options") != "nosniff" {
+		t.Error("expected security headers on preflight responses")
+	}
+}
+
+func TestE2E_SessionCookie(t *testing.T) {
+	creds := map[string]string{"email": "cookie@example.com", "password": "password123"}
+
+	for _, tc := range []struct {
+		name       string
+		session    config.SessionConfig
+		wantMode   http.SameSite
+		wantSecure bool
+	}{
+		{name: "기본값은 같은 사이트 전용", session: config.SessionConfig{TTL: 3600}, wantMode: http.SameSiteStrictMode},
+		{
+			name:       "다른 사이트 앱용 None+Secure",
+			session:    config.SessionConfig{TTL: 3600, CookieSameSite: "none", CookieSecure: true},
+			wantMode:   http.SameSiteNoneMode,
+			wantSecure: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given: 쿠키 설정을 적용한 서버
+			srv := newTestServer(t, func(cfg *config.Config) { cfg.Session = tc.session })
+			postJSON(t, newTestClient(t), srv.URL+"/v1/auth/signup", creds)
+
+			// When: 로그인
+			resp := postJSON(t, newPlainClient(t), srv.URL+"/v1/auth/login", creds)
+
+			// Then: 세션과 CSRF 쿠키 모두 설정한 속성
+			cookies := resp.Cookies()
+			if len(cookies) != 2 {
+				t.Fatalf("expected session and CSRF cookies, got %v", cookies)
+			}
+			for _, c := range cookies {
+				if c.SameSite != tc.wantMode || c.Secure != tc.wantSecure {
+					t.Errorf("expected %s with SameSite %v and Secure %v, got %v", c.Name, tc.wantMode, tc.wantSecure, c)
+				}
+			}
+		})
+	}
+}
+
+func TestE2E_CSRF(t *testing.T) {
+	srv := newTestServer(t)
+	creds := map[string]string{"email": "csrf@example.com", "password": "password123"}
+	postJSON(t, newTestClient(t), srv.URL+"/v1/auth/signup", creds)
+
+	t.Run("로그인 시 토큰 발급", func(t *testing.T) {
+		// When: 로그인
+		resp := postJSON(t, newPlainClient(t), srv.URL+"/v1/auth/login", creds)
+
+		// Then: 헤더와 쿠키로 같은 토큰
+		token := resp.Header.Get(csrf.Header)
+		if token == "" {
+			t.Fatal("expected a CSRF token header")
+		}
+		var cookie string
+		for _, c := range resp.Cookies() {
+			if c.Name == csrf.CookieName {
+				cookie = c.Value
+			}
+		}
+		if cookie != token {
+			t.Errorf("expected the cookie to carry the header's token, got %q", cookie)
+		}
+	})
+
+	t.Run("토큰 없는 요청 거부", func(t *testing.T) {
+		// Given: 로그인했지만 토큰을 보내지 않는 클라이언트
+		c := newPlainClient(t)
+		postJSON(t, c, srv.URL+"/v1/auth/login", creds)
+
+		// When: 인증 확인 요청
+		resp := postJSON(t, c, srv.URL+"/v1/verification/request", nil)
+
+		// Then: 403 csrf_failed
+		if resp.StatusCode != http.StatusForbidden {
+			t.Fatalf("expected 403, got %d", resp.StatusCode)
+		}
+		var p problem.Problem
+		decodeProblem(t, resp, &p)
+		if p.Code != "csrf_failed" {
+			t.Errorf("expected csrf_failed, got %q", p.Code)
+		}
+	})
+
+	t.Run("다른 사이트 출처 거부", func(t *testing.T) {
+		// Given: 토큰을 보내는 로그인한 클라이언트
+		c := newTestClient(t)
+		postJSON(t, c, srv.URL+"/v1/auth/login", creds)
+
+		// When: 다른 출처에서 로그아웃
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/v1/auth/logout", nil)
+		req.Header.Set("Origin", "https://evil.example.net")
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Fatalf("POST logout: %v", err)
+		}
+		resp.Body.Close()
+
+		// Then: 403 이고 세션은 유지됨
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("expected 403, got %d", resp.StatusCode)
+		}
+		if resp := get(t, c, srv.URL+"/v1/me"); resp.StatusCode != http.StatusOK {
+			t.Errorf("expected the session to survive, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("세션 쿠키와 임의의 베어러 토큰", func(t *testing.T) {
+		// Given: 토큰 없이 로그인한 클라이언트
+		c := newPlainClient(t)
+		postJSON(t, c, srv.URL+"/v1/auth/login", creds)
+
+		// When: 쿠키에 더해 아무 Authorization 헤더나 붙여 요청
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/v1/verification/request", nil)
+		req.Header.Set("Authorization", "Bearer dummy")
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Fatalf("POST: %v", err)
+		}
+		resp.Body.Close()
+
+		// Then: CSRF 검사를 건너뛰지 않고 403
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("expected 403, got %d", resp.StatusCode)
+		}
+	})
+}
+
+// decodeProblem checks the media type and decodes a problem details body
+func decodeProblem(t *testing.T, resp *http.Response, p *problem.Problem) {
+	t.Helper()
+	if ct := resp.Header.Get("Content-Type"); ct != problem.ContentType {
+		t.Fatalf("expected %s, got %q", problem.ContentType, ct)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(p); err != nil {
+		t.Fatalf("failed to decode problem: %v", err)
+	}
+	if p.Status != resp.StatusCode {
+		t.Errorf("expected body status %d to match response, got %d", resp.StatusCode, p.Status)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+
+	"github.com/junghwan16/test-server/internal/config"
+	"github.com/junghwan16/test-server/internal/server"
+	"github.com/junghwan16/test-server/internal/shared/csrf"
+	"github.com/junghwan16/test-server/internal/shared/infrastructure/metrics"
+	"github.com/junghwan16/test-server/internal/shared/infrastructure/ratelimit"
+)
+
+// guards are the per-route protections newMux applies
+type guards struct {
+	limits     *rateLimits
+	csrfTokens *csrf.Tokens
+	csrf       func(http.Handler) http.Handler
+}
+
+// newGuards builds the rate limit policies and CSRF protection from cfg
+func newGuards(cfg *config.Config, limiter ratelimit.Limiter, m *metrics.Metrics) (guards, error) {
+	limits, err := newRateLimits(&cfg.RateLimit, limiter, m)
+	if err != nil {
+		return guards{}, err
+	}
+
+	tokens, err := newCSRFTokens(&cfg.Session)
+	if err != nil {
+		return guards{}, err
+	}
+	check, err := server.CSRF(tokens, server.CSRFOptions{TrustedOrigins: cfg.CORS.AllowedOrigins})
+	if err != nil {
+		return guards{}, err
+	}
+
+	return guards{limits: limits, csrfTokens: tokens, csrf: check}, nil
+}
+
+// newCSRFTokens signs CSRF tokens with CSRF_SECRET, or with a random key when
+// none is set, which is only safe while sessions live in this process
+func newCSRFTokens(cfg *config.SessionConfig) (*csrf.Tokens, error) {
+	if cfg.CSRFSecret == "" {
+		key := make([]byte, csrf.MinKeySize)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("generate CSRF key: %w", err)
+		}
+		return csrf.NewTokens(key)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(cfg.CSRFSecret)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSRF_SECRET: %w", err)
+	}
+	return csrf.NewTokens(key)
+}
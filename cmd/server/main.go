@@ -7,19 +7,28 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	gormtracing "gorm.io/plugin/opentelemetry/tracing"
 
 	"github.com/junghwan16/test-server/internal/config"
-	"github.com/junghwan16/test-server/internal/identity/application"
+	"github.com/junghwan16/test-server/internal/identity/domain/session"
+	"github.com/junghwan16/test-server/internal/identity/domain/user"
 	"github.com/junghwan16/test-server/internal/identity/handler"
 	"github.com/junghwan16/test-server/internal/identity/infrastructure/persistence"
-	"github.com/junghwan16/test-server/internal/server"
 	"github.com/junghwan16/test-server/internal/shared/domain"
+	"github.com/junghwan16/test-server/internal/shared/infrastructure/eventbus"
+	"github.com/junghwan16/test-server/internal/shared/infrastructure/keyring"
+	"github.com/junghwan16/test-server/internal/shared/infrastructure/metrics"
+	"github.com/junghwan16/test-server/internal/shared/infrastructure/ratelimit"
+	"github.com/junghwan16/test-server/internal/shared/infrastructure/scheduler"
+	"github.com/junghwan16/test-server/internal/shared/infrastructure/tracing"
+	"github.com/junghwan16/test-server/internal/shared/requestid"
 )
 
 func main() {
@@ -29,78 +38,212 @@ func main() {
 	}
 
 	logger := newLogger(cfg)
+	slog.SetDefault(logger)
 
-	db, err := connectDB(cfg, logger)
-	if err != nil {
-		logger.Error("failed to connect database", "error", err)
-		os.Exit(1)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(cfg, logger, os.Args[2:]))
 	}
-
-	if err := db.AutoMigrate(
-		&persistence.UserModel{},
-		&persistence.EmailVerificationModel{},
-		&persistence.PasswordResetModel{},
-	); err != nil {
-		logger.Error("failed to migrate database", "error", err)
-		os.Exit(1)
+	if len(os.Args) > 1 && os.Args[1] == "reencrypt" {
+		os.Exit(runReencrypt(cfg, logger))
 	}
-	logger.Info("database migrated")
 
-	rdb, err := connectRedis(cfg, logger)
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		Exporter:    cfg.Tracing.Exporter,
+		File:        cfg.Tracing.File,
+		SampleRatio: cfg.Tracing.SampleRatio,
+		ServiceName: "test-server",
+	})
 	if err != nil {
-		logger.Error("failed to connect redis", "error", err)
+		logger.Error("failed to set up tracing", "error", err)
 		os.Exit(1)
 	}
 
-	eventBus := domain.NewSimpleEventBus()
+	var (
+		db   *gorm.DB
+		rdb  redis.UniversalClient
+		keys *keyring.Keyring
+	)
 
-	userRepo := persistence.NewUserRepository(db, eventBus)
-	sessionRepo := persistence.NewRedisSessionRepository(rdb)
-	emailVerifRepo := persistence.NewEmailVerificationRepository(db)
-	passwordResetRepo := persistence.NewPasswordResetRepository(db)
+	if !cfg.Storage.IsMemory() {
+		keys, err = loadKeyring(cfg)
+		if err != nil {
+			logger.Error("failed to load encryption keys", "error", err)
+			os.Exit(1)
+		}
+
+		db, err = connectDB(cfg, logger)
+		if err != nil {
+			logger.Error("failed to connect database", "error", err)
+			os.Exit(1)
+		}
 
-	userSvc := application.NewUserService(userRepo)
-	authSvc := application.NewAuthService(userRepo, sessionRepo, cfg.Session.TTL)
-	verifSvc := application.NewVerificationService(
-		userRepo,
-		emailVerifRepo,
-		passwordResetRepo,
-		24*time.Hour,
-		1*time.Hour,
+		if err := checkSchema(db); err != nil {
+			logger.Error("refusing to start: run `server migrate up` first", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	sessionsInRedis := !cfg.Storage.IsMemory() && !cfg.Session.InDatabase()
+	userCacheInRedis := !cfg.Storage.IsMemory() && cfg.UserCache.Enabled() && cfg.UserCache.Redis
+	rateLimitsInRedis := !cfg.Storage.IsMemory() && cfg.RateLimit.InRedis()
+	if sessionsInRedis || userCacheInRedis || rateLimitsInRedis || cfg.EventBus.IsRedis() {
+		rdb, err = connectRedis(cfg, logger)
+		if err != nil {
+			logger.Error("failed to connect redis", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	registry := domain.NewEventRegistry()
+	user.RegisterEvents(registry)
+	session.RegisterEvents(registry)
+
+	var (
+		eventBus  domain.EventBus
+		streamBus *eventbus.RedisStreamEventBus
+		localBus  *domain.SimpleEventBus
 	)
+	if cfg.EventBus.IsRedis() {
+		opts := eventbus.RedisStreamOptions{
+			Stream:        cfg.EventBus.Stream,
+			MaxLen:        cfg.EventBus.MaxLen,
+			Consumer:      cfg.EventBus.Consumer,
+			BlockTimeout:  cfg.EventBus.BlockTimeout,
+			ClaimMinIdle:  cfg.EventBus.ClaimMinIdle,
+			MaxDeliveries: 10,
+		}
+		if keys != nil {
+			opts.Cipher = keys
+		}
+		streamBus = eventbus.NewRedisStreamEventBus(rdb, registry, opts, logger)
+		eventBus = streamBus
+	} else {
+		localBus = domain.NewSimpleEventBus()
+		eventBus = localBus
+	}
 
-	authHandler := handler.NewAuthHandler(userSvc, authSvc, cfg.Session.TTL)
-	usersHandler := handler.NewUsersHandler(userSvc)
-	verifHandler := handler.NewVerificationHandler(verifSvc)
+	m := metrics.New()
 
-	mux := http.NewServeMux()
+	// subscribeAll delivers every event to this instance regardless of backend
+	subscribeAll := func(name string, h domain.EventHandler) {
+		h = m.InstrumentHandler(name, h)
+		if streamBus != nil {
+			streamBus.SubscribeBroadcast(h)
+		} else {
+			localBus.Subscribe(h)
+		}
+	}
+	// subscribeOnce delivers each event to one instance, so fleet-wide counts add up
+	subscribeOnce := func(name string, h domain.EventHandler) {
+		h = m.InstrumentHandler(name, h)
+		if streamBus != nil {
+			streamBus.Subscribe(name, h)
+		} else {
+			localBus.Subscribe(h)
+		}
+	}
+
+	var st stores
+	if cfg.Storage.IsMemory() {
+		logger.Warn("using in-memory storage; data will be lost on restart")
+		st = newMemoryStores(eventBus)
+	} else {
+		st = newPersistentStores(db, rdb, eventBus, keys, cfg.Session.InDatabase())
+	}
+
+	var (
+		userCache     *persistence.CachedUserRepository
+		cacheNotifier *persistence.PostgresUserCacheNotifier
+	)
+	if !cfg.Storage.IsMemory() && cfg.UserCache.Enabled() {
+		opts := persistence.UserCacheOptions{
+			Size:   cfg.UserCache.Size,
+			TTL:    cfg.UserCache.TTL,
+			Logger: logger,
+		}
+		if userCacheInRedis {
+			opts.Redis = rdb
+			opts.Keys = keys
+		}
+		// SQLite runs on a single node, so only Postgres needs cross-replica invalidation
+		if !cfg.Storage.IsSQLite() {
+			cacheNotifier = persistence.NewPostgresUserCacheNotifier(db, cfg.Database.DSN(), logger)
+			opts.Notifier = cacheNotifier
+		}
+		userCache = persistence.NewCachedUserRepository(st.users, opts)
+		st.users = userCache
+		subscribeOnce("user-cache", userCache.HandleEvent)
+	}
+
+	eventsHandler := handler.NewEventsHandler(1000, 15*time.Second, streamBus != nil)
+	subscribeAll("event-stream", eventsHandler.HandleEvent)
+	subscribeOnce("metrics", countEvents(m))
 
-	mux.HandleFunc("GET /health/live", server.HandleLive)
-	mux.HandleFunc("GET /health/ready", server.HandleReady(db, logger))
-	mux.HandleFunc("GET /health", server.HandleHealth(db, logger))
+	if db != nil {
+		if sqlDB, err := db.DB(); err == nil {
+			if err := m.RegisterDB(cfg.Storage.Backend, sqlDB); err != nil {
+				logger.Error("failed to register database metrics", "error", err)
+				os.Exit(1)
+			}
+		}
+	}
+	if rdb != nil {
+		if err := m.RegisterRedis(rdb); err != nil {
+			logger.Error("failed to register redis metrics", "error", err)
+			os.Exit(1)
+		}
+	}
 
-	mux.HandleFunc("POST /auth/signup", authHandler.Signup)
-	mux.HandleFunc("POST /auth/login", authHandler.Login)
-	mux.HandleFunc("POST /auth/logout", authHandler.Logout)
-	mux.Handle("GET /me", server.RequireAuth(authSvc)(http.HandlerFunc(authHandler.Me)))
+	// Limits are counted in memory when Redis is not used or cannot be reached
+	localLimits := ratelimit.NewMemory()
+	var limiter ratelimit.Limiter = localLimits
+	if rateLimitsInRedis {
+		limiter = ratelimit.NewFallback(ratelimit.NewRedis(rdb), localLimits, 5*time.Second, logger)
+	}
 
-	mux.Handle("POST /verification/request", server.RequireAuth(authSvc)(http.HandlerFunc(verifHandler.RequestVerification)))
-	mux.HandleFunc("GET /verification/verify", verifHandler.VerifyEmail)
+	handler, err := newRouter(cfg, logger, st, limiter, eventsHandler, m)
+	if err != nil {
+		logger.Error("failed to build router", "error", err)
+		os.Exit(1)
+	}
 
-	mux.HandleFunc("POST /password/reset/request", verifHandler.RequestPasswordReset)
-	mux.HandleFunc("POST /password/reset/confirm", verifHandler.ResetPassword)
+	if streamBus != nil {
+		if err := streamBus.Start(context.Background()); err != nil {
+			logger.Error("failed to start event bus", "error", err)
+			os.Exit(1)
+		}
+		logger.Info("event bus started", "stream", cfg.EventBus.Stream)
+	}
 
-	mux.Handle("GET /admin/users", server.RequireAdmin(authSvc)(http.HandlerFunc(usersHandler.ListUsers)))
-	mux.Handle("GET /admin/users/{id}", server.RequireAdmin(authSvc)(http.HandlerFunc(usersHandler.GetUser)))
-	mux.Handle("PATCH /admin/users/{id}", server.RequireAdmin(authSvc)(http.HandlerFunc(usersHandler.UpdateUser)))
-	mux.Handle("DELETE /admin/users/{id}", server.RequireAdmin(authSvc)(http.HandlerFunc(usersHandler.DeleteUser)))
+	if cacheNotifier != nil {
+		if err := cacheNotifier.Start(context.Background(), userCache); err != nil {
+			logger.Error("failed to start user cache notifier", "error", err)
+			os.Exit(1)
+		}
+	}
 
-	handler := server.Logging(logger)(server.RateLimit(cfg.RateLimit.RequestsPerSecond, cfg.RateLimit.Burst)(mux))
+	jobs := scheduler.New(logger, time.Minute)
+	jobs.Every("delete-expired-sessions", cfg.Session.CleanupInterval, st.sessions.DeleteExpired)
+	jobs.Every("prune-rate-limits", time.Minute, localLimits.Prune)
+	if st.reencryptUsers != nil {
+		jobs.Every("reencrypt-users", cfg.Encryption.ReencryptInterval, func(ctx context.Context) error {
+			rewritten, err := st.reencryptUsers(ctx)
+			if rewritten > 0 {
+				logger.Info("re-encrypted users", "count", rewritten, "key", keys.Primary())
+			}
+			return err
+		})
+	}
+	if err := jobs.Start(context.Background()); err != nil {
+		logger.Error("failed to start scheduler", "error", err)
+		os.Exit(1)
+	}
 
 	srv := &http.Server{
 		Addr:    ":" + cfg.Server.Port,
 		Handler: handler,
 	}
+	srv.RegisterOnShutdown(eventsHandler.Close)
 
 	go func() {
 		logger.Info("server listening", "address", srv.Addr)
@@ -109,6 +252,23 @@ func main() {
 		}
 	}()
 
+	var adminSrv *http.Server
+	if cfg.Server.MetricsPort != "" {
+		adminMux := http.NewServeMux()
+		adminMux.Handle("GET /metrics", m.Handler())
+		adminSrv = &http.Server{
+			Addr:    ":" + cfg.Server.MetricsPort,
+			Handler: adminMux,
+		}
+
+		go func() {
+			logger.Info("metrics listening", "address", adminSrv.Addr)
+			if err := adminSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Error("metrics server failed", "error", err)
+			}
+		}()
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 	<-quit
@@ -121,10 +281,29 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		logger.Error("server shutdown failed", "error", err)
 	}
+	if adminSrv != nil {
+		if err := adminSrv.Shutdown(ctx); err != nil {
+			logger.Error("metrics server shutdown failed", "error", err)
+		}
+	}
+
+	logger.Info("stopping scheduler")
+	jobs.Close()
+
+	if cacheNotifier != nil {
+		cacheNotifier.Close()
+	}
 
-	if sqlDB, _ := db.DB(); sqlDB != nil {
-		logger.Info("closing database")
-		sqlDB.Close()
+	if streamBus != nil {
+		logger.Info("stopping event bus")
+		streamBus.Close()
+	}
+
+	if db != nil {
+		if sqlDB, _ := db.DB(); sqlDB != nil {
+			logger.Info("closing database")
+			sqlDB.Close()
+		}
 	}
 
 	if rdb != nil {
@@ -132,6 +311,10 @@ func main() {
 		rdb.Close()
 	}
 
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Error("tracing shutdown failed", "error", err)
+	}
+
 	logger.Info("shutdown complete")
 }
 
@@ -142,11 +325,24 @@ func newLogger(cfg *config.Config) *slog.Logger {
 	} else {
 		handler = slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})
 	}
-	return slog.New(handler)
+	return slog.New(requestid.NewLogHandler(handler))
 }
 
 func connectDB(cfg *config.Config, logger *slog.Logger) (*gorm.DB, error) {
-	db, err := gorm.Open(postgres.Open(cfg.Database.DSN()), &gorm.Config{})
+	gormConfig := &gorm.Config{TranslateError: true}
+
+	var (
+		db  *gorm.DB
+		err error
+	)
+	if cfg.Storage.IsSQLite() {
+		if err := os.MkdirAll(filepath.Dir(cfg.Storage.SQLitePath), 0o755); err != nil {
+			return nil, err
+		}
+		db, err = persistence.OpenSQLite(cfg.Storage.SQLitePath, gormConfig)
+	} else {
+		db, err = gorm.Open(postgres.Open(cfg.Database.DSN()), gormConfig)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -160,24 +356,31 @@ func connectDB(cfg *config.Config, logger *slog.Logger) (*gorm.DB, error) {
 		return nil, err
 	}
 
-	logger.Info("database connected")
+	if err := db.Use(gormtracing.NewPlugin(gormtracing.WithoutMetrics(), gormtracing.WithoutQueryVariables())); err != nil {
+		return nil, err
+	}
+
+	logger.Info("database connected", "dialect", db.Dialector.Name())
 	return db, nil
 }
 
-func connectRedis(cfg *config.Config, logger *slog.Logger) (*redis.Client, error) {
-	client := redis.NewClient(&redis.Options{
-		Addr:     cfg.Redis.Addr(),
-		Password: cfg.Redis.Password,
-		DB:       cfg.Redis.DB,
-	})
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+// loadKeyring reads the PII encryption keys from the key file or the environment
+func loadKeyring(cfg *config.Config) (*keyring.Keyring, error) {
+	if cfg.Encryption.KeyFile != "" {
+		return keyring.LoadFile(cfg.Encryption.KeyFile)
+	}
+	return keyring.Parse(cfg.Encryption.Keys, cfg.Encryption.PrimaryKey, cfg.Encryption.BlindIndexKey)
+}
 
-	if err := client.Ping(ctx).Err(); err != nil {
-		return nil, err
+// checkSchema fails if the database has pending migrations
+func checkSchema(db *gorm.DB) error {
+	migrator, err := persistence.NewMigrator(db)
+	if err != nil {
+		return err
 	}
 
-	logger.Info("redis connected")
-	return client, nil
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return migrator.CheckCurrent(ctx)
 }
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"context"
+
+	"github.com/junghwan16/test-server/internal/identity/domain/session"
+	"github.com/junghwan16/test-server/internal/shared/domain"
+	"github.com/junghwan16/test-server/internal/shared/infrastructure/metrics"
+)
+
+// countEvents returns an event handler that drives the business counters in m
+// from domain events, so they count what happened rather than what was asked
+func countEvents(m *metrics.Metrics) domain.EventHandler {
+	return func(_ context.Context, event domain.DomainEvent) error {
+		m.CountEvent(event)
+		switch e := event.(type) {
+		case session.LoginSucceeded:
+			m.LoginSucceeded()
+		case session.LoginFailed:
+			m.LoginFailed(e.Reason)
+		}
+		return nil
+	}
+}
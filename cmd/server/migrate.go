@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+
+	"github.com/junghwan16/test-server/internal/config"
+	"github.com/junghwan16/test-server/internal/identity/infrastructure/persistence"
+	"github.com/junghwan16/test-server/internal/shared/infrastructure/migrate"
+)
+
+const migrateUsage = `usage: server migrate <command>
+
+commands:
+  up                 apply all pending migrations
+  down [-steps N]    roll back the last N migrations (default 1)
+  status             list migrations and whether they are applied
+  create <name>      create a new empty up/down migration pair
+
+Scripts shared by all databases are named <version>_<name>.up.sql; add
+<version>_<name>.postgres.up.sql or .sqlite.up.sql where the SQL differs.`
+
+// runMigrate implements the "migrate" subcommand and returns the exit code
+func runMigrate(cfg *config.Config, logger *slog.Logger, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	if args[0] == "create" {
+		if len(args) != 2 {
+			fmt.Fprintln(os.Stderr, migrateUsage)
+			return 2
+		}
+		up, down, err := migrate.Create(persistence.MigrationsDir, args[1])
+		if err != nil {
+			logger.Error("failed to create migration", "error", err)
+			return 1
+		}
+		fmt.Println(up)
+		fmt.Println(down)
+		return 0
+	}
+
+	if cfg.Storage.IsMemory() {
+		logger.Error("in-memory storage has no schema to migrate")
+		return 1
+	}
+
+	db, err := connectDB(cfg, logger)
+	if err != nil {
+		logger.Error("failed to connect database", "error", err)
+		return 1
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		logger.Error("failed to get database handle", "error", err)
+		return 1
+	}
+	defer sqlDB.Close()
+
+	migrator, err := persistence.NewMigrator(db)
+	if err != nil {
+		logger.Error("failed to load migrations", "error", err)
+		return 1
+	}
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			logger.Info("migration applied", "version", m.Version, "name", m.Name)
+		}
+		if err != nil {
+			logger.Error("migration failed", "error", err)
+			return 1
+		}
+		if len(applied) == 0 {
+			logger.Info("database schema is up to date")
+		}
+
+	case "down":
+		fs := flag.NewFlagSet("down", flag.ContinueOnError)
+		steps := fs.Int("steps", 1, "number of migrations to roll back")
+		if err := fs.Parse(args[1:]); err != nil || *steps < 1 {
+			fmt.Fprintln(os.Stderr, migrateUsage)
+			return 2
+		}
+		reverted, err := migrator.Down(ctx, *steps)
+		for _, m := range reverted {
+			logger.Info("migration rolled back", "version", m.Version, "name", m.Name)
+		}
+		if err != nil {
+			logger.Error("rollback failed", "error", err)
+			return 1
+		}
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			logger.Error("failed to read migration status", "error", err)
+			return 1
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+		for _, s := range statuses {
+			status, appliedAt := "pending", ""
+			if s.Applied {
+				status, appliedAt = "applied", s.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			if s.Missing {
+				status = "applied (missing file)"
+			}
+			fmt.Fprintf(tw, "%04d\t%s\t%s\t%s\n", s.Version, s.Name, status, appliedAt)
+		}
+		tw.Flush()
+
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	return 0
+}
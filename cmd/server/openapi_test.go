@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/junghwan16/test-server/internal/config"
+	"github.com/junghwan16/test-server/internal/identity/handler"
+	"github.com/junghwan16/test-server/internal/server/openapi"
+	"github.com/junghwan16/test-server/internal/shared/domain"
+	"github.com/junghwan16/test-server/internal/shared/infrastructure/metrics"
+	"github.com/junghwan16/test-server/internal/shared/infrastructure/ratelimit"
+	"github.com/junghwan16/test-server/internal/shared/problem"
+)
+
+func TestOpenAPI_RoutesMatchMux(t *testing.T) {
+	// Given: 실제 라우터에 등록된 패턴
+	cfg := &config.Config{Session: config.SessionConfig{TTL: 3600}}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	eventsHandler := handler.NewEventsHandler(0, time.Second, false)
+	defer eventsHandler.Close()
+	m := metrics.New()
+	g, err := newGuards(cfg, ratelimit.NewMemory(), m)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	mux := newMux(cfg, logger, newMemoryStores(domain.NewSimpleEventBus()), g, eventsHandler, m)
+	registered := slices.Sorted(slices.Values(mux.patterns))
+
+	// When: OpenAPI 문서의 오퍼레이션과 비교
+	documented, err := openapi.Routes()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Then: 양쪽이 정확히 같음
+	for _, route := range registered {
+		if !slices.Contains(documented, route) {
+			t.Errorf("route %q is registered but missing from openapi.json", route)
+		}
+	}
+	for _, route := range documented {
+		if !slices.Contains(registered, route) {
+			t.Errorf("route %q is in openapi.json but not registered", route)
+		}
+	}
+}
+
+func TestE2E_OpenAPI(t *testing.T) {
+	srv := newTestServer(t, func(cfg *config.Config) { cfg.Server.ValidateRequests = true })
+	c := newTestClient(t)
+
+	t.Run("문서 제공", func(t *testing.T) {
+		// When: 문서 요청
+		resp := get(t, c, srv.URL+"/openapi.json")
+
+		// Then: OpenAPI 3.1 문서
+		var doc map[string]any
+		json.NewDecoder(resp.Body).Decode(&doc)
+		if doc["openapi"] != "3.1.0" {
+			t.Errorf("expected openapi 3.1.0, got %v", doc["openapi"])
+		}
+		docs := get(t, c, srv.URL+"/docs")
+		if !strings.HasPrefix(docs.Header.Get("Content-Type"), "text/html") {
+			t.Errorf("expected HTML docs page, got %q", docs.Header.Get("Content-Type"))
+		}
+		page, _ := io.ReadAll(docs.Body)
+		if bytes.Contains(page, []byte("<script")) || !bytes.Contains(page, []byte("/v1/auth/login")) {
+			t.Errorf("expected a script-free page listing the operations, got %s", page)
+		}
+	})
+
+	t.Run("스키마에 맞지 않는 본문", func(t *testing.T) {
+		// When: 비밀번호 없이 가입
+		resp := postJSON(t, c, srv.URL+"/v1/auth/signup", map[string]any{"email": "spec@example.com"})
+
+		// Then: 핸들러 전에 필드 오류로 거부됨
+		var p problem.Problem
+		decodeProblem(t, resp, &p)
+		if p.Code != "validation_failed" || len(p.Errors) != 1 || p.Errors[0].Field != "password" || p.Errors[0].Code != "required" {
+			t.Errorf("expected missing password, got %s %+v", p.Code, p.Errors)
+		}
+	})
+
+	t.Run("스키마에 맞는 요청은 통과", func(t *testing.T) {
+		// When: 올바른 가입
+		resp := postJSON(t, c, srv.URL+"/v1/auth/signup", map[string]any{"email": "spec@example.com", "password": "password123"})
+
+		// Then: 핸들러가 처리
+		if resp.StatusCode != http.StatusCreated {
+			t.Errorf("expected 201, got %d", resp.StatusCode)
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/junghwan16/test-server/internal/config"
+	"github.com/junghwan16/test-server/internal/server"
+	"github.com/junghwan16/test-server/internal/shared/infrastructure/metrics"
+	"github.com/junghwan16/test-server/internal/shared/infrastructure/ratelimit"
+)
+
+// globalPolicy limits every request per IP, from RATE_LIMIT_RPS and RATE_LIMIT_BURST
+const globalPolicy = "global"
+
+// routePolicies are the per-route rate limit policies by name, before
+// RATE_LIMIT_POLICIES overrides
+var routePolicies = map[string]server.RatePolicy{
+	"signup":              {Limit: ratelimit.Limit{Rate: 10, Period: time.Hour, Burst: 5}, Key: server.ByIP},
+	"login-ip":            {Limit: ratelimit.Limit{Rate: 30, Period: time.Minute, Burst: 10}, Key: server.ByIP},
+	"login-email":         {Limit: ratelimit.Limit{Rate: 5, Period: 5 * time.Minute, Burst: 5}, Key: server.ByEmail},
+	"verification":        {Limit: ratelimit.Limit{Rate: 3, Period: time.Hour, Burst: 3}, Key: server.ByUser},
+	"verify-email":        {Limit: ratelimit.Limit{Rate: 10, Period: time.Minute, Burst: 10}, Key: server.ByIP},
+	"reset-request-ip":    {Limit: ratelimit.Limit{Rate: 10, Period: time.Hour, Burst: 5}, Key: server.ByIP},
+	"reset-request-email": {Limit: ratelimit.Limit{Rate: 3, Period: time.Hour, Burst: 3}, Key: server.ByEmail},
+	"reset-confirm":       {Limit: ratelimit.Limit{Rate: 5, Period: time.Hour, Burst: 5}, Key: server.ByToken},
+	"admin":               {Limit: ratelimit.Limit{Rate: 10, Period: time.Second, Burst: 50}, Key: server.ByUser},
+}
+
+// rateLimits applies named policies against one limiter
+type rateLimits struct {
+	limiter  ratelimit.Limiter
+	metrics  *metrics.Metrics
+	policies map[string]server.RatePolicy
+}
+
+// newRateLimits resolves the global and per-route policies with cfg's overrides
+func newRateLimits(cfg *config.RateLimitConfig, limiter ratelimit.Limiter, m *metrics.Metrics) (*rateLimits, error) {
+	policies := map[string]server.RatePolicy{
+		globalPolicy: {
+			Limit: ratelimit.Limit{Rate: cfg.RequestsPerSecond, Period: time.Second, Burst: cfg.Burst},
+			Key:   server.ByIP,
+		},
+	}
+	for name, p := range routePolicies {
+		policies[name] = p
+	}
+
+	for name, rule := range cfg.Policies {
+		p, ok := policies[name]
+		if !ok {
+			return nil, fmt.Errorf("RATE_LIMIT_POLICIES: unknown policy %q", name)
+		}
+		p.Limit = ratelimit.Limit{Rate: rule.Rate, Period: rule.Period, Burst: rule.Burst}
+		policies[name] = p
+	}
+	for name, p := range policies {
+		p.Name = name
+		policies[name] = p
+	}
+
+	return &rateLimits{limiter: limiter, metrics: m, policies: policies}, nil
+}
+
+// apply wraps h in the named policies
+func (rl *rateLimits) apply(h http.Handler, names ...string) http.Handler {
+	policies := make([]server.RatePolicy, len(names))
+	for i, name := range names {
+		p, ok := rl.policies[name]
+		if !ok {
+			panic("unknown rate limit policy " + name)
+		}
+		policies[i] = p
+	}
+	return server.RateLimit(rl.limiter, rl.metrics, policies...)(h)
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/redis/go-redis/extra/redisotel/v9"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/junghwan16/test-server/internal/config"
+)
+
+// connectRedis opens a single node, Sentinel or Cluster client depending on
+// REDIS_MODE and checks that it can reach the server
+func connectRedis(cfg *config.Config, logger *slog.Logger) (redis.UniversalClient, error) {
+	client, err := newRedisClient(&cfg.Redis)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	if err := redisotel.InstrumentTracing(client); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	logger.Info("redis connected", "mode", cfg.Redis.Mode)
+	return client, nil
+}
+
+// newRedisClient builds the client for rc without connecting. The client type
+// is chosen from the mode rather than left to redis.NewUniversalClient, which
+// would treat a cluster with a single seed node as a standalone server.
+func newRedisClient(rc *config.RedisConfig) (redis.UniversalClient, error) {
+	if rc.URL != "" {
+		return newRedisClientFromURL(rc)
+	}
+
+	opts := &redis.UniversalOptions{
+		Addrs:            rc.Addrs,
+		MasterName:       rc.MasterName,
+		Username:         rc.Username,
+		Password:         rc.Password,
+		SentinelPassword: rc.SentinelPassword,
+		DB:               rc.DB,
+	}
+	if rc.TLS {
+		opts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	if err := applyRedisCA(opts.TLSConfig, rc); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case rc.IsSentinel():
+		return redis.NewFailoverClient(opts.Failover()), nil
+	case rc.IsCluster():
+		return redis.NewClusterClient(opts.Cluster()), nil
+	default:
+		opts.Addrs = []string{rc.Addr()}
+		return redis.NewClient(opts.Simple()), nil
+	}
+}
+
+// newRedisClientFromURL builds the client from REDIS_URL using the parser for
+// the configured mode, so query parameters such as dial_timeout are honoured.
+// A rediss:// scheme enables TLS.
+func newRedisClientFromURL(rc *config.RedisConfig) (redis.UniversalClient, error) {
+	switch {
+	case rc.IsSentinel():
+		opts, err := redis.ParseFailoverURL(rc.URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+		}
+		if opts.MasterName == "" {
+			return nil, errors.New("invalid REDIS_URL: sentinel mode needs a master_name parameter")
+		}
+		if err := applyRedisCA(opts.TLSConfig, rc); err != nil {
+			return nil, err
+		}
+		return redis.NewFailoverClient(opts), nil
+	case rc.IsCluster():
+		opts, err := redis.ParseClusterURL(rc.URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+		}
+		if err := applyRedisCA(opts.TLSConfig, rc); err != nil {
+			return nil, err
+		}
+		return redis.NewClusterClient(opts), nil
+	default:
+		opts, err := redis.ParseURL(rc.URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+		}
+		if err := applyRedisCA(opts.TLSConfig, rc); err != nil {
+			return nil, err
+		}
+		return redis.NewClient(opts), nil
+	}
+}
+
+// applyRedisCA trusts the CAs in REDIS_TLS_CA_FILE, if set, on tlsConfig
+func applyRedisCA(tlsConfig *tls.Config, rc *config.RedisConfig) error {
+	if rc.TLSCAFile == "" {
+		return nil
+	}
+	if tlsConfig == nil {
+		return errors.New("REDIS_TLS_CA_FILE is set but TLS is not enabled")
+	}
+	pool, err := loadCertPool(rc.TLSCAFile)
+	if err != nil {
+		return err
+	}
+	tlsConfig.RootCAs = pool
+	return nil
+}
+
+// loadCertPool reads a PEM bundle of trusted CA certificates
+func loadCertPool(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read REDIS_TLS_CA_FILE: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("REDIS_TLS_CA_FILE %s contains no certificates", path)
+	}
+	return pool, nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/junghwan16/test-server/internal/config"
+	"github.com/junghwan16/test-server/internal/identity/infrastructure/persistence"
+	"github.com/junghwan16/test-server/internal/shared/domain"
+)
+
+// runReencrypt implements the "reencrypt" subcommand: it rewrites every user
+// whose PII is plaintext or encrypted with a retired key, then exits. The
+// server does the same in the background; this finishes a rotation at once.
+func runReencrypt(cfg *config.Config, logger *slog.Logger) int {
+	if cfg.Storage.IsMemory() {
+		logger.Error("in-memory storage has nothing to re-encrypt")
+		return 1
+	}
+
+	keys, err := loadKeyring(cfg)
+	if err != nil {
+		logger.Error("failed to load encryption keys", "error", err)
+		return 1
+	}
+
+	db, err := connectDB(cfg, logger)
+	if err != nil {
+		logger.Error("failed to connect database", "error", err)
+		return 1
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+
+	if err := checkSchema(db); err != nil {
+		logger.Error("run `server migrate up` first", "error", err)
+		return 1
+	}
+
+	users := persistence.NewUserRepository(db, domain.NewSimpleEventBus(), keys)
+	rewritten, err := users.Reencrypt(context.Background())
+	logger.Info("re-encrypted users", "count", rewritten, "key", keys.Primary())
+	if err != nil {
+		logger.Error("re-encryption incomplete", "error", err)
+		return 1
+	}
+	return 0
+}
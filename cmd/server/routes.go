@@ -0,0 +1,156 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/junghwan16/test-server/internal/config"
+	"github.com/junghwan16/test-server/internal/identity/application"
+	"github.com/junghwan16/test-server/internal/identity/handler"
+	"github.com/junghwan16/test-server/internal/server"
+	"github.com/junghwan16/test-server/internal/server/openapi"
+	"github.com/junghwan16/test-server/internal/shared/clientip"
+	"github.com/junghwan16/test-server/internal/shared/infrastructure/metrics"
+	"github.com/junghwan16/test-server/internal/shared/infrastructure/ratelimit"
+)
+
+// routeMux is a ServeMux that remembers its patterns, so tests can check
+// them against the OpenAPI document, tags requests with the matched route
+// for the access log and bounds them with the request timeout
+type routeMux struct {
+	*http.ServeMux
+	patterns []string
+	timeout  time.Duration
+}
+
+func (m *routeMux) Handle(pattern string, h http.Handler) {
+	m.handle(pattern, server.Timeout(m.timeout)(h))
+}
+
+// HandleStream registers a long-lived streaming route without the request timeout
+func (m *routeMux) HandleStream(pattern string, h http.Handler) {
+	m.handle(pattern, h)
+}
+
+func (m *routeMux) handle(pattern string, h http.Handler) {
+	m.patterns = append(m.patterns, pattern)
+	m.ServeMux.Handle(pattern, server.Routed(h))
+}
+
+func (m *routeMux) HandleFunc(pattern string, h func(http.ResponseWriter, *http.Request)) {
+	m.Handle(pattern, http.HandlerFunc(h))
+}
+
+// newRouter wires services and handlers onto a mux wrapped in the middleware chain
+func newRouter(cfg *config.Config, logger *slog.Logger, st stores, limiter ratelimit.Limiter, eventsHandler *handler.EventsHandler, m *metrics.Metrics) (http.Handler, error) {
+	g, err := newGuards(cfg, limiter, m)
+	if err != nil {
+		return nil, err
+	}
+	clientIPs, err := clientip.NewResolver(cfg.Server.TrustedProxies, cfg.Server.ProxyHeader)
+	if err != nil {
+		return nil, err
+	}
+	mux := newMux(cfg, logger, st, g, eventsHandler, m)
+	cors, err := server.CORS(mux.ServeMux, server.CORSOptions{
+		AllowedOrigins:   cfg.CORS.AllowedOrigins,
+		AllowCredentials: cfg.CORS.AllowCredentials,
+		MaxAge:           cfg.CORS.MaxAge,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var h http.Handler = mux
+
+	if cfg.Server.ValidateRequests {
+		validate, err := openapi.Validate()
+		if err != nil {
+			return nil, err
+		}
+		h = validate(h)
+	}
+
+	// Wrapped innermost first: requests pass through these in reverse order
+	h = server.NegotiateVersion(mux.ServeMux, apiVersion)(h)
+	h = g.limits.apply(h, globalPolicy)
+	h = cors(h)
+	h = server.SecurityHeaders(server.SecurityOptions{
+		HSTSMaxAge:            cfg.Security.HSTSMaxAge,
+		FrameOptions:          cfg.Security.FrameOptions,
+		ContentSecurityPolicy: cfg.Security.ContentSecurityPolicy,
+		ReferrerPolicy:        cfg.Security.ReferrerPolicy,
+	})(h)
+	h = server.Logging(logger, server.AccessLogOptions{
+		SlowThreshold:    cfg.Logger.SlowRequestThreshold,
+		HealthSampleRate: cfg.Logger.HealthSampleRate,
+	})(h)
+	h = server.Instrument(m)(h)
+	h = server.ClientIP(clientIPs)(h)
+	h = server.RequestID()(h)
+	return server.Trace(h), nil
+}
+
+// apiVersion is the latest API version; its routes are mounted under /v{apiVersion}
+const apiVersion = 1
+
+// newMux registers every route; each one must be described in the OpenAPI document.
+// API routes live under their version prefix; operational ones do not.
+// Routes acting on the session check CSRF first; rate limits keyed by user
+// go inside RequireAuth so they see the user.
+func newMux(cfg *config.Config, logger *slog.Logger, st stores, g guards, eventsHandler *handler.EventsHandler, m *metrics.Metrics) *routeMux {
+	userSvc := application.NewUserService(st.users)
+	authSvc := application.NewAuthService(st.users, st.sessions, st.eventBus, cfg.Session.TTL)
+	verifSvc := application.NewVerificationService(
+		st.uow,
+		st.users,
+		st.emailVerifications,
+		24*time.Hour,
+		1*time.Hour,
+	)
+
+	authHandler := handler.NewAuthHandler(userSvc, authSvc, g.csrfTokens, cfg.Session.TTL, handler.CookieOptions{
+		SameSite: cfg.Session.SameSite(),
+		Secure:   cfg.Session.CookieSecure,
+	})
+	usersHandler := handler.NewUsersHandler(userSvc)
+	verifHandler := handler.NewVerificationHandler(verifSvc)
+
+	mux := &routeMux{ServeMux: http.NewServeMux(), timeout: cfg.Server.RequestTimeout}
+
+	mux.HandleFunc("GET /health/live", server.HandleLive)
+	mux.HandleFunc("GET /health/ready", server.HandleReady(st.healthChecks, logger))
+	mux.HandleFunc("GET /health", server.HandleHealth(st.healthChecks, logger))
+
+	mux.HandleFunc("GET /openapi.json", openapi.HandleSpec)
+	mux.HandleFunc("GET /docs", openapi.HandleDocs)
+	if cfg.Server.MetricsPort == "" {
+		mux.Handle("GET /metrics", m.Handler())
+	}
+
+	limits := g.limits
+	requireAuth := func(h http.Handler) http.Handler {
+		return g.csrf(server.RequireAuth(authSvc)(h))
+	}
+	requireAdmin := func(h http.HandlerFunc) http.Handler {
+		return g.csrf(server.RequireAdmin(authSvc)(limits.apply(h, "admin")))
+	}
+
+	mux.Handle("POST /v1/auth/signup", limits.apply(http.HandlerFunc(authHandler.Signup), "signup"))
+	mux.Handle("POST /v1/auth/login", limits.apply(http.HandlerFunc(authHandler.Login), "login-ip", "login-email"))
+	mux.Handle("POST /v1/auth/logout", g.csrf(http.HandlerFunc(authHandler.Logout)))
+	mux.Handle("GET /v1/me", requireAuth(http.HandlerFunc(authHandler.Me)))
+
+	mux.Handle("POST /v1/verification/request", requireAuth(limits.apply(http.HandlerFunc(verifHandler.RequestVerification), "verification")))
+	mux.Handle("GET /v1/verification/verify", limits.apply(http.HandlerFunc(verifHandler.VerifyEmail), "verify-email"))
+
+	mux.Handle("POST /v1/password/reset/request", limits.apply(http.HandlerFunc(verifHandler.RequestPasswordReset), "reset-request-ip", "reset-request-email"))
+	mux.Handle("POST /v1/password/reset/confirm", limits.apply(http.HandlerFunc(verifHandler.ResetPassword), "reset-confirm"))
+
+	mux.Handle("GET /v1/admin/users", requireAdmin(usersHandler.ListUsers))
+	mux.Handle("GET /v1/admin/users/{id}", requireAdmin(usersHandler.GetUser))
+	mux.Handle("PATCH /v1/admin/users/{id}", requireAdmin(usersHandler.UpdateUser))
+	mux.Handle("DELETE /v1/admin/users/{id}", requireAdmin(usersHandler.DeleteUser))
+	mux.HandleStream("GET /v1/admin/events/stream", requireAdmin(eventsHandler.Stream))
+	return mux
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRouteMux_Timeout(t *testing.T) {
+	// Given: 일반 라우트와 스트림 라우트가 등록된 mux
+	mux := &routeMux{ServeMux: http.NewServeMux(), timeout: time.Minute}
+	hasDeadline := func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Deadline(); ok {
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}
+	mux.HandleFunc("GET /plain", hasDeadline)
+	mux.HandleStream("GET /stream", http.HandlerFunc(hasDeadline))
+
+	for _, tc := range []struct {
+		name, path   string
+		wantDeadline bool
+	}{
+		{name: "일반 라우트는 데드라인 적용", path: "/plain", wantDeadline: true},
+		{name: "스트림 라우트는 Accept 헤더와 무관하게 제외", path: "/stream", wantDeadline: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// When: Accept 헤더 없이 요청
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			// Then: 라우트에 따라 데드라인 여부가 결정됨
+			if got := rec.Code == http.StatusNoContent; got != tc.wantDeadline {
+				t.Errorf("expected deadline %v, got %v", tc.wantDeadline, got)
+			}
+		})
+	}
+}
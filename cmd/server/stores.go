@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+
+	"github.com/junghwan16/test-server/internal/identity/application"
+	"github.com/junghwan16/test-server/internal/identity/domain/session"
+	"github.com/junghwan16/test-server/internal/identity/domain/user"
+	"github.com/junghwan16/test-server/internal/identity/domain/verification"
+	"github.com/junghwan16/test-server/internal/identity/infrastructure/memory"
+	"github.com/junghwan16/test-server/internal/identity/infrastructure/persistence"
+	"github.com/junghwan16/test-server/internal/server"
+	"github.com/junghwan16/test-server/internal/shared/domain"
+	"github.com/junghwan16/test-server/internal/shared/infrastructure/keyring"
+)
+
+// stores holds the repositories of the configured storage backend
+type stores struct {
+	users              user.Repository
+	sessions           session.Repository
+	emailVerifications verification.EmailVerificationRepository
+	uow                application.UnitOfWork
+	eventBus           domain.EventBus
+	healthChecks       map[string]server.HealthCheck
+
+	// reencryptUsers rewrites user PII under the primary key; nil if nothing is encrypted
+	reencryptUsers func(ctx context.Context) (int, error)
+}
+
+// newPersistentStores keeps users and tokens in the SQL database, with user PII
+// encrypted under keys, and sessions in Redis, or in the database as well when
+// sessionsInDB is set
+func newPersistentStores(db *gorm.DB, rdb redis.UniversalClient, eventBus domain.EventBus, keys *keyring.Keyring, sessionsInDB bool) stores {
+	checks := map[string]server.HealthCheck{
+		"database": func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		},
+	}
+	if rdb != nil {
+		checks["redis"] = func(ctx context.Context) error { return rdb.Ping(ctx).Err() }
+	}
+
+	var sessions session.Repository
+	if sessionsInDB {
+		sessions = persistence.NewSessionRepository(db)
+	} else {
+		sessions = persistence.NewRedisSessionRepository(rdb)
+	}
+
+	users := persistence.NewUserRepository(db, eventBus, keys)
+
+	return stores{
+		users:              users,
+		sessions:           sessions,
+		emailVerifications: persistence.NewEmailVerificationRepository(db),
+		uow:                persistence.NewUnitOfWork(db, eventBus, keys),
+		eventBus:           eventBus,
+		healthChecks:       checks,
+		reencryptUsers:     users.Reencrypt,
+	}
+}
+
+// newMemoryStores keeps everything in process memory; data is lost on restart
+func newMemoryStores(eventBus domain.EventBus) stores {
+	store := memory.NewStore()
+
+	return stores{
+		users:              memory.NewUserRepository(store, eventBus),
+		sessions:           memory.NewSessionRepository(),
+		emailVerifications: memory.NewEmailVerificationRepository(store),
+		uow:                memory.NewUnitOfWork(store, eventBus),
+		eventBus:           eventBus,
+		healthChecks:       map[string]server.HealthCheck{},
+	}
+}
@@ -1,24 +1,47 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Server    ServerConfig
-	Database  DatabaseConfig
-	Redis     RedisConfig
-	Logger    LoggerConfig
-	SMTP      SMTPConfig
-	Session   SessionConfig
-	RateLimit RateLimitConfig
+	Server     ServerConfig
+	Storage    StorageConfig
+	Database   DatabaseConfig
+	Redis      RedisConfig
+	Logger     LoggerConfig
+	SMTP       SMTPConfig
+	Session    SessionConfig
+	RateLimit  RateLimitConfig
+	EventBus   EventBusConfig
+	UserCache  UserCacheConfig
+	Encryption EncryptionConfig
+	Tracing    TracingConfig
+	CORS       CORSConfig
+	Security   SecurityConfig
 }
 
 type ServerConfig struct {
-	Port string
+	Port             string
+	RequestTimeout   time.Duration
+	ValidateRequests bool     // reject requests that do not match the OpenAPI document
+	MetricsPort      string   // serve /metrics on this port instead of Port; empty keeps it on Port
+	TrustedProxies   []string // CIDRs of proxies whose forwarding headers are believed
+	ProxyHeader      string   // "forwarded", "x-forwarded-for" or "x-real-ip"; the only header believed
+}
+
+type StorageConfig struct {
+	Backend    string // "postgres", "sqlite" or "memory"
+	SQLitePath string
 }
 
 type DatabaseConfig struct {
@@ -30,14 +53,24 @@ type DatabaseConfig struct {
 }
 
 type RedisConfig struct {
-	Host     string
-	Port     string
-	Password string
-	DB       int
+	Mode             string // standalone, sentinel or cluster
+	URL              string // redis:// or rediss:// URL used instead of the settings below
+	Host             string
+	Port             string
+	Addrs            []string // sentinel addresses or cluster seed nodes
+	MasterName       string   // sentinel master set
+	Username         string   // ACL user
+	Password         string
+	SentinelPassword string
+	DB               int
+	TLS              bool
+	TLSCAFile        string // PEM bundle for servers signed by a private CA
 }
 
 type LoggerConfig struct {
-	Environment string
+	Environment          string
+	SlowRequestThreshold time.Duration // requests slower than this log at warn; 0 disables
+	HealthSampleRate     float64       // fraction of successful health checks logged
 }
 
 type SMTPConfig struct {
@@ -49,21 +82,93 @@ type SMTPConfig struct {
 }
 
 type SessionConfig struct {
-	TTL int // seconds
+	TTL             int    // seconds
+	Store           string // "redis" or "database"
+	CleanupInterval time.Duration
+	CSRFSecret      string // base64 key signing CSRF tokens; random per process if empty
+	CookieSameSite  string // "strict", "lax" or "none"; none lets cross-site apps send the cookies
+	CookieSecure    bool   // send the cookies over HTTPS only; required with SameSite=None
 }
 
 type RateLimitConfig struct {
+	Store             string // "redis" or "memory"
 	RequestsPerSecond float64
 	Burst             int
+	Policies          map[string]RateLimitRule // overrides of the named per-route policies
+}
+
+// RateLimitRule allows Rate requests per Period on average, and up to Burst at once
+type RateLimitRule struct {
+	Rate   float64
+	Period time.Duration
+	Burst  int
+}
+
+type EventBusConfig struct {
+	Backend      string // "memory" or "redis"
+	Stream       string
+	MaxLen       int64
+	Consumer     string
+	BlockTimeout time.Duration
+	ClaimMinIdle time.Duration
+}
+
+type CORSConfig struct {
+	AllowedOrigins   []string // exact origins or patterns such as https://*.example.com; empty disables CORS
+	AllowCredentials bool     // let browsers send the session cookie
+	MaxAge           time.Duration
+}
+
+type SecurityConfig struct {
+	HSTSMaxAge            time.Duration // 0 omits Strict-Transport-Security
+	FrameOptions          string
+	ContentSecurityPolicy string
+	ReferrerPolicy        string
+}
+
+type TracingConfig struct {
+	Exporter    string // "none", "otlp", "stdout" or "file"
+	File        string // output of the file exporter
+	SampleRatio float64
+}
+
+type UserCacheConfig struct {
+	Size  int // 0 disables the cache
+	TTL   time.Duration
+	Redis bool // share cached users between replicas through Redis
+}
+
+type EncryptionConfig struct {
+	Keys              string // "id:base64key,..."; the primary key encrypts new values
+	PrimaryKey        string // defaults to the last key in Keys
+	BlindIndexKey     string // base64; must not change once emails are indexed
+	KeyFile           string // JSON keyring file used instead of the settings above
+	ReencryptInterval time.Duration
 }
 
 // Load loads the configuration from environment variables
 func Load() (*Config, error) {
 	_ = godotenv.Load()
 
+	// Production defaults to HTTPS-only; development servers run on plain HTTP
+	production := getEnv("ENV", "development") == "production"
+	var hstsMaxAge time.Duration
+	if production {
+		hstsMaxAge = 365 * 24 * time.Hour
+	}
+
 	cfg := &Config{
 		Server: ServerConfig{
-			Port: getEnv("SERVER_PORT", "8080"),
+			Port:             getEnv("SERVER_PORT", "8080"),
+			RequestTimeout:   getEnvDuration("SERVER_REQUEST_TIMEOUT", 30*time.Second),
+			ValidateRequests: getEnvBool("SERVER_VALIDATE_REQUESTS", false),
+			MetricsPort:      getEnv("SERVER_METRICS_PORT", ""),
+			TrustedProxies:   getEnvList("SERVER_TRUSTED_PROXIES"),
+			ProxyHeader:      strings.ToLower(getEnv("SERVER_TRUSTED_PROXY_HEADER", "x-forwarded-for")),
+		},
+		Storage: StorageConfig{
+			Backend:    getEnv("STORAGE", "postgres"),
+			SQLitePath: getEnv("SQLITE_PATH", "data/identity.db"),
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -73,13 +178,23 @@ func Load() (*Config, error) {
 			Port:     getEnv("DB_PORT", "5432"),
 		},
 		Redis: RedisConfig{
-			Host:     getEnv("REDIS_HOST", "localhost"),
-			Port:     getEnv("REDIS_PORT", "6379"),
-			Password: getEnv("REDIS_PASSWORD", ""),
-			DB:       getEnvInt("REDIS_DB", 0),
+			Mode:             getEnv("REDIS_MODE", "standalone"),
+			URL:              getEnv("REDIS_URL", ""),
+			Host:             getEnv("REDIS_HOST", "localhost"),
+			Port:             getEnv("REDIS_PORT", "6379"),
+			Addrs:            getEnvList("REDIS_ADDRS"),
+			MasterName:       getEnv("REDIS_MASTER_NAME", ""),
+			Username:         getEnv("REDIS_USERNAME", ""),
+			Password:         getEnv("REDIS_PASSWORD", ""),
+			SentinelPassword: getEnv("REDIS_SENTINEL_PASSWORD", ""),
+			DB:               getEnvInt("REDIS_DB", 0),
+			TLS:              getEnvBool("REDIS_TLS", false),
+			TLSCAFile:        getEnv("REDIS_TLS_CA_FILE", ""),
 		},
 		Logger: LoggerConfig{
-			Environment: getEnv("ENV", "development"),
+			Environment:          getEnv("ENV", "development"),
+			SlowRequestThreshold: getEnvDuration("LOG_SLOW_REQUEST_THRESHOLD", time.Second),
+			HealthSampleRate:     getEnvFloat("LOG_HEALTH_SAMPLE_RATE", 0.01),
 		},
 		SMTP: SMTPConfig{
 			Host:     getEnv("SMTP_HOST", "localhost"),
@@ -89,17 +204,142 @@ func Load() (*Config, error) {
 			From:     getEnv("SMTP_FROM", "noreply@example.com"),
 		},
 		Session: SessionConfig{
-			TTL: getEnvInt("SESSION_TTL", 86400), // 24 hours
+			TTL:             getEnvInt("SESSION_TTL", 86400), // 24 hours
+			Store:           getEnv("SESSION_STORE", "redis"),
+			CleanupInterval: getEnvDuration("SESSION_CLEANUP_INTERVAL", 10*time.Minute),
+			CSRFSecret:      getEnv("CSRF_SECRET", ""),
+			CookieSameSite:  strings.ToLower(getEnv("SESSION_COOKIE_SAMESITE", "strict")),
+			CookieSecure:    getEnvBool("SESSION_COOKIE_SECURE", production),
 		},
 		RateLimit: RateLimitConfig{
+			Store:             getEnv("RATE_LIMIT_STORE", "redis"),
 			RequestsPerSecond: getEnvFloat("RATE_LIMIT_RPS", 10),
 			Burst:             getEnvInt("RATE_LIMIT_BURST", 20),
 		},
+		EventBus: EventBusConfig{
+			Backend:      getEnv("EVENT_BUS", "memory"),
+			Stream:       getEnv("EVENT_STREAM", "identity:events"),
+			MaxLen:       int64(getEnvInt("EVENT_STREAM_MAXLEN", 100000)),
+			Consumer:     getEnv("EVENT_CONSUMER", defaultConsumerName()),
+			BlockTimeout: getEnvDuration("EVENT_BLOCK_TIMEOUT", 5*time.Second),
+			ClaimMinIdle: getEnvDuration("EVENT_CLAIM_MIN_IDLE", time.Minute),
+		},
+		CORS: CORSConfig{
+			AllowedOrigins:   getEnvList("CORS_ALLOWED_ORIGINS"),
+			AllowCredentials: getEnvBool("CORS_ALLOW_CREDENTIALS", true),
+			MaxAge:           getEnvDuration("CORS_MAX_AGE", 10*time.Minute),
+		},
+		Security: SecurityConfig{
+			HSTSMaxAge:            getEnvDuration("SECURITY_HSTS_MAX_AGE", hstsMaxAge),
+			FrameOptions:          getEnv("SECURITY_FRAME_OPTIONS", "DENY"),
+			ContentSecurityPolicy: getEnv("SECURITY_CSP", "default-src 'none'; frame-ancestors 'none'"),
+			ReferrerPolicy:        getEnv("SECURITY_REFERRER_POLICY", "no-referrer"),
+		},
+		Tracing: TracingConfig{
+			Exporter:    getEnv("TRACING_EXPORTER", "none"),
+			File:        getEnv("TRACING_FILE", "traces.jsonl"),
+			SampleRatio: getEnvFloat("TRACING_SAMPLE_RATIO", 1),
+		},
+		UserCache: UserCacheConfig{
+			Size:  getEnvInt("USER_CACHE_SIZE", 10000),
+			TTL:   getEnvDuration("USER_CACHE_TTL", time.Minute),
+			Redis: getEnvBool("USER_CACHE_REDIS", false),
+		},
+		Encryption: EncryptionConfig{
+			Keys:              getEnv("PII_ENCRYPTION_KEYS", ""),
+			PrimaryKey:        getEnv("PII_ENCRYPTION_PRIMARY_KEY", ""),
+			BlindIndexKey:     getEnv("PII_BLIND_INDEX_KEY", ""),
+			KeyFile:           getEnv("PII_KEY_FILE", ""),
+			ReencryptInterval: getEnvDuration("PII_REENCRYPT_INTERVAL", time.Hour),
+		},
+	}
+
+	policies, err := parseRateLimitRules(os.Getenv("RATE_LIMIT_POLICIES"))
+	if err != nil {
+		return nil, err
+	}
+	cfg.RateLimit.Policies = policies
+
+	if err := cfg.validate(); err != nil {
+		return nil, err
 	}
 
 	return cfg, nil
 }
 
+func (c *Config) validate() error {
+	switch c.Server.ProxyHeader {
+	case "forwarded", "x-forwarded-for", "x-real-ip":
+	default:
+		return fmt.Errorf("invalid SERVER_TRUSTED_PROXY_HEADER %q: must be forwarded, x-forwarded-for or x-real-ip", c.Server.ProxyHeader)
+	}
+
+	switch c.Storage.Backend {
+	case "postgres", "sqlite", "memory":
+	default:
+		return fmt.Errorf("invalid STORAGE %q: must be postgres, sqlite or memory", c.Storage.Backend)
+	}
+
+	switch c.Session.Store {
+	case "redis", "database":
+	default:
+		return fmt.Errorf("invalid SESSION_STORE %q: must be redis or database", c.Session.Store)
+	}
+
+	switch c.EventBus.Backend {
+	case "memory", "redis":
+	default:
+		return fmt.Errorf("invalid EVENT_BUS %q: must be memory or redis", c.EventBus.Backend)
+	}
+
+	switch c.RateLimit.Store {
+	case "redis", "memory":
+	default:
+		return fmt.Errorf("invalid RATE_LIMIT_STORE %q: must be redis or memory", c.RateLimit.Store)
+	}
+	if c.RateLimit.RequestsPerSecond <= 0 || c.RateLimit.Burst < 1 {
+		return errors.New("RATE_LIMIT_RPS must be positive and RATE_LIMIT_BURST at least 1")
+	}
+
+	if err := c.Redis.validate(); err != nil {
+		return err
+	}
+
+	switch c.Tracing.Exporter {
+	case "none", "otlp", "stdout", "file":
+	default:
+		return fmt.Errorf("invalid TRACING_EXPORTER %q: must be none, otlp, stdout or file", c.Tracing.Exporter)
+	}
+	if r := c.Tracing.SampleRatio; r < 0 || r > 1 {
+		return fmt.Errorf("invalid TRACING_SAMPLE_RATIO %v: must be between 0 and 1", r)
+	}
+
+	if r := c.Logger.HealthSampleRate; r < 0 || r > 1 {
+		return fmt.Errorf("invalid LOG_HEALTH_SAMPLE_RATE %v: must be between 0 and 1", r)
+	}
+
+	if !c.Storage.IsMemory() && !c.Encryption.Configured() {
+		return fmt.Errorf("PII_ENCRYPTION_KEYS and PII_BLIND_INDEX_KEY, or PII_KEY_FILE, are required with STORAGE=%s", c.Storage.Backend)
+	}
+
+	switch c.Session.CookieSameSite {
+	case "strict", "lax", "none":
+	default:
+		return fmt.Errorf("invalid SESSION_COOKIE_SAMESITE %q: must be strict, lax or none", c.Session.CookieSameSite)
+	}
+	// Browsers drop SameSite=None cookies that are not Secure
+	if c.Session.CookieSameSite == "none" && !c.Session.CookieSecure {
+		return errors.New("SESSION_COOKIE_SAMESITE=none requires SESSION_COOKIE_SECURE=true")
+	}
+
+	// Sessions outlive the process and are shared, so their tokens must verify everywhere
+	if !c.Storage.IsMemory() && c.Session.CSRFSecret == "" {
+		return fmt.Errorf("CSRF_SECRET is required with STORAGE=%s", c.Storage.Backend)
+	}
+
+	return nil
+}
+
 // getEnv gets an environment variable with a default value
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -132,12 +372,128 @@ func getEnvFloat(key string, defaultValue float64) float64 {
 	return defaultValue
 }
 
+// getEnvBool gets an environment variable as bool with a default value
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return defaultValue
+}
+
+// getEnvList gets a comma-separated environment variable as a list
+func getEnvList(key string) []string {
+	var list []string
+	for item := range strings.SplitSeq(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// getEnvDuration gets an environment variable as time.Duration with a default value
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return defaultValue
+}
+
+// parseRateLimitRules parses "name=rate/period[:burst],..." such as
+// "login-email=5/5m:5"; burst defaults to the rate
+func parseRateLimitRules(value string) (map[string]RateLimitRule, error) {
+	rules := make(map[string]RateLimitRule)
+	for item := range strings.SplitSeq(value, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		invalid := fmt.Errorf("invalid RATE_LIMIT_POLICIES entry %q: want name=rate/period[:burst]", item)
+
+		name, spec, ok := strings.Cut(item, "=")
+		if !ok || name == "" {
+			return nil, invalid
+		}
+		spec, burstText, hasBurst := strings.Cut(spec, ":")
+		rateText, periodText, ok := strings.Cut(spec, "/")
+		if !ok {
+			return nil, invalid
+		}
+
+		rate, err := strconv.ParseFloat(rateText, 64)
+		if err != nil || rate <= 0 {
+			return nil, invalid
+		}
+		period, err := time.ParseDuration(periodText)
+		if err != nil || period <= 0 {
+			return nil, invalid
+		}
+		burst := int(math.Ceil(rate))
+		if hasBurst {
+			if burst, err = strconv.Atoi(burstText); err != nil || burst < 1 {
+				return nil, invalid
+			}
+		}
+		rules[name] = RateLimitRule{Rate: rate, Period: period, Burst: burst}
+	}
+	return rules, nil
+}
+
+// defaultConsumerName identifies this process within a consumer group
+func defaultConsumerName() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "server"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
 // DSN returns the database connection string
 func (d *DatabaseConfig) DSN() string {
 	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
 		d.Host, d.User, d.Password, d.Name, d.Port)
 }
 
+// SameSite returns the SameSite mode of the session and CSRF cookies
+func (s *SessionConfig) SameSite() http.SameSite {
+	switch s.CookieSameSite {
+	case "lax":
+		return http.SameSiteLaxMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteStrictMode
+	}
+}
+
+// IsMemory returns true if all data is kept in process memory
+func (s *StorageConfig) IsMemory() bool {
+	return s.Backend == "memory"
+}
+
+// IsSQLite returns true if data is kept in a local SQLite file
+func (s *StorageConfig) IsSQLite() bool {
+	return s.Backend == "sqlite"
+}
+
+// InDatabase returns true if sessions are kept in the SQL database instead of Redis
+func (s *SessionConfig) InDatabase() bool {
+	return s.Store == "database"
+}
+
+// Enabled returns true if user lookups are cached
+func (u *UserCacheConfig) Enabled() bool {
+	return u.Size > 0
+}
+
+// Configured returns true if encryption keys are provided
+func (e *EncryptionConfig) Configured() bool {
+	return e.KeyFile != "" || (e.Keys != "" && e.BlindIndexKey != "")
+}
+
 // IsProduction returns true if the environment is production
 func (l *LoggerConfig) IsProduction() bool {
 	return l.Environment == "production"
@@ -147,3 +503,46 @@ func (l *LoggerConfig) IsProduction() bool {
 func (r *RedisConfig) Addr() string {
 	return r.Host + ":" + r.Port
 }
+
+// IsCluster returns true if Redis runs in cluster mode
+func (r *RedisConfig) IsCluster() bool {
+	return r.Mode == "cluster"
+}
+
+// IsSentinel returns true if the Redis master is discovered through Sentinel
+func (r *RedisConfig) IsSentinel() bool {
+	return r.Mode == "sentinel"
+}
+
+func (r *RedisConfig) validate() error {
+	switch r.Mode {
+	case "standalone", "sentinel", "cluster":
+	default:
+		return fmt.Errorf("invalid REDIS_MODE %q: must be standalone, sentinel or cluster", r.Mode)
+	}
+
+	// A URL carries its own addresses (and master_name for Sentinel)
+	if r.URL != "" {
+		return nil
+	}
+	if r.IsSentinel() && (r.MasterName == "" || len(r.Addrs) == 0) {
+		return errors.New("REDIS_MASTER_NAME and REDIS_ADDRS are required with REDIS_MODE=sentinel")
+	}
+	if r.IsCluster() && len(r.Addrs) == 0 {
+		return errors.New("REDIS_ADDRS is required with REDIS_MODE=cluster")
+	}
+	if r.IsCluster() && r.DB != 0 {
+		return errors.New("REDIS_DB must be 0 with REDIS_MODE=cluster")
+	}
+	return nil
+}
+
+// InRedis returns true if rate limits are shared between replicas through Redis
+func (r *RateLimitConfig) InRedis() bool {
+	return r.Store == "redis"
+}
+
+// IsRedis returns true if events are published through Redis Streams
+func (e *EventBusConfig) IsRedis() bool {
+	return e.Backend == "redis"
+}
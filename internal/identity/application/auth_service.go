@@ -1,18 +1,25 @@
 package application
 
 import (
+	"context"
 	"errors"
 
 	"github.com/junghwan16/test-server/internal/identity/domain/session"
 	"github.com/junghwan16/test-server/internal/identity/domain/user"
+	"github.com/junghwan16/test-server/internal/shared/clientip"
+	"github.com/junghwan16/test-server/internal/shared/domain"
 )
 
-var ErrInvalidCredentials = errors.New("invalid credentials")
+var (
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrInvalidSession     = errors.New("invalid or expired session")
+)
 
 // AuthService handles authentication logic
 type AuthService struct {
 	userRepo    user.Repository
 	sessionRepo session.Repository
+	eventBus    domain.EventBus
 	sessionTTL  int
 }
 
@@ -20,62 +27,87 @@ type AuthService struct {
 func NewAuthService(
 	userRepo user.Repository,
 	sessionRepo session.Repository,
+	eventBus domain.EventBus,
 	sessionTTL int,
 ) *AuthService {
 	return &AuthService{
 		userRepo:    userRepo,
 		sessionRepo: sessionRepo,
+		eventBus:    eventBus,
 		sessionTTL:  sessionTTL,
 	}
 }
 
-// Login authenticates a user and creates a session
-func (s *AuthService) Login(email, password string) (*session.Session, *user.User, error) {
+// Login authenticates a user and creates a session. Every attempt publishes
+// LoginSucceeded or LoginFailed.
+func (s *AuthService) Login(ctx context.Context, email, password string) (_ *session.Session, _ *user.User, err error) {
+	ctx, span := startSpan(ctx, "AuthService.Login")
+	defer func() { endSpan(span, err) }()
+
 	emailVO, err := user.NewEmail(email)
 	if err != nil {
+		s.publish(ctx, session.NewLoginFailed(session.FailureInvalidEmail))
 		return nil, nil, ErrInvalidCredentials
 	}
 
-	u, err := s.userRepo.FindByEmail(emailVO)
+	u, err := s.userRepo.FindByEmail(ctx, emailVO)
 	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			s.publish(ctx, session.NewLoginFailed(session.FailureUnknownUser))
+		}
 		return nil, nil, ErrInvalidCredentials
 	}
 
-	if !u.Authenticate(password) {
+	// bcrypt dominates a login; its own span separates it from storage latency
+	_, hashSpan := startSpan(ctx, "user.Authenticate")
+	authenticated := u.Authenticate(password)
+	hashSpan.End()
+	if !authenticated {
+		s.publish(ctx, session.NewLoginFailed(session.FailureWrongPassword))
 		return nil, nil, ErrInvalidCredentials
 	}
 
-	// Create session
+	// Create session, remembering where it was signed in from
 	sess := session.NewSession(
 		session.GenerateSessionID(),
 		u.ID(),
+		clientip.FromContext(ctx),
 		s.sessionTTL,
 	)
 
-	if err := s.sessionRepo.Save(sess); err != nil {
+	if err := s.sessionRepo.Save(ctx, sess); err != nil {
 		return nil, nil, err
 	}
 
+	s.publish(ctx, session.NewLoginSucceeded(u.ID()))
 	return sess, u, nil
 }
 
+// publish is best-effort, like event publishing from repositories
+func (s *AuthService) publish(ctx context.Context, event domain.DomainEvent) {
+	_ = s.eventBus.Publish(ctx, event)
+}
+
 // ValidateSession validates a session
-func (s *AuthService) ValidateSession(sessionID string) (*session.Session, *user.User, error) {
+func (s *AuthService) ValidateSession(ctx context.Context, sessionID string) (_ *session.Session, _ *user.User, err error) {
+	ctx, span := startSpan(ctx, "AuthService.ValidateSession")
+	defer func() { endSpan(span, err) }()
+
 	sid, err := session.NewSessionID(sessionID)
 	if err != nil {
-		return nil, nil, errors.New("invalid session")
+		return nil, nil, ErrInvalidSession
 	}
 
-	sess, err := s.sessionRepo.FindByID(sid)
+	sess, err := s.sessionRepo.FindByID(ctx, sid)
 	if err != nil {
-		return nil, nil, errors.New("invalid session")
+		return nil, nil, ErrInvalidSession
 	}
 
 	if sess.IsExpired() {
-		return nil, nil, errors.New("session expired")
+		return nil, nil, ErrInvalidSession
 	}
 
-	u, err := s.userRepo.FindByID(sess.UserID())
+	u, err := s.userRepo.FindByID(ctx, sess.UserID())
 	if err != nil {
 		return nil, nil, err
 	}
@@ -84,7 +116,10 @@ func (s *AuthService) ValidateSession(sessionID string) (*session.Session, *user
 }
 
 // Logout destroys a session
-func (s *AuthService) Logout(sessionID string) error {
+func (s *AuthService) Logout(ctx context.Context, sessionID string) (err error) {
+	ctx, span := startSpan(ctx, "AuthService.Logout")
+	defer func() { endSpan(span, err) }()
+
 	sid, _ := session.NewSessionID(sessionID)
-	return s.sessionRepo.Delete(sid)
+	return s.sessionRepo.Delete(ctx, sid)
 }
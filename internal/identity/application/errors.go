@@ -0,0 +1,56 @@
+package application
+
+import (
+	"errors"
+	"strings"
+)
+
+// FieldError ties a validation failure to the request field that caused it
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+// ValidationError reports every invalid field of a request at once, so
+// clients can show all problems instead of fixing them one by one.
+// errors.Is matches the underlying domain errors.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// validate returns a ValidationError for the fields whose Err is set, or nil
+func validate(fields ...FieldError) error {
+	var invalid []FieldError
+	for _, f := range fields {
+		if f.Err != nil {
+			invalid = append(invalid, f)
+		}
+	}
+	if len(invalid) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: invalid}
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = f.Field + ": " + f.Err.Error()
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the field errors for errors.Is and errors.As
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Fields))
+	for i, f := range e.Fields {
+		errs[i] = f.Err
+	}
+	return errs
+}
+
+// IsValidationError returns true if err reports invalid request fields
+func IsValidationError(err error) bool {
+	var ve *ValidationError
+	return errors.As(err, &ve)
+}
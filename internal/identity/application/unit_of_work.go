@@ -0,0 +1,22 @@
+package application
+
+import (
+	"context"
+
+	"github.com/junghwan16/test-server/internal/identity/domain/user"
+	"github.com/junghwan16/test-server/internal/identity/domain/verification"
+)
+
+// Repositories gives a use case access to repositories bound to one unit of work
+type Repositories struct {
+	Users              user.Repository
+	EmailVerifications verification.EmailVerificationRepository
+	PasswordResets     verification.PasswordResetRepository
+}
+
+// UnitOfWork runs a use case atomically. All writes made through the given
+// repositories commit together or not at all, and domain events raised by
+// the use case are published only after a successful commit.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(repos Repositories) error) error
+}
@@ -1,14 +1,16 @@
 package application
 
 import (
+	"context"
 	"errors"
 
 	"github.com/junghwan16/test-server/internal/identity/domain/user"
 )
 
 var (
-	ErrUserNotFound     = errors.New("user not found")
-	ErrCannotDeleteSelf = errors.New("cannot delete yourself")
+	ErrUserNotFound           = errors.New("user not found")
+	ErrCannotDeleteSelf       = errors.New("cannot delete yourself")
+	ErrEmailAlreadyRegistered = errors.New("email already registered")
 )
 
 // UserService handles user-related application logic
@@ -21,29 +23,38 @@ func NewUserService(userRepo user.Repository) *UserService {
 	return &UserService{userRepo: userRepo}
 }
 
-func (s *UserService) RegisterUser(email, password string) (*user.User, error) {
-	emailVO, err := user.NewEmail(email)
-	if err != nil {
-		return nil, err
-	}
+func (s *UserService) RegisterUser(ctx context.Context, email, password string) (_ *user.User, err error) {
+	ctx, span := startSpan(ctx, "UserService.RegisterUser")
+	defer func() { endSpan(span, err) }()
 
-	passwordVO, err := user.NewPassword(password)
-	if err != nil {
+	emailVO, emailErr := user.NewEmail(email)
+	passwordVO, passwordErr := user.NewPassword(password)
+	if err := validate(
+		FieldError{Field: "email", Err: emailErr},
+		FieldError{Field: "password", Err: passwordErr},
+	); err != nil {
 		return nil, err
 	}
 
-	existing, _ := s.userRepo.FindByEmail(emailVO)
-	if existing != nil {
-		return nil, errors.New("email already registered")
+	_, err = s.userRepo.FindByEmail(ctx, emailVO)
+	if err == nil {
+		return nil, ErrEmailAlreadyRegistered
+	}
+	if !errors.Is(err, user.ErrUserNotFound) {
+		return nil, err
 	}
 
-	id := s.userRepo.NextID()
+	id := s.userRepo.NextID(ctx)
 	u, err := user.NewUser(id, emailVO, passwordVO)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := s.userRepo.Save(u); err != nil {
+	// A concurrent signup can win the race after the lookup above
+	if err := s.userRepo.Save(ctx, u); err != nil {
+		if errors.Is(err, user.ErrDuplicateEmail) {
+			return nil, ErrEmailAlreadyRegistered
+		}
 		return nil, err
 	}
 
@@ -51,13 +62,16 @@ func (s *UserService) RegisterUser(email, password string) (*user.User, error) {
 }
 
 // GetUser retrieves a user by ID
-func (s *UserService) GetUser(id uint) (*user.User, error) {
+func (s *UserService) GetUser(ctx context.Context, id string) (_ *user.User, err error) {
+	ctx, span := startSpan(ctx, "UserService.GetUser")
+	defer func() { endSpan(span, err) }()
+
 	userID, err := user.NewUserID(id)
 	if err != nil {
 		return nil, err
 	}
 
-	u, err := s.userRepo.FindByID(userID)
+	u, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
 		return nil, ErrUserNotFound
 	}
@@ -66,13 +80,16 @@ func (s *UserService) GetUser(id uint) (*user.User, error) {
 }
 
 // GetUserByEmail retrieves a user by email
-func (s *UserService) GetUserByEmail(email string) (*user.User, error) {
+func (s *UserService) GetUserByEmail(ctx context.Context, email string) (_ *user.User, err error) {
+	ctx, span := startSpan(ctx, "UserService.GetUserByEmail")
+	defer func() { endSpan(span, err) }()
+
 	emailVO, err := user.NewEmail(email)
 	if err != nil {
 		return nil, err
 	}
 
-	u, err := s.userRepo.FindByEmail(emailVO)
+	u, err := s.userRepo.FindByEmail(ctx, emailVO)
 	if err != nil {
 		return nil, ErrUserNotFound
 	}
@@ -80,8 +97,20 @@ func (s *UserService) GetUserByEmail(email string) (*user.User, error) {
 	return u, nil
 }
 
-// ListUsers retrieves all users with pagination
-func (s *UserService) ListUsers(limit, offset int) ([]*user.User, int64, error) {
+// UserPage is one page of users along with the bounds actually applied
+type UserPage struct {
+	Users  []*user.User
+	Total  int64
+	Limit  int
+	Offset int
+}
+
+// ListUsers retrieves all users with pagination. Out of range limits fall
+// back to 20 and negative offsets to 0.
+func (s *UserService) ListUsers(ctx context.Context, limit, offset int) (_ UserPage, err error) {
+	ctx, span := startSpan(ctx, "UserService.ListUsers")
+	defer func() { endSpan(span, err) }()
+
 	if limit <= 0 || limit > 100 {
 		limit = 20
 	}
@@ -89,17 +118,24 @@ func (s *UserService) ListUsers(limit, offset int) ([]*user.User, int64, error)
 		offset = 0
 	}
 
-	return s.userRepo.FindAll(limit, offset)
+	users, total, err := s.userRepo.FindAll(ctx, limit, offset)
+	if err != nil {
+		return UserPage{}, err
+	}
+	return UserPage{Users: users, Total: total, Limit: limit, Offset: offset}, nil
 }
 
 // ChangePassword changes a user's password
-func (s *UserService) ChangePassword(id uint, newPassword string) error {
+func (s *UserService) ChangePassword(ctx context.Context, id string, newPassword string) (err error) {
+	ctx, span := startSpan(ctx, "UserService.ChangePassword")
+	defer func() { endSpan(span, err) }()
+
 	userID, err := user.NewUserID(id)
 	if err != nil {
 		return err
 	}
 
-	u, err := s.userRepo.FindByID(userID)
+	u, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
 		return ErrUserNotFound
 	}
@@ -113,17 +149,20 @@ func (s *UserService) ChangePassword(id uint, newPassword string) error {
 		return err
 	}
 
-	return s.userRepo.Save(u)
+	return s.userRepo.Save(ctx, u)
 }
 
 // VerifyEmail marks a user's email as verified
-func (s *UserService) VerifyEmail(id uint) error {
+func (s *UserService) VerifyEmail(ctx context.Context, id string) (err error) {
+	ctx, span := startSpan(ctx, "UserService.VerifyEmail")
+	defer func() { endSpan(span, err) }()
+
 	userID, err := user.NewUserID(id)
 	if err != nil {
 		return err
 	}
 
-	u, err := s.userRepo.FindByID(userID)
+	u, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
 		return ErrUserNotFound
 	}
@@ -132,17 +171,20 @@ func (s *UserService) VerifyEmail(id uint) error {
 		return err
 	}
 
-	return s.userRepo.Save(u)
+	return s.userRepo.Save(ctx, u)
 }
 
 // ChangeRole changes a user's role (admin operation)
-func (s *UserService) ChangeRole(id uint, roleName string) error {
+func (s *UserService) ChangeRole(ctx context.Context, id string, roleName string) (err error) {
+	ctx, span := startSpan(ctx, "UserService.ChangeRole")
+	defer func() { endSpan(span, err) }()
+
 	userID, err := user.NewUserID(id)
 	if err != nil {
 		return err
 	}
 
-	u, err := s.userRepo.FindByID(userID)
+	u, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
 		return ErrUserNotFound
 	}
@@ -156,17 +198,20 @@ func (s *UserService) ChangeRole(id uint, roleName string) error {
 		return err
 	}
 
-	return s.userRepo.Save(u)
+	return s.userRepo.Save(ctx, u)
 }
 
 // SetActive sets a user's active status (admin operation)
-func (s *UserService) SetActive(id uint, active bool) error {
+func (s *UserService) SetActive(ctx context.Context, id string, active bool) (err error) {
+	ctx, span := startSpan(ctx, "UserService.SetActive")
+	defer func() { endSpan(span, err) }()
+
 	userID, err := user.NewUserID(id)
 	if err != nil {
 		return err
 	}
 
-	u, err := s.userRepo.FindByID(userID)
+	u, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
 		return ErrUserNotFound
 	}
@@ -181,10 +226,75 @@ func (s *UserService) SetActive(id uint, active bool) error {
 		}
 	}
 
-	return s.userRepo.Save(u)
+	return s.userRepo.Save(ctx, u)
 }
 
-func (s *UserService) DeleteUser(id uint, currentUserID uint) error {
+// UserChanges describes an admin update; nil fields are left unchanged
+type UserChanges struct {
+	Role          *string
+	Active        *bool
+	EmailVerified *bool
+}
+
+// UpdateUser applies admin changes with a single load and save (admin operation).
+// A non-zero expectedVersion must match the stored version, otherwise
+// user.ErrConcurrentModification is returned.
+func (s *UserService) UpdateUser(ctx context.Context, id string, changes UserChanges, expectedVersion int64) (_ *user.User, err error) {
+	ctx, span := startSpan(ctx, "UserService.UpdateUser")
+	defer func() { endSpan(span, err) }()
+
+	userID, err := user.NewUserID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	if expectedVersion != 0 && u.Version() != expectedVersion {
+		return nil, user.ErrConcurrentModification
+	}
+
+	if changes.Role != nil {
+		newRole, err := user.NewRole(*changes.Role)
+		if err != nil {
+			return nil, validate(FieldError{Field: "role", Err: err})
+		}
+		if err := u.ChangeRole(newRole); err != nil {
+			return nil, err
+		}
+	}
+
+	if changes.Active != nil {
+		if *changes.Active {
+			err = u.Activate()
+		} else {
+			err = u.Deactivate()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if changes.EmailVerified != nil && *changes.EmailVerified {
+		if err := u.VerifyEmail(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.userRepo.Save(ctx, u); err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
+func (s *UserService) DeleteUser(ctx context.Context, id string, currentUserID string) (err error) {
+	ctx, span := startSpan(ctx, "UserService.DeleteUser")
+	defer func() { endSpan(span, err) }()
+
 	if id == currentUserID {
 		return ErrCannotDeleteSelf
 	}
@@ -194,5 +304,5 @@ func (s *UserService) DeleteUser(id uint, currentUserID uint) error {
 		return err
 	}
 
-	return s.userRepo.Delete(userID)
+	return s.userRepo.Delete(ctx, userID)
 }
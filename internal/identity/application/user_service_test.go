@@ -1,77 +1,131 @@
 package application
 
 import (
+	"context"
 	"errors"
+	"slices"
+	"sync"
 	"testing"
 
 	"github.com/junghwan16/test-server/internal/identity/domain/user"
+	"github.com/junghwan16/test-server/internal/identity/domain/user/usertest"
 )
 
+// mockUserRepository keeps copies of saved users and enforces the same
+// uniqueness and versioning rules as the real repositories
 type mockUserRepository struct {
-	users   map[uint]*user.User
-	nextID  uint
-	findErr error
-	saveErr error
+	mu    sync.Mutex
+	users map[string]*user.User
 }
 
 func newMockUserRepository() *mockUserRepository {
 	return &mockUserRepository{
-		users:  make(map[uint]*user.User),
-		nextID: 1,
+		users: make(map[string]*user.User),
 	}
 }
 
-func (m *mockUserRepository) Save(u *user.User) error {
-	if m.saveErr != nil {
-		return m.saveErr
+func (m *mockUserRepository) Save(ctx context.Context, u *user.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if u.ID().IsZero() {
+		return user.ErrInvalidUserID
+	}
+
+	if u.Version() == 0 {
+		if _, exists := m.users[u.ID().Value()]; exists {
+			return errors.New("user already exists")
+		}
+	} else if stored, ok := m.users[u.ID().Value()]; !ok || stored.Version() != u.Version() {
+		return user.ErrConcurrentModification
 	}
-	m.users[u.ID().Value()] = u
+
+	for id, other := range m.users {
+		if id != u.ID().Value() && other.Email() == u.Email() {
+			return user.ErrDuplicateEmail
+		}
+	}
+
+	u.SetVersion(u.Version() + 1)
+	m.users[u.ID().Value()] = cloneUser(u)
+	u.ClearEvents()
 	return nil
 }
 
-func (m *mockUserRepository) FindByID(id user.UserID) (*user.User, error) {
-	if m.findErr != nil {
-		return nil, m.findErr
-	}
+func (m *mockUserRepository) FindByID(ctx context.Context, id user.UserID) (*user.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	u, ok := m.users[id.Value()]
 	if !ok {
-		return nil, errors.New("user not found")
+		return nil, user.ErrUserNotFound
 	}
-	return u, nil
+	return cloneUser(u), nil
 }
 
-func (m *mockUserRepository) FindByEmail(email user.Email) (*user.User, error) {
-	if m.findErr != nil {
-		return nil, m.findErr
-	}
+func (m *mockUserRepository) FindByEmail(ctx context.Context, email user.Email) (*user.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	for _, u := range m.users {
 		if u.Email() == email {
-			return u, nil
+			return cloneUser(u), nil
 		}
 	}
-	return nil, errors.New("user not found")
+	return nil, user.ErrUserNotFound
 }
 
-func (m *mockUserRepository) FindAll(limit, offset int) ([]*user.User, int64, error) {
-	if m.findErr != nil {
-		return nil, 0, m.findErr
-	}
+func (m *mockUserRepository) FindAll(ctx context.Context, limit, offset int) ([]*user.User, int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	var result []*user.User
 	for _, u := range m.users {
-		result = append(result, u)
+		result = append(result, cloneUser(u))
 	}
-	return result, int64(len(result)), nil
+	slices.SortFunc(result, func(a, b *user.User) int {
+		return b.CreatedAt().Compare(a.CreatedAt())
+	})
+
+	total := int64(len(result))
+	start := min(offset, len(result))
+	end := min(start+limit, len(result))
+	return result[start:end], total, nil
 }
 
-func (m *mockUserRepository) Delete(id user.UserID) error {
+func (m *mockUserRepository) Delete(ctx context.Context, id user.UserID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	delete(m.users, id.Value())
 	return nil
 }
 
-func (m *mockUserRepository) NextID() user.UserID {
-	id, _ := user.NewUserID(m.nextID)
-	m.nextID++
-	return id
+func (m *mockUserRepository) NextID(ctx context.Context) user.UserID {
+	return user.GenerateUserID()
+}
+
+func cloneUser(u *user.User) *user.User {
+	return user.ReconstructUser(
+		u.ID(), u.Email(), u.Password(), u.Role(),
+		u.EmailVerified(), u.Active(),
+		u.CreatedAt(), u.UpdatedAt(), u.Version(),
+	)
+}
+
+func TestMockUserRepository(t *testing.T) {
+	usertest.TestRepository(t, func(t *testing.T) user.Repository {
+		return newMockUserRepository()
+	})
+}
+
+// lateUserRepository misses users on lookup, as if they were saved concurrently
+type lateUserRepository struct {
+	*mockUserRepository
+}
+
+func (lateUserRepository) FindByEmail(ctx context.Context, email user.Email) (*user.User, error) {
+	return nil, user.ErrUserNotFound
 }
 
 func TestUserService_RegisterUser(t *testing.T) {
@@ -84,7 +138,7 @@ func TestUserService_RegisterUser(t *testing.T) {
 		password := "password123"
 
 		// When: 사용자 등록
-		u, err := svc.RegisterUser(email, password)
+		u, err := svc.RegisterUser(t.Context(), email, password)
 
 		// Then: 사용자가 생성됨
 		if err != nil {
@@ -107,14 +161,46 @@ func TestUserService_RegisterUser(t *testing.T) {
 		svc := NewUserService(repo)
 
 		email := "test@example.com"
-		svc.RegisterUser(email, "password123")
+		svc.RegisterUser(t.Context(), email, "password123")
 
 		// When: 같은 이메일로 재등록
-		_, err := svc.RegisterUser(email, "password456")
+		_, err := svc.RegisterUser(t.Context(), email, "password456")
 
-		// Then: 에러 발생
-		if err == nil {
-			t.Fatal("expected error, got nil")
+		// Then: ErrEmailAlreadyRegistered
+		if !errors.Is(err, ErrEmailAlreadyRegistered) {
+			t.Fatalf("expected ErrEmailAlreadyRegistered, got %v", err)
+		}
+	})
+
+	t.Run("동시 가입으로 저장 시 중복", func(t *testing.T) {
+		// Given: 조회 뒤 다른 요청이 먼저 저장한 이메일
+		repo := newMockUserRepository()
+		NewUserService(repo).RegisterUser(t.Context(), "race@example.com", "password123")
+		svc := NewUserService(lateUserRepository{repo})
+
+		// When: 조회에서는 보이지 않는 상태로 등록
+		_, err := svc.RegisterUser(t.Context(), "race@example.com", "password456")
+
+		// Then: ErrEmailAlreadyRegistered
+		if !errors.Is(err, ErrEmailAlreadyRegistered) {
+			t.Fatalf("expected ErrEmailAlreadyRegistered, got %v", err)
+		}
+	})
+
+	t.Run("잘못된 이메일과 짧은 비밀번호", func(t *testing.T) {
+		// Given: 두 필드 모두 잘못된 요청
+		svc := NewUserService(newMockUserRepository())
+
+		// When: 사용자 등록
+		_, err := svc.RegisterUser(t.Context(), "invalid-email", "short")
+
+		// Then: 두 필드를 모두 담은 ValidationError
+		var ve *ValidationError
+		if !errors.As(err, &ve) || len(ve.Fields) != 2 {
+			t.Fatalf("expected ValidationError with 2 fields, got %v", err)
+		}
+		if !errors.Is(err, user.ErrInvalidEmail) || !errors.Is(err, user.ErrPasswordTooShort) {
+			t.Errorf("expected to match domain errors, got %v", err)
 		}
 	})
 
@@ -124,7 +210,7 @@ func TestUserService_RegisterUser(t *testing.T) {
 		svc := NewUserService(repo)
 
 		// When: 잘못된 이메일로 등록
-		_, err := svc.RegisterUser("invalid-email", "password123")
+		_, err := svc.RegisterUser(t.Context(), "invalid-email", "password123")
 
 		// Then: 에러 발생
 		if err == nil {
@@ -138,7 +224,7 @@ func TestUserService_RegisterUser(t *testing.T) {
 		svc := NewUserService(repo)
 
 		// When: 짧은 비밀번호로 등록
-		_, err := svc.RegisterUser("test@example.com", "short")
+		_, err := svc.RegisterUser(t.Context(), "test@example.com", "short")
 
 		// Then: 에러 발생
 		if err == nil {
@@ -152,10 +238,10 @@ func TestUserService_GetUser(t *testing.T) {
 		// Given: 등록된 사용자
 		repo := newMockUserRepository()
 		svc := NewUserService(repo)
-		created, _ := svc.RegisterUser("test@example.com", "password123")
+		created, _ := svc.RegisterUser(t.Context(), "test@example.com", "password123")
 
 		// When: 사용자 조회
-		u, err := svc.GetUser(created.ID().Value())
+		u, err := svc.GetUser(t.Context(), created.ID().Value())
 
 		// Then: 사용자를 찾음
 		if err != nil {
@@ -172,7 +258,7 @@ func TestUserService_GetUser(t *testing.T) {
 		svc := NewUserService(repo)
 
 		// When: 존재하지 않는 사용자 조회
-		_, err := svc.GetUser(999)
+		_, err := svc.GetUser(t.Context(), user.GenerateUserID().Value())
 
 		// Then: 에러 발생
 		if err != ErrUserNotFound {
@@ -185,17 +271,17 @@ func TestUserService_ChangePassword(t *testing.T) {
 	// Given: 등록된 사용자
 	repo := newMockUserRepository()
 	svc := NewUserService(repo)
-	u, _ := svc.RegisterUser("test@example.com", "oldpassword123")
+	u, _ := svc.RegisterUser(t.Context(), "test@example.com", "oldpassword123")
 
 	// When: 비밀번호 변경
-	err := svc.ChangePassword(u.ID().Value(), "newpassword123")
+	err := svc.ChangePassword(t.Context(), u.ID().Value(), "newpassword123")
 
 	// Then: 비밀번호가 변경됨
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
 
-	updated, _ := svc.GetUser(u.ID().Value())
+	updated, _ := svc.GetUser(t.Context(), u.ID().Value())
 	if !updated.Authenticate("newpassword123") {
 		t.Error("expected to authenticate with new password")
 	}
@@ -208,17 +294,17 @@ func TestUserService_VerifyEmail(t *testing.T) {
 	// Given: 등록된 사용자
 	repo := newMockUserRepository()
 	svc := NewUserService(repo)
-	u, _ := svc.RegisterUser("test@example.com", "password123")
+	u, _ := svc.RegisterUser(t.Context(), "test@example.com", "password123")
 
 	// When: 이메일 인증
-	err := svc.VerifyEmail(u.ID().Value())
+	err := svc.VerifyEmail(t.Context(), u.ID().Value())
 
 	// Then: 이메일이 인증됨
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
 
-	updated, _ := svc.GetUser(u.ID().Value())
+	updated, _ := svc.GetUser(t.Context(), u.ID().Value())
 	if !updated.EmailVerified() {
 		t.Error("expected email to be verified")
 	}
@@ -228,17 +314,17 @@ func TestUserService_ChangeRole(t *testing.T) {
 	// Given: 등록된 사용자
 	repo := newMockUserRepository()
 	svc := NewUserService(repo)
-	u, _ := svc.RegisterUser("test@example.com", "password123")
+	u, _ := svc.RegisterUser(t.Context(), "test@example.com", "password123")
 
 	// When: 역할을 관리자로 변경
-	err := svc.ChangeRole(u.ID().Value(), "admin")
+	err := svc.ChangeRole(t.Context(), u.ID().Value(), "admin")
 
 	// Then: 역할이 변경됨
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
 
-	updated, _ := svc.GetUser(u.ID().Value())
+	updated, _ := svc.GetUser(t.Context(), u.ID().Value())
 	if !updated.IsAdmin() {
 		t.Error("expected user to be admin")
 	}
@@ -249,17 +335,17 @@ func TestUserService_SetActive(t *testing.T) {
 		// Given: 등록된 사용자
 		repo := newMockUserRepository()
 		svc := NewUserService(repo)
-		u, _ := svc.RegisterUser("test@example.com", "password123")
+		u, _ := svc.RegisterUser(t.Context(), "test@example.com", "password123")
 
 		// When: 비활성화
-		err := svc.SetActive(u.ID().Value(), false)
+		err := svc.SetActive(t.Context(), u.ID().Value(), false)
 
 		// Then: 비활성화됨
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
 
-		updated, _ := svc.GetUser(u.ID().Value())
+		updated, _ := svc.GetUser(t.Context(), u.ID().Value())
 		if updated.Active() {
 			t.Error("expected user to be inactive")
 		}
@@ -269,18 +355,18 @@ func TestUserService_SetActive(t *testing.T) {
 		// Given: 비활성 사용자
 		repo := newMockUserRepository()
 		svc := NewUserService(repo)
-		u, _ := svc.RegisterUser("test@example.com", "password123")
-		svc.SetActive(u.ID().Value(), false)
+		u, _ := svc.RegisterUser(t.Context(), "test@example.com", "password123")
+		svc.SetActive(t.Context(), u.ID().Value(), false)
 
 		// When: 활성화
-		err := svc.SetActive(u.ID().Value(), true)
+		err := svc.SetActive(t.Context(), u.ID().Value(), true)
 
 		// Then: 활성화됨
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
 
-		updated, _ := svc.GetUser(u.ID().Value())
+		updated, _ := svc.GetUser(t.Context(), u.ID().Value())
 		if !updated.Active() {
 			t.Error("expected user to be active")
 		}
@@ -292,18 +378,18 @@ func TestUserService_DeleteUser(t *testing.T) {
 		// Given: 두 명의 사용자
 		repo := newMockUserRepository()
 		svc := NewUserService(repo)
-		u1, _ := svc.RegisterUser("user1@example.com", "password123")
-		u2, _ := svc.RegisterUser("user2@example.com", "password123")
+		u1, _ := svc.RegisterUser(t.Context(), "user1@example.com", "password123")
+		u2, _ := svc.RegisterUser(t.Context(), "user2@example.com", "password123")
 
 		// When: 다른 사용자 삭제
-		err := svc.DeleteUser(u2.ID().Value(), u1.ID().Value())
+		err := svc.DeleteUser(t.Context(), u2.ID().Value(), u1.ID().Value())
 
 		// Then: 삭제됨
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
 
-		_, err = svc.GetUser(u2.ID().Value())
+		_, err = svc.GetUser(t.Context(), u2.ID().Value())
 		if err != ErrUserNotFound {
 			t.Error("expected user to be deleted")
 		}
@@ -313,10 +399,10 @@ func TestUserService_DeleteUser(t *testing.T) {
 		// Given: 사용자
 		repo := newMockUserRepository()
 		svc := NewUserService(repo)
-		u, _ := svc.RegisterUser("test@example.com", "password123")
+		u, _ := svc.RegisterUser(t.Context(), "test@example.com", "password123")
 
 		// When: 자기 자신 삭제 시도
-		err := svc.DeleteUser(u.ID().Value(), u.ID().Value())
+		err := svc.DeleteUser(t.Context(), u.ID().Value(), u.ID().Value())
 
 		// Then: 에러 발생
 		if err != ErrCannotDeleteSelf {
@@ -329,21 +415,70 @@ func TestUserService_ListUsers(t *testing.T) {
 	// Given: 여러 사용자
 	repo := newMockUserRepository()
 	svc := NewUserService(repo)
-	svc.RegisterUser("user1@example.com", "password123")
-	svc.RegisterUser("user2@example.com", "password123")
-	svc.RegisterUser("user3@example.com", "password123")
+	svc.RegisterUser(t.Context(), "user1@example.com", "password123")
+	svc.RegisterUser(t.Context(), "user2@example.com", "password123")
+	svc.RegisterUser(t.Context(), "user3@example.com", "password123")
 
 	// When: 사용자 목록 조회
-	users, total, err := svc.ListUsers(10, 0)
+	page, err := svc.ListUsers(t.Context(), 10, 0)
 
 	// Then: 모든 사용자 반환
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
-	if total != 3 {
-		t.Errorf("expected total 3, got %d", total)
+	if page.Total != 3 {
+		t.Errorf("expected total 3, got %d", page.Total)
 	}
-	if len(users) != 3 {
-		t.Errorf("expected 3 users, got %d", len(users))
+	if len(page.Users) != 3 {
+		t.Errorf("expected 3 users, got %d", len(page.Users))
 	}
+	if page.Limit != 10 || page.Offset != 0 {
+		t.Errorf("expected limit 10 offset 0, got %d %d", page.Limit, page.Offset)
+	}
+}
+
+func TestUserService_UpdateUser(t *testing.T) {
+	t.Run("여러 필드를 한 번에 변경", func(t *testing.T) {
+		// Given: 등록된 사용자
+		repo := newMockUserRepository()
+		svc := NewUserService(repo)
+		u, _ := svc.RegisterUser(t.Context(), "test@example.com", "password123")
+		role, active, verified := "admin", false, true
+
+		// When: 현재 버전으로 여러 필드 변경
+		updated, err := svc.UpdateUser(t.Context(), u.ID().Value(), UserChanges{
+			Role:          &role,
+			Active:        &active,
+			EmailVerified: &verified,
+		}, u.Version())
+
+		// Then: 모든 변경이 반영되고 버전이 증가함
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !updated.IsAdmin() || updated.Active() || !updated.EmailVerified() {
+			t.Error("expected all changes to be applied")
+		}
+		if updated.Version() != 2 {
+			t.Errorf("expected version 2, got %d", updated.Version())
+		}
+	})
+
+	t.Run("오래된 버전으로 변경 시도", func(t *testing.T) {
+		// Given: 다른 관리자가 이미 변경한 사용자
+		repo := newMockUserRepository()
+		svc := NewUserService(repo)
+		u, _ := svc.RegisterUser(t.Context(), "test@example.com", "password123")
+		staleVersion := u.Version()
+		svc.ChangeRole(t.Context(), u.ID().Value(), "admin")
+		active := false
+
+		// When: 오래된 버전으로 변경
+		_, err := svc.UpdateUser(t.Context(), u.ID().Value(), UserChanges{Active: &active}, staleVersion)
+
+		// Then: ErrConcurrentModification
+		if !errors.Is(err, user.ErrConcurrentModification) {
+			t.Errorf("expected ErrConcurrentModification, got %v", err)
+		}
+	})
 }
@@ -1,6 +1,7 @@
 package application
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -15,38 +16,41 @@ var (
 
 // VerificationService handles email verification and password reset
 type VerificationService struct {
-	userRepo          user.Repository
-	emailVerifRepo    verification.EmailVerificationRepository
-	passwordResetRepo verification.PasswordResetRepository
-	verificationTTL   time.Duration
-	passwordResetTTL  time.Duration
+	uow              UnitOfWork
+	userRepo         user.Repository
+	emailVerifRepo   verification.EmailVerificationRepository
+	verificationTTL  time.Duration
+	passwordResetTTL time.Duration
 }
 
 // NewVerificationService creates a new VerificationService
 func NewVerificationService(
+	uow UnitOfWork,
 	userRepo user.Repository,
 	emailVerifRepo verification.EmailVerificationRepository,
-	passwordResetRepo verification.PasswordResetRepository,
 	verificationTTL time.Duration,
 	passwordResetTTL time.Duration,
 ) *VerificationService {
 	return &VerificationService{
-		userRepo:          userRepo,
-		emailVerifRepo:    emailVerifRepo,
-		passwordResetRepo: passwordResetRepo,
-		verificationTTL:   verificationTTL,
-		passwordResetTTL:  passwordResetTTL,
+		uow:              uow,
+		userRepo:         userRepo,
+		emailVerifRepo:   emailVerifRepo,
+		verificationTTL:  verificationTTL,
+		passwordResetTTL: passwordResetTTL,
 	}
 }
 
 // RequestEmailVerification creates a verification token for a user
-func (s *VerificationService) RequestEmailVerification(userID uint) (string, error) {
+func (s *VerificationService) RequestEmailVerification(ctx context.Context, userID string) (_ string, err error) {
+	ctx, span := startSpan(ctx, "VerificationService.RequestEmailVerification")
+	defer func() { endSpan(span, err) }()
+
 	uid, err := user.NewUserID(userID)
 	if err != nil {
 		return "", err
 	}
 
-	u, err := s.userRepo.FindByID(uid)
+	u, err := s.userRepo.FindByID(ctx, uid)
 	if err != nil {
 		return "", ErrUserNotFound
 	}
@@ -57,7 +61,7 @@ func (s *VerificationService) RequestEmailVerification(userID uint) (string, err
 
 	verif := verification.NewEmailVerification(u.ID(), s.verificationTTL)
 
-	if err := s.emailVerifRepo.Save(verif); err != nil {
+	if err := s.emailVerifRepo.Save(ctx, verif); err != nil {
 		return "", err
 	}
 
@@ -65,40 +69,48 @@ func (s *VerificationService) RequestEmailVerification(userID uint) (string, err
 }
 
 // VerifyEmail verifies an email using a token
-func (s *VerificationService) VerifyEmail(token string) error {
-	verif, err := s.emailVerifRepo.FindByToken(token)
-	if err != nil {
-		return ErrInvalidToken
-	}
-
-	if verif.IsExpired() {
-		return ErrInvalidToken
-	}
-
-	u, err := s.userRepo.FindByID(verif.UserID())
-	if err != nil {
-		return ErrUserNotFound
-	}
-
-	if err := u.VerifyEmail(); err != nil {
-		return err
-	}
-
-	if err := s.userRepo.Save(u); err != nil {
-		return err
-	}
-
-	return s.emailVerifRepo.Delete(token)
+func (s *VerificationService) VerifyEmail(ctx context.Context, token string) (err error) {
+	ctx, span := startSpan(ctx, "VerificationService.VerifyEmail")
+	defer func() { endSpan(span, err) }()
+
+	return s.uow.Do(ctx, func(repos Repositories) error {
+		verif, err := repos.EmailVerifications.FindByToken(ctx, token)
+		if err != nil {
+			return ErrInvalidToken
+		}
+
+		if verif.IsExpired() {
+			return ErrInvalidToken
+		}
+
+		u, err := repos.Users.FindByID(ctx, verif.UserID())
+		if err != nil {
+			return ErrUserNotFound
+		}
+
+		if err := u.VerifyEmail(); err != nil {
+			return err
+		}
+
+		if err := repos.Users.Save(ctx, u); err != nil {
+			return err
+		}
+
+		return repos.EmailVerifications.Delete(ctx, token)
+	})
 }
 
 // RequestPasswordReset creates a password reset token
-func (s *VerificationService) RequestPasswordReset(email string) (string, error) {
+func (s *VerificationService) RequestPasswordReset(ctx context.Context, email string) (_ string, err error) {
+	ctx, span := startSpan(ctx, "VerificationService.RequestPasswordReset")
+	defer func() { endSpan(span, err) }()
+
 	emailVO, err := user.NewEmail(email)
 	if err != nil {
-		return "", err
+		return "", validate(FieldError{Field: "email", Err: err})
 	}
 
-	u, err := s.userRepo.FindByEmail(emailVO)
+	u, err := s.userRepo.FindByEmail(ctx, emailVO)
 	if err != nil {
 		// Don't reveal if email exists or not
 		return "", nil
@@ -108,11 +120,16 @@ func (s *VerificationService) RequestPasswordReset(email string) (string, error)
 		return "", nil
 	}
 
-	s.passwordResetRepo.DeleteByUserID(u.ID())
-
 	reset := verification.NewPasswordReset(u.ID(), s.passwordResetTTL)
 
-	if err := s.passwordResetRepo.Save(reset); err != nil {
+	// Replace any outstanding tokens atomically
+	err = s.uow.Do(ctx, func(repos Repositories) error {
+		if err := repos.PasswordResets.DeleteByUserID(ctx, u.ID()); err != nil {
+			return err
+		}
+		return repos.PasswordResets.Save(ctx, reset)
+	})
+	if err != nil {
 		return "", err
 	}
 
@@ -120,35 +137,40 @@ func (s *VerificationService) RequestPasswordReset(email string) (string, error)
 }
 
 // ResetPassword resets a password using a token
-func (s *VerificationService) ResetPassword(token, newPassword string) error {
-	reset, err := s.passwordResetRepo.FindByToken(token)
-	if err != nil {
-		return ErrInvalidToken
-	}
-
-	if reset.IsExpired() {
-		return ErrInvalidToken
-	}
-
-	u, err := s.userRepo.FindByID(reset.UserID())
-	if err != nil {
-		return ErrUserNotFound
-	}
+func (s *VerificationService) ResetPassword(ctx context.Context, token, newPassword string) (err error) {
+	ctx, span := startSpan(ctx, "VerificationService.ResetPassword")
+	defer func() { endSpan(span, err) }()
 
+	// Hash outside the transaction; bcrypt is slow and needs no locks
 	newPass, err := user.NewPassword(newPassword)
 	if err != nil {
-		return err
+		return validate(FieldError{Field: "new_password", Err: err})
 	}
 
-	if err := u.ChangePassword(newPass); err != nil {
-		return err
-	}
+	return s.uow.Do(ctx, func(repos Repositories) error {
+		reset, err := repos.PasswordResets.FindByToken(ctx, token)
+		if err != nil {
+			return ErrInvalidToken
+		}
 
-	if err := s.userRepo.Save(u); err != nil {
-		return err
-	}
+		if reset.IsExpired() {
+			return ErrInvalidToken
+		}
+
+		u, err := repos.Users.FindByID(ctx, reset.UserID())
+		if err != nil {
+			return ErrUserNotFound
+		}
+
+		if err := u.ChangePassword(newPass); err != nil {
+			return err
+		}
 
-	s.passwordResetRepo.Delete(token)
+		if err := repos.Users.Save(ctx, u); err != nil {
+			return err
+		}
 
-	return s.passwordResetRepo.DeleteByUserID(u.ID())
+		// Consumes this token and any other outstanding ones
+		return repos.PasswordResets.DeleteByUserID(ctx, u.ID())
+	})
 }
@@ -0,0 +1,59 @@
+package session
+
+import (
+	"github.com/junghwan16/test-server/internal/identity/domain/user"
+	"github.com/junghwan16/test-server/internal/shared/domain"
+)
+
+// Reasons a login is rejected
+const (
+	FailureInvalidEmail  = "invalid_email"
+	FailureUnknownUser   = "unknown_user"
+	FailureWrongPassword = "wrong_password"
+)
+
+// LoginSucceeded is fired when a user signs in. It deliberately does not
+// implement user.Event: signing in does not change the user.
+type LoginSucceeded struct {
+	domain.BaseEvent
+	UserID user.UserID `json:"user_id"`
+}
+
+// EventType returns the event type
+func (e LoginSucceeded) EventType() string {
+	return "identity.session.login_succeeded"
+}
+
+// NewLoginSucceeded creates a new LoginSucceeded event
+func NewLoginSucceeded(userID user.UserID) LoginSucceeded {
+	return LoginSucceeded{
+		BaseEvent: domain.NewBaseEvent(),
+		UserID:    userID,
+	}
+}
+
+// LoginFailed is fired when a login is rejected. It carries no email so
+// failed attempts do not spread PII through the event stream.
+type LoginFailed struct {
+	domain.BaseEvent
+	Reason string `json:"reason"`
+}
+
+// EventType returns the event type
+func (e LoginFailed) EventType() string {
+	return "identity.session.login_failed"
+}
+
+// NewLoginFailed creates a new LoginFailed event
+func NewLoginFailed(reason string) LoginFailed {
+	return LoginFailed{
+		BaseEvent: domain.NewBaseEvent(),
+		Reason:    reason,
+	}
+}
+
+// RegisterEvents registers all identity session events with the registry
+func RegisterEvents(r *domain.EventRegistry) {
+	domain.RegisterEvent[LoginSucceeded](r)
+	domain.RegisterEvent[LoginFailed](r)
+}
@@ -1,19 +1,25 @@
 package session
 
 import (
+	"context"
+	"errors"
+
 	"github.com/junghwan16/test-server/internal/identity/domain/user"
 )
 
+// ErrSessionNotFound is returned by FindByID for unknown or expired sessions
+var ErrSessionNotFound = errors.New("session not found or expired")
+
 // Repository defines the interface for Session aggregate persistence
 type Repository interface {
-	Save(session *Session) error
+	Save(ctx context.Context, session *Session) error
 
 	// FindByID retrieves a Session by ID
-	FindByID(id SessionID) (*Session, error)
+	FindByID(ctx context.Context, id SessionID) (*Session, error)
 
-	Delete(id SessionID) error
+	Delete(ctx context.Context, id SessionID) error
 
-	DeleteExpired() error
+	DeleteExpired(ctx context.Context) error
 
-	DeleteByUserID(userID user.UserID) error
+	DeleteByUserID(ctx context.Context, userID user.UserID) error
 }
@@ -10,15 +10,17 @@ import (
 type Session struct {
 	id        SessionID
 	userID    user.UserID
+	clientIP  string // address the session was created from; "" if unknown
 	expiresAt time.Time
 	createdAt time.Time
 }
 
-// NewSession creates a new Session aggregate
-func NewSession(id SessionID, userID user.UserID, ttl int) *Session {
+// NewSession creates a new Session aggregate for a sign-in from clientIP
+func NewSession(id SessionID, userID user.UserID, clientIP string, ttl int) *Session {
 	return &Session{
 		id:        id,
 		userID:    userID,
+		clientIP:  clientIP,
 		expiresAt: time.Now().Add(time.Duration(ttl) * time.Second),
 		createdAt: time.Now(),
 	}
@@ -28,11 +30,13 @@ func NewSession(id SessionID, userID user.UserID, ttl int) *Session {
 func ReconstructSession(
 	id SessionID,
 	userID user.UserID,
+	clientIP string,
 	expiresAt, createdAt time.Time,
 ) *Session {
 	return &Session{
 		id:        id,
 		userID:    userID,
+		clientIP:  clientIP,
 		expiresAt: expiresAt,
 		createdAt: createdAt,
 	}
@@ -42,6 +46,7 @@ func ReconstructSession(
 
 func (s *Session) ID() SessionID        { return s.id }
 func (s *Session) UserID() user.UserID  { return s.userID }
+func (s *Session) ClientIP() string     { return s.clientIP }
 func (s *Session) ExpiresAt() time.Time { return s.expiresAt }
 func (s *Session) CreatedAt() time.Time { return s.createdAt }
 
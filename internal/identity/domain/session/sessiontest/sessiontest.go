@@ -0,0 +1,167 @@
+// Package sessiontest provides a conformance test suite for session.Repository implementations.
+package sessiontest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/junghwan16/test-server/internal/identity/domain/session"
+	"github.com/junghwan16/test-server/internal/identity/domain/user"
+)
+
+// TestRepository runs the session.Repository conformance suite. newRepo is
+// called once per subtest; every subtest uses fresh session and user IDs, so
+// repositories backed by a shared server such as Redis need not be emptied.
+func TestRepository(t *testing.T, newRepo func(t *testing.T) session.Repository) {
+	t.Helper()
+
+	// Generated user IDs are unique per run, keeping shared stores isolated
+	alice := user.GenerateUserID()
+	bob := user.GenerateUserID()
+
+	t.Run("저장 후 조회", func(t *testing.T) {
+		// Given: 저장된 세션
+		repo := newRepo(t)
+		ctx := t.Context()
+		s := session.NewSession(session.GenerateSessionID(), alice, "203.0.113.7", 3600)
+		if err := repo.Save(ctx, s); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		// When: ID로 조회
+		found, err := repo.FindByID(ctx, s.ID())
+
+		// Then: 같은 사용자, 접속 IP 와 만료 시각
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !found.UserID().Equals(alice) {
+			t.Errorf("expected user %v, got %v", alice.Value(), found.UserID().Value())
+		}
+		if found.ClientIP() != "203.0.113.7" {
+			t.Errorf("expected client IP 203.0.113.7, got %q", found.ClientIP())
+		}
+		if !found.ExpiresAt().Equal(s.ExpiresAt()) {
+			t.Errorf("expected expiry %v, got %v", s.ExpiresAt(), found.ExpiresAt())
+		}
+	})
+
+	t.Run("없는 세션", func(t *testing.T) {
+		// When: 저장된 적 없는 ID로 조회
+		repo := newRepo(t)
+		_, err := repo.FindByID(t.Context(), session.GenerateSessionID())
+
+		// Then: ErrSessionNotFound
+		if !errors.Is(err, session.ErrSessionNotFound) {
+			t.Errorf("expected ErrSessionNotFound, got %v", err)
+		}
+	})
+
+	t.Run("이미 만료된 세션 저장", func(t *testing.T) {
+		// Given: 만료 시각이 지난 세션
+		repo := newRepo(t)
+		s := session.ReconstructSession(session.GenerateSessionID(), alice, "", time.Now().Add(-time.Minute), time.Now().Add(-time.Hour))
+
+		// When: 저장
+		err := repo.Save(t.Context(), s)
+
+		// Then: 거부됨
+		if err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+
+	t.Run("같은 ID 재저장", func(t *testing.T) {
+		// Given: 저장된 세션
+		repo := newRepo(t)
+		ctx := t.Context()
+		id := session.GenerateSessionID()
+		repo.Save(ctx, session.NewSession(id, alice, "", 60))
+
+		// When: 같은 ID로 만료를 늘려 저장
+		extended := session.NewSession(id, alice, "", 7200)
+		err := repo.Save(ctx, extended)
+
+		// Then: 덮어쓴 값이 조회됨
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		found, err := repo.FindByID(ctx, id)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !found.ExpiresAt().Equal(extended.ExpiresAt()) {
+			t.Errorf("expected expiry %v, got %v", extended.ExpiresAt(), found.ExpiresAt())
+		}
+	})
+
+	t.Run("삭제", func(t *testing.T) {
+		// Given: 저장된 세션
+		repo := newRepo(t)
+		ctx := t.Context()
+		s := session.NewSession(session.GenerateSessionID(), alice, "", 3600)
+		repo.Save(ctx, s)
+
+		// When: 삭제
+		err := repo.Delete(ctx, s.ID())
+
+		// Then: 더 이상 조회되지 않음
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if _, err := repo.FindByID(ctx, s.ID()); err == nil {
+			t.Error("expected deleted session to be gone")
+		}
+	})
+
+	t.Run("사용자별 삭제", func(t *testing.T) {
+		// Given: 두 사용자의 세션
+		repo := newRepo(t)
+		ctx := t.Context()
+		aliceSession := session.NewSession(session.GenerateSessionID(), alice, "", 3600)
+		bobSession := session.NewSession(session.GenerateSessionID(), bob, "", 3600)
+		repo.Save(ctx, aliceSession)
+		repo.Save(ctx, bobSession)
+
+		// When: alice 의 세션 삭제
+		if err := repo.DeleteByUserID(ctx, alice); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		// Then: bob 의 세션만 남음
+		if _, err := repo.FindByID(ctx, aliceSession.ID()); err == nil {
+			t.Error("expected alice's session to be deleted")
+		}
+		if _, err := repo.FindByID(ctx, bobSession.ID()); err != nil {
+			t.Errorf("expected bob's session to remain, got %v", err)
+		}
+	})
+
+	t.Run("만료 세션", func(t *testing.T) {
+		// Given: 곧 만료되는 세션과 유효한 세션
+		repo := newRepo(t)
+		ctx := t.Context()
+		expiring := session.ReconstructSession(session.GenerateSessionID(), bob, "", time.Now().Add(50*time.Millisecond), time.Now())
+		valid := session.NewSession(session.GenerateSessionID(), bob, "", 3600)
+		if err := repo.Save(ctx, expiring); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		repo.Save(ctx, valid)
+		time.Sleep(100 * time.Millisecond)
+
+		// When: 만료 세션 정리
+		err := repo.DeleteExpired(ctx)
+
+		// Then: 만료 세션은 조회되지 않고 유효한 세션은 유지
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if _, err := repo.FindByID(ctx, expiring.ID()); !errors.Is(err, session.ErrSessionNotFound) {
+			t.Errorf("expected ErrSessionNotFound, got %v", err)
+		}
+		if _, err := repo.FindByID(ctx, valid.ID()); err != nil {
+			t.Errorf("expected valid session to remain, got %v", err)
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package user
 
 import (
+	"encoding/json"
 	"errors"
 	"strings"
 )
@@ -56,3 +57,22 @@ func (e Email) Equals(other Email) bool {
 func (e Email) String() string {
 	return e.value
 }
+
+// MarshalJSON encodes the email as a JSON string
+func (e Email) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.value)
+}
+
+// UnmarshalJSON decodes and validates the email from a JSON string
+func (e *Email) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	parsed, err := NewEmail(value)
+	if err != nil {
+		return err
+	}
+	*e = parsed
+	return nil
+}
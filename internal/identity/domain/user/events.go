@@ -4,11 +4,17 @@ import (
 	"github.com/junghwan16/test-server/internal/shared/domain"
 )
 
+// Event is implemented by every event about a single user
+type Event interface {
+	domain.DomainEvent
+	AggregateID() UserID
+}
+
 // UserRegistered is fired when a new user registers
 type UserRegistered struct {
 	domain.BaseEvent
-	UserID UserID
-	Email  Email
+	UserID UserID `json:"user_id"`
+	Email  Email  `json:"email"`
 }
 
 // EventType returns the event type
@@ -16,6 +22,11 @@ func (e UserRegistered) EventType() string {
 	return "identity.user.registered"
 }
 
+// AggregateID returns the ID of the affected user
+func (e UserRegistered) AggregateID() UserID {
+	return e.UserID
+}
+
 // NewUserRegistered creates a new UserRegistered event
 func NewUserRegistered(userID UserID, email Email) UserRegistered {
 	return UserRegistered{
@@ -28,7 +39,7 @@ func NewUserRegistered(userID UserID, email Email) UserRegistered {
 // EmailVerified is fired when an email is verified
 type EmailVerified struct {
 	domain.BaseEvent
-	UserID UserID
+	UserID UserID `json:"user_id"`
 }
 
 // EventType returns the event type
@@ -36,6 +47,11 @@ func (e EmailVerified) EventType() string {
 	return "identity.user.email_verified"
 }
 
+// AggregateID returns the ID of the affected user
+func (e EmailVerified) AggregateID() UserID {
+	return e.UserID
+}
+
 // NewEmailVerified creates a new EmailVerified event
 func NewEmailVerified(userID UserID) EmailVerified {
 	return EmailVerified{
@@ -47,7 +63,7 @@ func NewEmailVerified(userID UserID) EmailVerified {
 // PasswordChanged is fired when a password is changed
 type PasswordChanged struct {
 	domain.BaseEvent
-	UserID UserID
+	UserID UserID `json:"user_id"`
 }
 
 // EventType returns the event type
@@ -55,6 +71,11 @@ func (e PasswordChanged) EventType() string {
 	return "identity.user.password_changed"
 }
 
+// AggregateID returns the ID of the affected user
+func (e PasswordChanged) AggregateID() UserID {
+	return e.UserID
+}
+
 // NewPasswordChanged creates a new PasswordChanged event
 func NewPasswordChanged(userID UserID) PasswordChanged {
 	return PasswordChanged{
@@ -66,7 +87,7 @@ func NewPasswordChanged(userID UserID) PasswordChanged {
 // UserDeactivated is fired when a user is deactivated
 type UserDeactivated struct {
 	domain.BaseEvent
-	UserID UserID
+	UserID UserID `json:"user_id"`
 }
 
 // EventType returns the event type
@@ -74,6 +95,11 @@ func (e UserDeactivated) EventType() string {
 	return "identity.user.deactivated"
 }
 
+// AggregateID returns the ID of the affected user
+func (e UserDeactivated) AggregateID() UserID {
+	return e.UserID
+}
+
 // NewUserDeactivated creates a new UserDeactivated event
 func NewUserDeactivated(userID UserID) UserDeactivated {
 	return UserDeactivated{
@@ -85,9 +111,9 @@ func NewUserDeactivated(userID UserID) UserDeactivated {
 // RoleChanged is fired when a user's role is changed
 type RoleChanged struct {
 	domain.BaseEvent
-	UserID  UserID
-	OldRole Role
-	NewRole Role
+	UserID  UserID `json:"user_id"`
+	OldRole Role   `json:"old_role"`
+	NewRole Role   `json:"new_role"`
 }
 
 // EventType returns the event type
@@ -95,6 +121,11 @@ func (e RoleChanged) EventType() string {
 	return "identity.user.role_changed"
 }
 
+// AggregateID returns the ID of the affected user
+func (e RoleChanged) AggregateID() UserID {
+	return e.UserID
+}
+
 // NewRoleChanged creates a new RoleChanged event
 func NewRoleChanged(userID UserID, oldRole, newRole Role) RoleChanged {
 	return RoleChanged{
@@ -104,3 +135,12 @@ func NewRoleChanged(userID UserID, oldRole, newRole Role) RoleChanged {
 		NewRole:   newRole,
 	}
 }
+
+// RegisterEvents registers all identity user events with the registry
+func RegisterEvents(r *domain.EventRegistry) {
+	domain.RegisterEvent[UserRegistered](r)
+	domain.RegisterEvent[EmailVerified](r)
+	domain.RegisterEvent[PasswordChanged](r)
+	domain.RegisterEvent[UserDeactivated](r)
+	domain.RegisterEvent[RoleChanged](r)
+}
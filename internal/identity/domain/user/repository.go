@@ -1,20 +1,41 @@
 package user
 
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrUserNotFound is returned by finders when no user matches
+	ErrUserNotFound = errors.New("user not found")
+
+	// ErrConcurrentModification is returned by Save when the user was changed
+	// by someone else since it was loaded
+	ErrConcurrentModification = errors.New("user was modified concurrently")
+
+	// ErrDuplicateEmail is returned by Save when another user has the same email
+	ErrDuplicateEmail = errors.New("email already in use")
+)
+
 // Repository defines the interface for User aggregate persistence
 type Repository interface {
-	// NextID generates a new UserID
-	NextID() UserID
+	// NextID generates a new time-ordered UserID for a user not yet persisted
+	NextID(ctx context.Context) UserID
 
-	Save(user *User) error
+	// Save persists the user. A user that was never saved (version 0) is
+	// inserted under the ID it was created with. Updates succeed only if
+	// the stored version still matches user.Version(); otherwise
+	// ErrConcurrentModification is returned.
+	Save(ctx context.Context, user *User) error
 
 	// FindByID retrieves a User by ID
-	FindByID(id UserID) (*User, error)
+	FindByID(ctx context.Context, id UserID) (*User, error)
 
 	// FindByEmail retrieves a User by email
-	FindByEmail(email Email) (*User, error)
+	FindByEmail(ctx context.Context, email Email) (*User, error)
 
-	// FindAll retrieves all users with pagination
-	FindAll(limit, offset int) ([]*User, int64, error)
+	// FindAll retrieves all users with pagination, newest first
+	FindAll(ctx context.Context, limit, offset int) ([]*User, int64, error)
 
-	Delete(id UserID) error
+	Delete(ctx context.Context, id UserID) error
 }
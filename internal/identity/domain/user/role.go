@@ -1,6 +1,7 @@
 package user
 
 import (
+	"encoding/json"
 	"errors"
 )
 
@@ -58,3 +59,22 @@ func (r Role) Equals(other Role) bool {
 func (r Role) String() string {
 	return r.value
 }
+
+// MarshalJSON encodes the role as a JSON string
+func (r Role) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.value)
+}
+
+// UnmarshalJSON decodes and validates the role from a JSON string
+func (r *Role) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	parsed, err := NewRole(value)
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
@@ -16,6 +16,7 @@ type User struct {
 	active        bool
 	createdAt     time.Time
 	updatedAt     time.Time
+	version       int64 // persisted version for optimistic concurrency; 0 if never saved
 
 	// Domain events
 	events []domain.DomainEvent
@@ -48,6 +49,7 @@ func ReconstructUser(
 	role Role,
 	emailVerified, active bool,
 	createdAt, updatedAt time.Time,
+	version int64,
 ) *User {
 	return &User{
 		id:            id,
@@ -58,6 +60,7 @@ func ReconstructUser(
 		active:        active,
 		createdAt:     createdAt,
 		updatedAt:     updatedAt,
+		version:       version,
 		events:        make([]domain.DomainEvent, 0),
 	}
 }
@@ -70,6 +73,12 @@ func (u *User) EmailVerified() bool  { return u.emailVerified }
 func (u *User) Active() bool         { return u.active }
 func (u *User) CreatedAt() time.Time { return u.createdAt }
 func (u *User) UpdatedAt() time.Time { return u.updatedAt }
+func (u *User) Version() int64       { return u.version }
+
+// SetVersion records the version the aggregate was persisted at (called by repositories)
+func (u *User) SetVersion(version int64) {
+	u.version = version
+}
 
 // Authenticate checks if the password is correct
 func (u *User) Authenticate(plaintext string) bool {
@@ -1,26 +1,31 @@
 package user
 
 import (
+	"encoding/json"
 	"errors"
+
+	"github.com/google/uuid"
 )
 
-// UserID is a value object representing a user's unique identifier
+// UserID is a value object representing a user's unique identifier.
+// IDs are UUIDv7: opaque to clients, but time-ordered so they index well.
 type UserID struct {
-	value uint
+	value string
 }
 
 var ErrInvalidUserID = errors.New("invalid user ID")
 
-// NewUserID creates a new UserID
-func NewUserID(value uint) (UserID, error) {
-	if value == 0 {
+// NewUserID creates a UserID from its string form
+func NewUserID(value string) (UserID, error) {
+	parsed, err := uuid.Parse(value)
+	if err != nil || parsed == uuid.Nil {
 		return UserID{}, ErrInvalidUserID
 	}
-	return UserID{value: value}, nil
+	return UserID{value: parsed.String()}, nil
 }
 
 // MustNewUserID creates a UserID or panics
-func MustNewUserID(value uint) UserID {
+func MustNewUserID(value string) UserID {
 	id, err := NewUserID(value)
 	if err != nil {
 		panic(err)
@@ -28,8 +33,13 @@ func MustNewUserID(value uint) UserID {
 	return id
 }
 
+// GenerateUserID generates a new time-ordered UserID
+func GenerateUserID() UserID {
+	return UserID{value: uuid.Must(uuid.NewV7()).String()}
+}
+
 // Value returns the underlying value
-func (id UserID) Value() uint {
+func (id UserID) Value() string {
 	return id.value
 }
 
@@ -40,10 +50,29 @@ func (id UserID) Equals(other UserID) bool {
 
 // String returns string representation
 func (id UserID) String() string {
-	return string(rune(id.value))
+	return id.value
 }
 
 // IsZero returns true if the ID is zero value
 func (id UserID) IsZero() bool {
-	return id.value == 0
+	return id.value == ""
+}
+
+// MarshalJSON encodes the ID as a JSON string
+func (id UserID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(id.value)
+}
+
+// UnmarshalJSON decodes the ID from a JSON string
+func (id *UserID) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	parsed, err := NewUserID(value)
+	if err != nil {
+		return err
+	}
+	*id = parsed
+	return nil
 }
@@ -0,0 +1,95 @@
+package user
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNewUserID(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "유효한 UUID",
+			value: "01890a5d-ac96-774b-bcce-b302099a8057",
+			want:  "01890a5d-ac96-774b-bcce-b302099a8057",
+		},
+		{
+			name:  "대문자는 정규화됨",
+			value: "01890A5D-AC96-774B-BCCE-B302099A8057",
+			want:  "01890a5d-ac96-774b-bcce-b302099a8057",
+		},
+		{
+			name:    "순번 ID",
+			value:   "42",
+			wantErr: true,
+		},
+		{
+			name:    "nil UUID",
+			value:   "00000000-0000-0000-0000-000000000000",
+			wantErr: true,
+		},
+		{
+			name:    "빈 문자열",
+			value:   "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When: ID 생성
+			id, err := NewUserID(tt.value)
+
+			// Then: 예상된 결과
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if id.Value() != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, id.Value())
+			}
+		})
+	}
+}
+
+func TestGenerateUserID(t *testing.T) {
+	// Given: 연속으로 생성한 ID
+	ids := make([]UserID, 100)
+	for i := range ids {
+		ids[i] = GenerateUserID()
+	}
+
+	// Then: 모두 다르고 생성 순서대로 정렬됨
+	for i := 1; i < len(ids); i++ {
+		if ids[i].Value() <= ids[i-1].Value() {
+			t.Fatalf("expected %s > %s", ids[i], ids[i-1])
+		}
+	}
+	if _, err := NewUserID(ids[0].String()); err != nil {
+		t.Errorf("expected generated ID to parse, got %v", err)
+	}
+}
+
+func TestUserID_JSON(t *testing.T) {
+	// Given: 생성한 ID
+	id := GenerateUserID()
+
+	// When: JSON 왕복
+	data, _ := json.Marshal(id)
+	var decoded UserID
+	err := json.Unmarshal(data, &decoded)
+
+	// Then: 문자열로 인코딩되고 같은 ID로 복원됨
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if string(data) != `"`+id.String()+`"` {
+		t.Errorf("expected JSON string, got %s", data)
+	}
+	if !decoded.Equals(id) {
+		t.Errorf("expected %s, got %s", id, decoded)
+	}
+}
@@ -11,7 +11,7 @@ func testTime() time.Time {
 
 func TestNewUser(t *testing.T) {
 	// Given: 유효한 사용자 정보
-	id, _ := NewUserID(1)
+	id := GenerateUserID()
 	email, _ := NewEmail("test@example.com")
 	password, _ := NewPassword("password123")
 
@@ -72,7 +72,7 @@ func TestUser_Authenticate(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Given: 사용자
-			id, _ := NewUserID(1)
+			id := GenerateUserID()
 			email, _ := NewEmail("test@example.com")
 			password, _ := NewPassword("password123")
 			u, _ := NewUser(id, email, password)
@@ -95,7 +95,7 @@ func TestUser_Authenticate(t *testing.T) {
 func TestUser_VerifyEmail(t *testing.T) {
 	t.Run("이메일 인증 성공", func(t *testing.T) {
 		// Given: 인증되지 않은 사용자
-		id, _ := NewUserID(1)
+		id := GenerateUserID()
 		email, _ := NewEmail("test@example.com")
 		password, _ := NewPassword("password123")
 		u, _ := NewUser(id, email, password)
@@ -118,7 +118,7 @@ func TestUser_VerifyEmail(t *testing.T) {
 
 	t.Run("이미 인증된 이메일", func(t *testing.T) {
 		// Given: 이미 인증된 사용자
-		id, _ := NewUserID(1)
+		id := GenerateUserID()
 		email, _ := NewEmail("test@example.com")
 		password, _ := NewPassword("password123")
 		u, _ := NewUser(id, email, password)
@@ -140,7 +140,7 @@ func TestUser_VerifyEmail(t *testing.T) {
 
 func TestUser_ChangePassword(t *testing.T) {
 	// Given: 사용자
-	id, _ := NewUserID(1)
+	id := GenerateUserID()
 	email, _ := NewEmail("test@example.com")
 	oldPassword, _ := NewPassword("oldpassword123")
 	u, _ := NewUser(id, email, oldPassword)
@@ -172,7 +172,7 @@ func TestUser_ChangePassword(t *testing.T) {
 func TestUser_ChangeRole(t *testing.T) {
 	t.Run("사용자에서 관리자로 변경", func(t *testing.T) {
 		// Given: 사용자 역할을 가진 사용자
-		id, _ := NewUserID(1)
+		id := GenerateUserID()
 		email, _ := NewEmail("test@example.com")
 		password, _ := NewPassword("password123")
 		u, _ := NewUser(id, email, password)
@@ -195,10 +195,10 @@ func TestUser_ChangeRole(t *testing.T) {
 
 	t.Run("관리자에서 사용자로 변경", func(t *testing.T) {
 		// Given: 관리자 역할을 가진 사용자
-		id, _ := NewUserID(1)
+		id := GenerateUserID()
 		email, _ := NewEmail("test@example.com")
 		password, _ := NewPassword("password123")
-		u := ReconstructUser(id, email, password, AdminRole(), false, true, testTime(), testTime())
+		u := ReconstructUser(id, email, password, AdminRole(), false, true, testTime(), testTime(), 1)
 
 		// When: 사용자로 역할 변경
 		err := u.ChangeRole(UserRole())
@@ -217,7 +217,7 @@ func TestUser_ChangeRole(t *testing.T) {
 
 	t.Run("동일한 역할로 변경", func(t *testing.T) {
 		// Given: 사용자
-		id, _ := NewUserID(1)
+		id := GenerateUserID()
 		email, _ := NewEmail("test@example.com")
 		password, _ := NewPassword("password123")
 		u, _ := NewUser(id, email, password)
@@ -238,7 +238,7 @@ func TestUser_ChangeRole(t *testing.T) {
 
 func TestUser_Deactivate(t *testing.T) {
 	// Given: 활성 사용자
-	id, _ := NewUserID(1)
+	id := GenerateUserID()
 	email, _ := NewEmail("test@example.com")
 	password, _ := NewPassword("password123")
 	u, _ := NewUser(id, email, password)
@@ -261,7 +261,7 @@ func TestUser_Deactivate(t *testing.T) {
 
 func TestUser_Activate(t *testing.T) {
 	// Given: 비활성 사용자
-	id, _ := NewUserID(1)
+	id := GenerateUserID()
 	email, _ := NewEmail("test@example.com")
 	password, _ := NewPassword("password123")
 	u, _ := NewUser(id, email, password)
@@ -301,10 +301,10 @@ func TestUser_IsAdmin(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Given: 특정 역할을 가진 사용자
-			id, _ := NewUserID(1)
+			id := GenerateUserID()
 			email, _ := NewEmail("test@example.com")
 			password, _ := NewPassword("password123")
-			u := ReconstructUser(id, email, password, tt.role, false, true, testTime(), testTime())
+			u := ReconstructUser(id, email, password, tt.role, false, true, testTime(), testTime(), 1)
 
 			// When: 관리자 확인
 			got := u.IsAdmin()
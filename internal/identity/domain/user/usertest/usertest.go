@@ -0,0 +1,344 @@
+// Package usertest provides a conformance test suite for user.Repository implementations.
+package usertest
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/junghwan16/test-server/internal/identity/domain/user"
+)
+
+// TestRepository runs the user.Repository conformance suite. newRepo is
+// called once per subtest and must return an empty repository.
+func TestRepository(t *testing.T, newRepo func(t *testing.T) user.Repository) {
+	t.Helper()
+
+	t.Run("NextID 는 고유하고 시간순", func(t *testing.T) {
+		// Given: 저장소
+		ctx := t.Context()
+		repo := newRepo(t)
+
+		// When: ID 를 연속으로 생성
+		ids := make([]user.UserID, 50)
+		for i := range ids {
+			ids[i] = repo.NextID(ctx)
+		}
+
+		// Then: 모두 유효하고 생성 순서대로 정렬됨
+		for i, id := range ids {
+			if _, err := user.NewUserID(id.Value()); err != nil {
+				t.Fatalf("expected valid ID, got %q", id.Value())
+			}
+			if i > 0 && id.Value() <= ids[i-1].Value() {
+				t.Fatalf("expected %s > %s", id, ids[i-1])
+			}
+		}
+	})
+
+	t.Run("저장 시 버전 부여", func(t *testing.T) {
+		// Given: 새 사용자
+		ctx := t.Context()
+		repo := newRepo(t)
+		u := newUser(t, repo, "new@example.com")
+
+		// When: 저장
+		err := repo.Save(ctx, u)
+
+		// Then: 버전 1이 부여되고 생성 시 받은 ID로 조회됨
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if u.Version() != 1 {
+			t.Errorf("expected version 1, got %d", u.Version())
+		}
+		found, err := repo.FindByID(ctx, u.ID())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !found.ID().Equals(u.ID()) {
+			t.Errorf("expected ID %s, got %s", u.ID(), found.ID())
+		}
+		if found.Email() != u.Email() || found.Version() != 1 {
+			t.Errorf("expected %s at version 1, got %s at version %d",
+				u.Email().Value(), found.Email().Value(), found.Version())
+		}
+		if found.Role() != u.Role() || found.Active() != u.Active() || found.EmailVerified() != u.EmailVerified() {
+			t.Error("expected stored state to match saved user")
+		}
+		if found.Password().Hash() != u.Password().Hash() {
+			t.Error("expected password hash to round-trip")
+		}
+		if d := found.CreatedAt().Sub(u.CreatedAt()).Abs(); d > time.Millisecond {
+			t.Errorf("expected created_at to round-trip, off by %v", d)
+		}
+	})
+
+	t.Run("없는 사용자", func(t *testing.T) {
+		// Given: 빈 저장소
+		ctx := t.Context()
+		repo := newRepo(t)
+
+		// When: ID와 이메일로 조회
+		_, errByID := repo.FindByID(ctx, user.GenerateUserID())
+		_, errByEmail := repo.FindByEmail(ctx, user.MustNewEmail("missing@example.com"))
+
+		// Then: ErrUserNotFound
+		if !errors.Is(errByID, user.ErrUserNotFound) {
+			t.Errorf("expected ErrUserNotFound by ID, got %v", errByID)
+		}
+		if !errors.Is(errByEmail, user.ErrUserNotFound) {
+			t.Errorf("expected ErrUserNotFound by email, got %v", errByEmail)
+		}
+	})
+
+	t.Run("중복 이메일", func(t *testing.T) {
+		// Given: 이미 등록된 이메일
+		ctx := t.Context()
+		repo := newRepo(t)
+		mustSave(t, repo, newUser(t, repo, "dup@example.com"))
+
+		// When: 같은 이메일의 다른 사용자 저장
+		err := repo.Save(ctx, newUser(t, repo, "dup@example.com"))
+
+		// Then: ErrDuplicateEmail
+		if !errors.Is(err, user.ErrDuplicateEmail) {
+			t.Errorf("expected ErrDuplicateEmail, got %v", err)
+		}
+	})
+
+	t.Run("수정 후 저장", func(t *testing.T) {
+		// Given: 저장된 사용자
+		ctx := t.Context()
+		repo := newRepo(t)
+		u := newUser(t, repo, "update@example.com")
+		mustSave(t, repo, u)
+
+		// When: 조회한 사용자를 수정해 저장
+		loaded, _ := repo.FindByID(ctx, u.ID())
+		loaded.VerifyEmail()
+		err := repo.Save(ctx, loaded)
+
+		// Then: 변경이 반영되고 버전 증가
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if loaded.Version() != 2 {
+			t.Errorf("expected version 2, got %d", loaded.Version())
+		}
+		found, _ := repo.FindByID(ctx, u.ID())
+		if !found.EmailVerified() || found.Version() != 2 {
+			t.Errorf("expected verified user at version 2, got verified=%v version=%d",
+				found.EmailVerified(), found.Version())
+		}
+	})
+
+	t.Run("저장하지 않은 변경은 반영되지 않음", func(t *testing.T) {
+		// Given: 저장된 사용자
+		ctx := t.Context()
+		repo := newRepo(t)
+		u := newUser(t, repo, "detached@example.com")
+		mustSave(t, repo, u)
+
+		// When: 조회한 사용자를 수정만 함
+		loaded, _ := repo.FindByID(ctx, u.ID())
+		loaded.Deactivate()
+
+		// Then: 저장소의 사용자는 그대로
+		found, _ := repo.FindByID(ctx, u.ID())
+		if !found.Active() {
+			t.Error("expected unsaved change not to leak into the repository")
+		}
+	})
+
+	t.Run("오래된 버전 저장", func(t *testing.T) {
+		// Given: 같은 사용자를 두 번 읽음
+		ctx := t.Context()
+		repo := newRepo(t)
+		u := newUser(t, repo, "stale@example.com")
+		mustSave(t, repo, u)
+		first, _ := repo.FindByID(ctx, u.ID())
+		second, _ := repo.FindByID(ctx, u.ID())
+
+		// When: 둘 다 수정 후 차례로 저장
+		first.VerifyEmail()
+		errFirst := repo.Save(ctx, first)
+		second.Deactivate()
+		errSecond := repo.Save(ctx, second)
+
+		// Then: 늦은 쪽은 ErrConcurrentModification 이고 먼저 쓴 값만 남음
+		if errFirst != nil {
+			t.Fatalf("expected first save to succeed, got %v", errFirst)
+		}
+		if !errors.Is(errSecond, user.ErrConcurrentModification) {
+			t.Errorf("expected ErrConcurrentModification, got %v", errSecond)
+		}
+		found, _ := repo.FindByID(ctx, u.ID())
+		if !found.EmailVerified() || !found.Active() {
+			t.Errorf("expected first write only, got verified=%v active=%v", found.EmailVerified(), found.Active())
+		}
+	})
+
+	t.Run("동시 저장", func(t *testing.T) {
+		// Given: 여러 작업자가 같은 버전의 사용자를 읽음
+		ctx := t.Context()
+		repo := newRepo(t)
+		u := newUser(t, repo, "race@example.com")
+		mustSave(t, repo, u)
+
+		const workers = 8
+		copies := make([]*user.User, workers)
+		for i := range copies {
+			copies[i], _ = repo.FindByID(ctx, u.ID())
+			copies[i].ChangeRole(user.AdminRole())
+		}
+
+		// When: 동시에 저장
+		errs := make([]error, workers)
+		var wg sync.WaitGroup
+		for i := range copies {
+			wg.Go(func() { errs[i] = repo.Save(ctx, copies[i]) })
+		}
+		wg.Wait()
+
+		// Then: 정확히 하나만 성공하고 나머지는 ErrConcurrentModification
+		succeeded := 0
+		for _, err := range errs {
+			switch {
+			case err == nil:
+				succeeded++
+			case !errors.Is(err, user.ErrConcurrentModification):
+				t.Errorf("expected ErrConcurrentModification, got %v", err)
+			}
+		}
+		if succeeded != 1 {
+			t.Errorf("expected exactly 1 successful save, got %d", succeeded)
+		}
+		found, _ := repo.FindByID(ctx, u.ID())
+		if found.Version() != 2 {
+			t.Errorf("expected version 2, got %d", found.Version())
+		}
+	})
+
+	t.Run("동시 가입", func(t *testing.T) {
+		// Given: 같은 이메일의 새 사용자들
+		ctx := t.Context()
+		repo := newRepo(t)
+
+		const workers = 8
+		users := make([]*user.User, workers)
+		for i := range users {
+			users[i] = newUser(t, repo, "same@example.com")
+		}
+
+		// When: 동시에 저장
+		errs := make([]error, workers)
+		var wg sync.WaitGroup
+		for i := range users {
+			wg.Go(func() { errs[i] = repo.Save(ctx, users[i]) })
+		}
+		wg.Wait()
+
+		// Then: 정확히 하나만 성공하고 나머지는 ErrDuplicateEmail
+		succeeded := 0
+		for _, err := range errs {
+			switch {
+			case err == nil:
+				succeeded++
+			case !errors.Is(err, user.ErrDuplicateEmail):
+				t.Errorf("expected ErrDuplicateEmail, got %v", err)
+			}
+		}
+		if succeeded != 1 {
+			t.Errorf("expected exactly 1 successful save, got %d", succeeded)
+		}
+	})
+
+	t.Run("페이지네이션과 정렬", func(t *testing.T) {
+		// Given: 가입 시각이 서로 다른 사용자 5명
+		ctx := t.Context()
+		repo := newRepo(t)
+		base := time.Now().Add(-time.Hour).Truncate(time.Second)
+		for i := range 5 {
+			u := user.ReconstructUser(
+				repo.NextID(ctx),
+				user.MustNewEmail(fmt.Sprintf("user%d@example.com", i)),
+				user.NewPasswordFromHash("hash"),
+				user.UserRole(),
+				false, true,
+				base.Add(time.Duration(i)*time.Minute),
+				base.Add(time.Duration(i)*time.Minute),
+				0,
+			)
+			mustSave(t, repo, u)
+		}
+
+		// When: 두 번째 페이지 조회
+		page, total, err := repo.FindAll(ctx, 2, 2)
+
+		// Then: 전체 건수와 최신순 정렬
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if total != 5 {
+			t.Errorf("expected total 5, got %d", total)
+		}
+		if len(page) != 2 {
+			t.Fatalf("expected 2 users, got %d", len(page))
+		}
+		if page[0].Email().Value() != "user2@example.com" || page[1].Email().Value() != "user1@example.com" {
+			t.Errorf("expected user2, user1 (newest first), got %s, %s",
+				page[0].Email().Value(), page[1].Email().Value())
+		}
+
+		last, _, err := repo.FindAll(ctx, 10, 4)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(last) != 1 || last[0].Email().Value() != "user0@example.com" {
+			t.Errorf("expected only user0 on the last page, got %d users", len(last))
+		}
+	})
+
+	t.Run("삭제", func(t *testing.T) {
+		// Given: 저장된 사용자
+		ctx := t.Context()
+		repo := newRepo(t)
+		u := newUser(t, repo, "delete@example.com")
+		mustSave(t, repo, u)
+
+		// When: 삭제
+		err := repo.Delete(ctx, u.ID())
+
+		// Then: 조회되지 않고 이메일을 다시 쓸 수 있음
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if _, err := repo.FindByID(ctx, u.ID()); !errors.Is(err, user.ErrUserNotFound) {
+			t.Errorf("expected ErrUserNotFound, got %v", err)
+		}
+		if err := repo.Save(ctx, newUser(t, repo, "delete@example.com")); err != nil {
+			t.Errorf("expected email to be reusable, got %v", err)
+		}
+	})
+}
+
+func newUser(t *testing.T, repo user.Repository, email string) *user.User {
+	t.Helper()
+
+	u, err := user.NewUser(repo.NextID(t.Context()), user.MustNewEmail(email), user.NewPasswordFromHash("hash"))
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	return u
+}
+
+func mustSave(t *testing.T, repo user.Repository, u *user.User) {
+	t.Helper()
+
+	if err := repo.Save(t.Context(), u); err != nil {
+		t.Fatalf("failed to save user: %v", err)
+	}
+}
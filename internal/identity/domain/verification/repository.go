@@ -1,20 +1,31 @@
 package verification
 
 import (
+	"context"
+	"errors"
+
 	"github.com/junghwan16/test-server/internal/identity/domain/user"
 )
 
+var (
+	// ErrTokenNotFound is returned by FindByToken for unknown or expired tokens
+	ErrTokenNotFound = errors.New("token not found or expired")
+
+	// ErrDuplicateToken is returned by Save when the token is already stored
+	ErrDuplicateToken = errors.New("token already exists")
+)
+
 // EmailVerificationRepository defines the interface for email verification persistence
 type EmailVerificationRepository interface {
-	Save(verification *EmailVerification) error
-	FindByToken(token string) (*EmailVerification, error)
-	Delete(token string) error
+	Save(ctx context.Context, verification *EmailVerification) error
+	FindByToken(ctx context.Context, token string) (*EmailVerification, error)
+	Delete(ctx context.Context, token string) error
 }
 
 // PasswordResetRepository defines the interface for password reset persistence
 type PasswordResetRepository interface {
-	Save(reset *PasswordReset) error
-	FindByToken(token string) (*PasswordReset, error)
-	Delete(token string) error
-	DeleteByUserID(userID user.UserID) error
+	Save(ctx context.Context, reset *PasswordReset) error
+	FindByToken(ctx context.Context, token string) (*PasswordReset, error)
+	Delete(ctx context.Context, token string) error
+	DeleteByUserID(ctx context.Context, userID user.UserID) error
 }
@@ -0,0 +1,213 @@
+// Package verificationtest provides conformance test suites for the
+// verification token repositories.
+package verificationtest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/junghwan16/test-server/internal/identity/domain/user"
+	"github.com/junghwan16/test-server/internal/identity/domain/verification"
+)
+
+// TestEmailVerificationRepository runs the EmailVerificationRepository
+// conformance suite. newRepo is called once per subtest.
+func TestEmailVerificationRepository(t *testing.T, newRepo func(t *testing.T) verification.EmailVerificationRepository) {
+	t.Helper()
+
+	userID := user.GenerateUserID()
+
+	t.Run("저장 후 조회", func(t *testing.T) {
+		// Given: 저장된 토큰
+		ctx := t.Context()
+		repo := newRepo(t)
+		v := verification.NewEmailVerification(userID, time.Hour)
+		if err := repo.Save(ctx, v); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		// When: 토큰으로 조회
+		found, err := repo.FindByToken(ctx, v.Token())
+
+		// Then: 같은 사용자와 만료 시각
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !found.UserID().Equals(userID) {
+			t.Errorf("expected user %s, got %s", userID.Value(), found.UserID().Value())
+		}
+		if d := found.ExpiresAt().Sub(v.ExpiresAt()).Abs(); d > time.Millisecond {
+			t.Errorf("expected expiry to round-trip, off by %v", d)
+		}
+	})
+
+	t.Run("없는 토큰", func(t *testing.T) {
+		// When: 저장된 적 없는 토큰으로 조회
+		repo := newRepo(t)
+		_, err := repo.FindByToken(t.Context(), "missing")
+
+		// Then: ErrTokenNotFound
+		if !errors.Is(err, verification.ErrTokenNotFound) {
+			t.Errorf("expected ErrTokenNotFound, got %v", err)
+		}
+	})
+
+	t.Run("만료된 토큰", func(t *testing.T) {
+		// Given: 이미 만료된 토큰
+		ctx := t.Context()
+		repo := newRepo(t)
+		v := verification.NewEmailVerification(userID, -time.Minute)
+		if err := repo.Save(ctx, v); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		// When: 조회
+		_, err := repo.FindByToken(ctx, v.Token())
+
+		// Then: ErrTokenNotFound
+		if !errors.Is(err, verification.ErrTokenNotFound) {
+			t.Errorf("expected ErrTokenNotFound, got %v", err)
+		}
+	})
+
+	t.Run("중복 토큰", func(t *testing.T) {
+		// Given: 저장된 토큰
+		ctx := t.Context()
+		repo := newRepo(t)
+		v := verification.NewEmailVerification(userID, time.Hour)
+		repo.Save(ctx, v)
+
+		// When: 같은 토큰을 다시 저장
+		err := repo.Save(ctx, verification.ReconstructEmailVerification(v.Token(), userID, v.ExpiresAt(), v.CreatedAt()))
+
+		// Then: ErrDuplicateToken
+		if !errors.Is(err, verification.ErrDuplicateToken) {
+			t.Errorf("expected ErrDuplicateToken, got %v", err)
+		}
+	})
+
+	t.Run("삭제", func(t *testing.T) {
+		// Given: 저장된 토큰
+		ctx := t.Context()
+		repo := newRepo(t)
+		v := verification.NewEmailVerification(userID, time.Hour)
+		repo.Save(ctx, v)
+
+		// When: 삭제
+		err := repo.Delete(ctx, v.Token())
+
+		// Then: 더 이상 조회되지 않음
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if _, err := repo.FindByToken(ctx, v.Token()); !errors.Is(err, verification.ErrTokenNotFound) {
+			t.Errorf("expected ErrTokenNotFound, got %v", err)
+		}
+	})
+}
+
+// TestPasswordResetRepository runs the PasswordResetRepository conformance
+// suite. newRepo is called once per subtest.
+func TestPasswordResetRepository(t *testing.T, newRepo func(t *testing.T) verification.PasswordResetRepository) {
+	t.Helper()
+
+	alice := user.GenerateUserID()
+	bob := user.GenerateUserID()
+
+	t.Run("저장 후 조회", func(t *testing.T) {
+		// Given: 저장된 토큰
+		ctx := t.Context()
+		repo := newRepo(t)
+		p := verification.NewPasswordReset(alice, time.Hour)
+		if err := repo.Save(ctx, p); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		// When: 토큰으로 조회
+		found, err := repo.FindByToken(ctx, p.Token())
+
+		// Then: 같은 사용자
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !found.UserID().Equals(alice) {
+			t.Errorf("expected user %s, got %s", alice.Value(), found.UserID().Value())
+		}
+	})
+
+	t.Run("없는 토큰", func(t *testing.T) {
+		// When: 저장된 적 없는 토큰으로 조회
+		repo := newRepo(t)
+		_, err := repo.FindByToken(t.Context(), "missing")
+
+		// Then: ErrTokenNotFound
+		if !errors.Is(err, verification.ErrTokenNotFound) {
+			t.Errorf("expected ErrTokenNotFound, got %v", err)
+		}
+	})
+
+	t.Run("만료된 토큰", func(t *testing.T) {
+		// Given: 이미 만료된 토큰
+		ctx := t.Context()
+		repo := newRepo(t)
+		p := verification.NewPasswordReset(alice, -time.Minute)
+		if err := repo.Save(ctx, p); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		// When: 조회
+		_, err := repo.FindByToken(ctx, p.Token())
+
+		// Then: ErrTokenNotFound
+		if !errors.Is(err, verification.ErrTokenNotFound) {
+			t.Errorf("expected ErrTokenNotFound, got %v", err)
+		}
+	})
+
+	t.Run("중복 토큰", func(t *testing.T) {
+		// Given: 저장된 토큰
+		ctx := t.Context()
+		repo := newRepo(t)
+		p := verification.NewPasswordReset(alice, time.Hour)
+		repo.Save(ctx, p)
+
+		// When: 같은 토큰을 다시 저장
+		err := repo.Save(ctx, verification.ReconstructPasswordReset(p.Token(), alice, p.ExpiresAt(), p.CreatedAt()))
+
+		// Then: ErrDuplicateToken
+		if !errors.Is(err, verification.ErrDuplicateToken) {
+			t.Errorf("expected ErrDuplicateToken, got %v", err)
+		}
+	})
+
+	t.Run("사용자별 삭제", func(t *testing.T) {
+		// Given: 두 사용자의 토큰
+		ctx := t.Context()
+		repo := newRepo(t)
+		aliceFirst := verification.NewPasswordReset(alice, time.Hour)
+		aliceSecond := verification.NewPasswordReset(alice, time.Hour)
+		bobs := verification.NewPasswordReset(bob, time.Hour)
+		for _, p := range []*verification.PasswordReset{aliceFirst, aliceSecond, bobs} {
+			if err := repo.Save(ctx, p); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+
+		// When: alice 의 토큰 삭제
+		err := repo.DeleteByUserID(ctx, alice)
+
+		// Then: bob 의 토큰만 남음
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		for _, p := range []*verification.PasswordReset{aliceFirst, aliceSecond} {
+			if _, err := repo.FindByToken(ctx, p.Token()); !errors.Is(err, verification.ErrTokenNotFound) {
+				t.Errorf("expected alice's token to be deleted, got %v", err)
+			}
+		}
+		if _, err := repo.FindByToken(ctx, bobs.Token()); err != nil {
+			t.Errorf("expected bob's token to remain, got %v", err)
+		}
+	})
+}
@@ -2,25 +2,35 @@ package handler
 
 import (
 	"encoding/json"
-	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/junghwan16/test-server/internal/identity/application"
-	"github.com/junghwan16/test-server/internal/identity/domain/user"
+	"github.com/junghwan16/test-server/internal/shared/csrf"
+	"github.com/junghwan16/test-server/internal/shared/problem"
 )
 
+// CookieOptions are the attributes of the session and CSRF cookies
+type CookieOptions struct {
+	SameSite http.SameSite
+	Secure   bool
+}
+
 type AuthHandler struct {
 	userSvc    *application.UserService
 	authSvc    *application.AuthService
+	csrfTokens *csrf.Tokens
 	sessionTTL int
+	cookies    CookieOptions
 }
 
-func NewAuthHandler(userSvc *application.UserService, authSvc *application.AuthService, sessionTTL int) *AuthHandler {
+func NewAuthHandler(userSvc *application.UserService, authSvc *application.AuthService, csrfTokens *csrf.Tokens, sessionTTL int, cookies CookieOptions) *AuthHandler {
 	return &AuthHandler{
 		userSvc:    userSvc,
 		authSvc:    authSvc,
+		csrfTokens: csrfTokens,
 		sessionTTL: sessionTTL,
+		cookies:    cookies,
 	}
 }
 
@@ -31,31 +41,20 @@ func (h *AuthHandler) Signup(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request", http.StatusBadRequest)
+		problem.Write(w, r, problem.MalformedRequest)
 		return
 	}
 
 	req.Email = strings.TrimSpace(strings.ToLower(req.Email))
 
 	// Use IDDD UserService
-	u, err := h.userSvc.RegisterUser(req.Email, req.Password)
+	u, err := h.userSvc.RegisterUser(r.Context(), req.Email, req.Password)
 	if err != nil {
-		if errors.Is(err, user.ErrInvalidEmail) {
-			http.Error(w, "Invalid email", http.StatusBadRequest)
-		} else if errors.Is(err, user.ErrPasswordTooShort) {
-			http.Error(w, "Password must be at least 8 characters", http.StatusBadRequest)
-		} else {
-			http.Error(w, "Email already registered", http.StatusConflict)
-		}
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]any{
-		"message": "User created successfully",
-		"user":    userToDTO(u),
-	})
+	writeData(w, http.StatusCreated, newUserResponse(u))
 }
 
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
@@ -65,62 +64,74 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request", http.StatusBadRequest)
+		problem.Write(w, r, problem.MalformedRequest)
 		return
 	}
 
 	req.Email = strings.TrimSpace(strings.ToLower(req.Email))
 
 	// Use IDDD AuthService
-	session, u, err := h.authSvc.Login(req.Email, req.Password)
+	session, u, err := h.authSvc.Login(r.Context(), req.Email, req.Password)
 	if err != nil {
-		http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+		writeError(w, r, err)
 		return
 	}
 
 	// Set session cookie
-	http.SetCookie(w, &http.Cookie{
-		Name:     "session",
-		Value:    session.ID().Value(),
-		Path:     "/",
-		HttpOnly: true,
-		SameSite: http.SameSiteStrictMode,
-		MaxAge:   h.sessionTTL,
-	})
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]any{
-		"user": userToDTO(u),
-	})
+	sessionCookie := h.cookie("session", session.ID().Value(), h.sessionTTL)
+	sessionCookie.HttpOnly = true
+	http.SetCookie(w, sessionCookie)
+	h.setCSRFToken(w, session.ID().Value())
+
+	writeData(w, http.StatusOK, newUserResponse(u))
 }
 
 func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 	cookie, err := r.Cookie("session")
 	if err == nil {
-		_ = h.authSvc.Logout(cookie.Value)
+		_ = h.authSvc.Logout(r.Context(), cookie.Value)
 	}
 
-	http.SetCookie(w, &http.Cookie{
-		Name:     "session",
-		Value:    "",
-		Path:     "/",
-		MaxAge:   -1,
-		HttpOnly: true,
-	})
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"message": "Logged out",
-	})
+	sessionCookie := h.cookie("session", "", -1)
+	sessionCookie.HttpOnly = true
+	http.SetCookie(w, sessionCookie)
+	http.SetCookie(w, h.cookie(csrf.CookieName, "", -1))
+
+	writeData(w, http.StatusOK, MessageResponse{Message: "Logged out"})
 }
 
 func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
 	u := GetUserFromContext(r.Context())
 	if u == nil {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		problem.Write(w, r, problem.Unauthenticated)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(userToDTO(u))
+	// Let apps on another origin, which cannot read the cookie, recover the token
+	if cookie, err := r.Cookie("session"); err == nil {
+		w.Header().Set(csrf.Header, h.csrfTokens.Issue(cookie.Value))
+	}
+
+	writeData(w, http.StatusOK, newUserResponse(u))
+}
+
+// setCSRFToken hands out the session's CSRF token in a header and in a
+// cookie scripts on the same origin can read
+func (h *AuthHandler) setCSRFToken(w http.ResponseWriter, sessionID string) {
+	token := h.csrfTokens.Issue(sessionID)
+	w.Header().Set(csrf.Header, token)
+	http.SetCookie(w, h.cookie(csrf.CookieName, token, h.sessionTTL))
+}
+
+// cookie builds a cookie with the configured SameSite and Secure attributes,
+// which deletions must repeat for browsers to replace the original
+func (h *AuthHandler) cookie(name, value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   maxAge,
+		SameSite: h.cookies.SameSite,
+		Secure:   h.cookies.Secure,
+	}
 }
@@ -1,16 +1,77 @@
 package handler
 
-import "github.com/junghwan16/test-server/internal/identity/domain/user"
-
-// userToDTO converts User aggregate to DTO
-func userToDTO(u *user.User) map[string]any {
-	return map[string]any{
-		"id":             u.ID().Value(),
-		"email":          u.Email().Value(),
-		"role":           u.Role().Value(),
-		"email_verified": u.EmailVerified(),
-		"active":         u.Active(),
-		"created_at":     u.CreatedAt(),
-		"updated_at":     u.UpdatedAt(),
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/junghwan16/test-server/internal/identity/domain/user"
+)
+
+// Envelope wraps every successful JSON response: the resource goes in Data,
+// and list responses describe the page in Meta. Errors are problem details.
+type Envelope[T any] struct {
+	Data T         `json:"data"`
+	Meta *PageMeta `json:"meta,omitempty"`
+}
+
+// PageMeta describes the page returned by a list endpoint
+type PageMeta struct {
+	Total  int64 `json:"total"`
+	Limit  int   `json:"limit"`
+	Offset int   `json:"offset"`
+}
+
+// UserResponse is the public representation of a user
+type UserResponse struct {
+	ID            string    `json:"id"`
+	Email         string    `json:"email"`
+	Role          string    `json:"role"`
+	EmailVerified bool      `json:"email_verified"`
+	Active        bool      `json:"active"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	Version       int64     `json:"version"`
+}
+
+// MessageResponse acknowledges an action that returns no resource
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+// TokenResponse acknowledges a token request. Token is returned only until
+// tokens are delivered by email alone.
+type TokenResponse struct {
+	Message string `json:"message"`
+	Token   string `json:"token,omitempty"`
+}
+
+// newUserResponse converts the User aggregate to its response form
+func newUserResponse(u *user.User) UserResponse {
+	return UserResponse{
+		ID:            u.ID().Value(),
+		Email:         u.Email().Value(),
+		Role:          u.Role().Value(),
+		EmailVerified: u.EmailVerified(),
+		Active:        u.Active(),
+		CreatedAt:     u.CreatedAt(),
+		UpdatedAt:     u.UpdatedAt(),
+		Version:       u.Version(),
 	}
 }
+
+// writeData sends data in the response envelope
+func writeData[T any](w http.ResponseWriter, status int, data T) {
+	writeEnvelope(w, status, Envelope[T]{Data: data})
+}
+
+// writePage sends a page of items in the response envelope
+func writePage[T any](w http.ResponseWriter, items []T, meta PageMeta) {
+	writeEnvelope(w, http.StatusOK, Envelope[[]T]{Data: items, Meta: &meta})
+}
+
+func writeEnvelope[T any](w http.ResponseWriter, status int, env Envelope[T]) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(env)
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/junghwan16/test-server/internal/identity/application"
+	"github.com/junghwan16/test-server/internal/identity/domain/user"
+	"github.com/junghwan16/test-server/internal/shared/problem"
+)
+
+// Problems returned by the identity endpoints. Codes are part of the API and
+// must not change once published.
+var (
+	ProblemInvalidUserID      = problem.New(http.StatusBadRequest, "invalid_user_id", "Invalid user ID")
+	ProblemInvalidCredentials = problem.New(http.StatusUnauthorized, "invalid_credentials", "Invalid credentials")
+	ProblemUserNotFound       = problem.New(http.StatusNotFound, "user_not_found", "User not found")
+	ProblemEmailRegistered    = problem.New(http.StatusConflict, "email_already_registered", "Email already registered")
+	ProblemEmailVerified      = problem.New(http.StatusBadRequest, "email_already_verified", "Email already verified")
+	ProblemCannotDeleteSelf   = problem.New(http.StatusBadRequest, "cannot_delete_self", "Cannot delete yourself")
+	ProblemInvalidToken       = problem.New(http.StatusBadRequest, "invalid_token", "Invalid or expired token")
+	ProblemPrecondition       = problem.New(http.StatusPreconditionFailed, "precondition_failed", "User was modified concurrently")
+	ProblemInvalidIfMatch     = problem.New(http.StatusPreconditionFailed, "invalid_if_match", "Invalid If-Match header")
+	ProblemAccountDeactivated = problem.New(http.StatusForbidden, "account_deactivated", "Account deactivated")
+)
+
+// errorProblems maps application and domain errors to their problem, first match wins
+var errorProblems = []struct {
+	err     error
+	problem *problem.Problem
+}{
+	{application.ErrEmailAlreadyRegistered, ProblemEmailRegistered},
+	{application.ErrInvalidCredentials, ProblemInvalidCredentials},
+	{application.ErrInvalidSession, problem.Unauthenticated},
+	{application.ErrUserNotFound, ProblemUserNotFound},
+	{user.ErrUserNotFound, ProblemUserNotFound},
+	{application.ErrCannotDeleteSelf, ProblemCannotDeleteSelf},
+	{application.ErrInvalidToken, ProblemInvalidToken},
+	{application.ErrAlreadyVerified, ProblemEmailVerified},
+	{user.ErrConcurrentModification, ProblemPrecondition},
+	{user.ErrInvalidUserID, ProblemInvalidUserID},
+}
+
+// fieldCodes gives each domain validation error a stable code
+var fieldCodes = []struct {
+	err  error
+	code string
+}{
+	{user.ErrInvalidEmail, "invalid_email"},
+	{user.ErrPasswordTooShort, "password_too_short"},
+	{user.ErrInvalidPassword, "invalid_password"},
+	{user.ErrInvalidRole, "invalid_role"},
+}
+
+// writeError renders err as problem details. Unknown errors are logged and
+// reported as a generic 500 so internals never reach the client.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	var ve *application.ValidationError
+	if errors.As(err, &ve) {
+		problem.Write(w, r, problem.Validation.WithErrors(fieldErrors(ve)...))
+		return
+	}
+
+	for _, m := range errorProblems {
+		if errors.Is(err, m.err) {
+			problem.Write(w, r, m.problem)
+			return
+		}
+	}
+
+	slog.ErrorContext(r.Context(), "request failed", "method", r.Method, "path", r.URL.Path, "error", err)
+	problem.Write(w, r, problem.Internal)
+}
+
+// fieldErrors converts validation failures to their wire form
+func fieldErrors(ve *application.ValidationError) []problem.FieldError {
+	out := make([]problem.FieldError, len(ve.Fields))
+	for i, f := range ve.Fields {
+		code := "invalid"
+		for _, c := range fieldCodes {
+			if errors.Is(f.Err, c.err) {
+				code = c.code
+				break
+			}
+		}
+		out[i] = problem.FieldError{Field: f.Field, Code: code, Detail: f.Err.Error()}
+	}
+	return out
+}
+
+// missingField reports a required field that was left empty
+func missingField(field string) problem.FieldError {
+	return problem.FieldError{Field: field, Code: "required", Detail: field + " is required"}
+}
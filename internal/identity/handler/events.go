@@ -0,0 +1,294 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/junghwan16/test-server/internal/shared/domain"
+	"github.com/junghwan16/test-server/internal/shared/problem"
+	"github.com/junghwan16/test-server/internal/shared/requestid"
+)
+
+const subscriberBufferSize = 64
+
+// eventID orders stream events as "<ms>-<seq>". With a shared event bus it is
+// the Redis Stream entry ID, identical on every replica; otherwise ms is the
+// process start time, so IDs issued by another process are recognisable.
+type eventID struct {
+	ms, seq uint64
+}
+
+func parseEventID(s string) (eventID, bool) {
+	msPart, seqPart, ok := strings.Cut(s, "-")
+	if !ok {
+		return eventID{}, false
+	}
+	ms, err := strconv.ParseUint(msPart, 10, 64)
+	if err != nil {
+		return eventID{}, false
+	}
+	seq, err := strconv.ParseUint(seqPart, 10, 64)
+	if err != nil {
+		return eventID{}, false
+	}
+	return eventID{ms: ms, seq: seq}, true
+}
+
+func (id eventID) String() string {
+	return strconv.FormatUint(id.ms, 10) + "-" + strconv.FormatUint(id.seq, 10)
+}
+
+func (id eventID) after(other eventID) bool {
+	return id.ms > other.ms || id.ms == other.ms && id.seq > other.seq
+}
+
+type streamEvent struct {
+	id        eventID
+	eventType string
+	data      []byte
+}
+
+type streamSubscriber struct {
+	events chan streamEvent
+	types  map[string]bool // nil accepts every type
+}
+
+func (s *streamSubscriber) accepts(eventType string) bool {
+	return s.types == nil || s.types[eventType]
+}
+
+// EventsHandler streams identity domain events to admin dashboards as
+// Server-Sent Events
+type EventsHandler struct {
+	heartbeat time.Duration
+	shared    bool
+	epoch     uint64
+
+	mu      sync.Mutex
+	nextSeq uint64
+	buffer  []streamEvent // ring buffer of recent events for Last-Event-ID replay
+	head    int
+	// horizon is the newest event this handler can no longer replay: evicted
+	// from the buffer or, with a shared bus, the first entry it saw, as
+	// earlier ones were never read. Only clients resuming at or after it can
+	// be replayed without a gap. With a shared bus it is unknown until the
+	// first event arrives.
+	horizon      eventID
+	horizonKnown bool
+	subscribers  map[*streamSubscriber]struct{}
+	done         chan struct{}
+	closeOnce    sync.Once
+}
+
+// NewEventsHandler creates an EventsHandler keeping up to replaySize events for resume.
+// Set shared when events arrive from a Redis Stream: its entry IDs become the
+// event IDs, so clients can resume on any replica. Otherwise resume only works
+// against the process that issued the ID.
+func NewEventsHandler(replaySize int, heartbeat time.Duration, shared bool) *EventsHandler {
+	epoch := uint64(time.Now().UnixMilli())
+	return &EventsHandler{
+		heartbeat:    heartbeat,
+		shared:       shared,
+		epoch:        epoch,
+		buffer:       make([]streamEvent, 0, replaySize),
+		horizon:      eventID{ms: epoch},
+		horizonKnown: !shared,
+		subscribers:  make(map[*streamSubscriber]struct{}),
+		done:         make(chan struct{}),
+	}
+}
+
+// HandleEvent records a domain event and fans it out to connected clients.
+// It never blocks: subscribers that fall behind are disconnected and can resume.
+func (h *EventsHandler) HandleEvent(ctx context.Context, event domain.DomainEvent) error {
+	msg := map[string]any{
+		"type":        event.EventType(),
+		"occurred_at": event.OccurredAt(),
+		"data":        event,
+	}
+	if id := requestid.FromContext(ctx); id != "" {
+		msg["request_id"] = id
+	}
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var id eventID
+	if h.shared {
+		var ok bool
+		if id, ok = parseEventID(domain.EventIDFromContext(ctx)); !ok {
+			return fmt.Errorf("event %s arrived without a stream entry ID", event.EventType())
+		}
+	} else {
+		h.nextSeq++
+		id = eventID{ms: h.epoch, seq: h.nextSeq}
+	}
+	ev := streamEvent{id: id, eventType: event.EventType(), data: payload}
+
+	if !h.horizonKnown {
+		h.horizon, h.horizonKnown = id, true
+	}
+	switch {
+	case cap(h.buffer) == 0:
+		h.horizon = id
+	case len(h.buffer) < cap(h.buffer):
+		h.buffer = append(h.buffer, ev)
+	default:
+		h.horizon = h.buffer[h.head].id
+		h.buffer[h.head] = ev
+		h.head = (h.head + 1) % cap(h.buffer)
+	}
+
+	for sub := range h.subscribers {
+		if !sub.accepts(ev.eventType) {
+			continue
+		}
+		select {
+		case sub.events <- ev:
+		default:
+			delete(h.subscribers, sub)
+			close(sub.events)
+		}
+	}
+
+	return nil
+}
+
+// Close disconnects all clients; register it with http.Server.RegisterOnShutdown
+func (h *EventsHandler) Close() {
+	h.closeOnce.Do(func() { close(h.done) })
+}
+
+// Stream serves the event stream (admin only).
+// Filter with ?type=a&type=b or ?type=a,b; resume with the Last-Event-ID header.
+// An ID this handler cannot replay from without a gap starts the stream afresh
+// with a reset event: one issued by another process without a shared bus, one
+// the replay buffer has wrapped past, or one from before this replica started
+// reading a shared bus.
+func (h *EventsHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+
+	var types map[string]bool
+	for _, v := range r.URL.Query()["type"] {
+		for _, t := range strings.Split(v, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				if types == nil {
+					types = make(map[string]bool)
+				}
+				types[t] = true
+			}
+		}
+	}
+
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("last_event_id")
+	}
+	var after eventID
+	if lastID != "" {
+		var ok bool
+		if after, ok = parseEventID(lastID); !ok {
+			problem.Write(w, r, problem.Validation.WithErrors(problem.FieldError{
+				Field: "Last-Event-ID", Code: "invalid", Detail: "Last-Event-ID must be an event ID from this stream",
+			}))
+			return
+		}
+	}
+
+	sub := &streamSubscriber{
+		events: make(chan streamEvent, subscriberBufferSize),
+		types:  types,
+	}
+	replay, reset := h.subscribe(sub, lastID != "", after)
+	defer h.unsubscribe(sub)
+
+	// Streams outlive any server write timeout
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", (3 * time.Second).Milliseconds())
+	if reset {
+		fmt.Fprint(w, "event: reset\ndata: {}\n\n")
+	}
+	for _, ev := range replay {
+		writeStreamEvent(w, ev)
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-h.done:
+			return
+		case <-ticker.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+		case ev, ok := <-sub.events:
+			if !ok {
+				return
+			}
+			writeStreamEvent(w, ev)
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// subscribe registers the subscriber and returns buffered events after the
+// given ID, or reset if events after it may have been missed
+func (h *EventsHandler) subscribe(sub *streamSubscriber, resume bool, after eventID) (replay []streamEvent, reset bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.subscribers[sub] = struct{}{}
+
+	if !resume {
+		return nil, false
+	}
+	if !h.shared && after.ms != h.epoch || !h.horizonKnown || h.horizon.after(after) {
+		return nil, true
+	}
+
+	for i := range h.buffer {
+		ev := h.buffer[(h.head+i)%len(h.buffer)]
+		if ev.id.after(after) && sub.accepts(ev.eventType) {
+			replay = append(replay, ev)
+		}
+	}
+	return replay, false
+}
+
+func (h *EventsHandler) unsubscribe(sub *streamSubscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.subscribers[sub]; ok {
+		delete(h.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+func writeStreamEvent(w http.ResponseWriter, ev streamEvent) {
+	fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", ev.id, ev.eventType, ev.data)
+}
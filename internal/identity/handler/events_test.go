@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/junghwan16/test-server/internal/shared/domain"
+)
+
+type pingEvent struct {
+	domain.BaseEvent
+}
+
+func (pingEvent) EventType() string { return "test.ping" }
+
+// streamAfter returns what a client resuming after lastID receives before any new event
+func streamAfter(h *EventsHandler, lastID string) string {
+	h.Close()
+	r := httptest.NewRequest(http.MethodGet, "/v1/admin/events/stream", nil)
+	r.Header.Set("Last-Event-ID", lastID)
+	w := httptest.NewRecorder()
+	h.Stream(w, r)
+	return w.Body.String()
+}
+
+func TestEventsHandler_Resume(t *testing.T) {
+	t.Run("같은 프로세스가 발급한 ID 이후부터 재전송", func(t *testing.T) {
+		// Given: 공유 버스 없이 두 이벤트를 받은 핸들러
+		h := NewEventsHandler(10, time.Minute, false)
+		h.HandleEvent(context.Background(), pingEvent{domain.NewBaseEvent()})
+		h.HandleEvent(context.Background(), pingEvent{domain.NewBaseEvent()})
+		first := h.buffer[0].id.String()
+
+		// When: 첫 이벤트 ID 로 재개
+		body := streamAfter(h, first)
+
+		// Then: 두 번째 이벤트만 재전송
+		if strings.Count(body, "event: test.ping") != 1 || !strings.Contains(body, "id: "+h.buffer[1].id.String()) {
+			t.Errorf("expected only the second event, got %q", body)
+		}
+	})
+
+	t.Run("다른 프로세스의 ID 는 reset 으로 새로 시작", func(t *testing.T) {
+		// Given: 공유 버스 없이 이벤트를 받은 핸들러
+		h := NewEventsHandler(10, time.Minute, false)
+		h.HandleEvent(context.Background(), pingEvent{domain.NewBaseEvent()})
+
+		// When: 다른 프로세스가 발급한 ID 로 재개
+		body := streamAfter(h, "1-0")
+
+		// Then: 재전송 없이 reset 이벤트
+		if !strings.Contains(body, "event: reset") || strings.Contains(body, "event: test.ping") {
+			t.Errorf("expected a reset without replay, got %q", body)
+		}
+	})
+
+	t.Run("공유 버스에서는 스트림 엔트리 ID 사용", func(t *testing.T) {
+		// Given: 다른 레플리카에서도 같은 엔트리 ID 로 이벤트를 받은 핸들러
+		h := NewEventsHandler(10, time.Minute, true)
+		for _, id := range []string{"1700000000000-0", "1700000000000-1"} {
+			ctx := domain.WithEventID(context.Background(), id)
+			if err := h.HandleEvent(ctx, pingEvent{domain.NewBaseEvent()}); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+
+		// When: 다른 레플리카가 보낸 엔트리 ID 로 재개
+		body := streamAfter(h, "1700000000000-0")
+
+		// Then: 그 이후 엔트리만 같은 ID 로 재전송
+		if strings.Count(body, "event: test.ping") != 1 || !strings.Contains(body, "id: 1700000000000-1\n") {
+			t.Errorf("expected only entry 1700000000000-1, got %q", body)
+		}
+	})
+
+	t.Run("버퍼가 한 바퀴 돈 뒤의 오래된 ID 는 reset", func(t *testing.T) {
+		// Given: 두 이벤트만 보관하는 핸들러가 네 이벤트를 받아 앞의 둘을 밀어냄
+		h := NewEventsHandler(2, time.Minute, false)
+		for range 4 {
+			h.HandleEvent(context.Background(), pingEvent{domain.NewBaseEvent()})
+		}
+
+		// When: 밀려난 첫 이벤트 ID 로 재개
+		body := streamAfter(h, eventID{ms: h.epoch, seq: 1}.String())
+
+		// Then: 두 번째 이벤트를 잃었으므로 재전송 없이 reset
+		if !strings.Contains(body, "event: reset") || strings.Contains(body, "event: test.ping") {
+			t.Errorf("expected a reset without replay, got %q", body)
+		}
+	})
+
+	t.Run("밀려난 마지막 이벤트 ID 는 빈틈 없이 재전송", func(t *testing.T) {
+		// Given: 두 이벤트만 보관하는 핸들러가 세 이벤트를 받음
+		h := NewEventsHandler(2, time.Minute, false)
+		for range 3 {
+			h.HandleEvent(context.Background(), pingEvent{domain.NewBaseEvent()})
+		}
+
+		// When: 밀려난 첫 이벤트 ID 로 재개
+		body := streamAfter(h, eventID{ms: h.epoch, seq: 1}.String())
+
+		// Then: 남은 두 이벤트 재전송
+		if strings.Contains(body, "event: reset") || strings.Count(body, "event: test.ping") != 2 {
+			t.Errorf("expected both buffered events, got %q", body)
+		}
+	})
+
+	t.Run("공유 버스에서 읽기 시작 전의 ID 는 reset", func(t *testing.T) {
+		// Given: 늦게 시작해 1700000000000-5 부터 읽은 레플리카
+		h := NewEventsHandler(10, time.Minute, true)
+		for _, id := range []string{"1700000000000-5", "1700000000000-6"} {
+			h.HandleEvent(domain.WithEventID(context.Background(), id), pingEvent{domain.NewBaseEvent()})
+		}
+
+		// When: 다른 레플리카에서 받은 그 이전 엔트리 ID 로 재개
+		body := streamAfter(h, "1700000000000-1")
+
+		// Then: 사이의 엔트리를 알 수 없으므로 reset
+		if !strings.Contains(body, "event: reset") || strings.Contains(body, "event: test.ping") {
+			t.Errorf("expected a reset without replay, got %q", body)
+		}
+	})
+
+	t.Run("공유 버스에서 아직 이벤트를 못 받은 레플리카는 reset", func(t *testing.T) {
+		// Given: 재시작 후 이벤트를 받지 못한 레플리카
+		h := NewEventsHandler(10, time.Minute, true)
+
+		// When: 재시작 전에 받은 ID 로 재개
+		body := streamAfter(h, "1700000000000-1")
+
+		// Then: reset
+		if !strings.Contains(body, "event: reset") {
+			t.Errorf("expected a reset, got %q", body)
+		}
+	})
+}
@@ -2,12 +2,13 @@ package handler
 
 import (
 	"encoding/json"
-	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/junghwan16/test-server/internal/identity/application"
 	"github.com/junghwan16/test-server/internal/identity/domain/user"
+	"github.com/junghwan16/test-server/internal/shared/problem"
 )
 
 type UsersHandler struct {
@@ -25,54 +26,49 @@ func (h *UsersHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
 	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
 	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
 
-	users, total, err := h.userSvc.ListUsers(limit, offset)
+	page, err := h.userSvc.ListUsers(r.Context(), limit, offset)
 	if err != nil {
-		http.Error(w, "Failed to list users", http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	// Convert to DTOs
-	userDTOs := make([]map[string]any, len(users))
-	for i, u := range users {
-		userDTOs[i] = userToDTO(u)
+	users := make([]UserResponse, len(page.Users))
+	for i, u := range page.Users {
+		users[i] = newUserResponse(u)
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]any{
-		"users":  userDTOs,
-		"total":  total,
-		"limit":  limit,
-		"offset": offset,
-	})
+	writePage(w, users, PageMeta{Total: page.Total, Limit: page.Limit, Offset: page.Offset})
 }
 
 // GetUser returns a single user (admin only)
 func (h *UsersHandler) GetUser(w http.ResponseWriter, r *http.Request) {
-	id, err := strconv.ParseUint(r.PathValue("id"), 10, 32)
-	if err != nil {
-		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+	id, ok := pathUserID(r)
+	if !ok {
+		problem.Write(w, r, ProblemInvalidUserID)
 		return
 	}
 
-	u, err := h.userSvc.GetUser(uint(id))
+	u, err := h.userSvc.GetUser(r.Context(), id)
 	if err != nil {
-		if errors.Is(err, application.ErrUserNotFound) {
-			http.Error(w, "User not found", http.StatusNotFound)
-		} else {
-			http.Error(w, "Failed to get user", http.StatusInternalServerError)
-		}
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(userToDTO(u))
+	w.Header().Set("ETag", userETag(u))
+	writeData(w, http.StatusOK, newUserResponse(u))
 }
 
-// UpdateUser updates a user (admin only)
+// UpdateUser updates a user (admin only).
+// Send the ETag from GetUser as If-Match to avoid overwriting concurrent changes.
 func (h *UsersHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
-	id, err := strconv.ParseUint(r.PathValue("id"), 10, 32)
-	if err != nil {
-		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+	id, ok := pathUserID(r)
+	if !ok {
+		problem.Write(w, r, ProblemInvalidUserID)
+		return
+	}
+
+	expectedVersion, ok := parseIfMatch(r.Header.Get("If-Match"))
+	if !ok {
+		problem.Write(w, r, ProblemInvalidIfMatch)
 		return
 	}
 
@@ -83,75 +79,75 @@ func (h *UsersHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request", http.StatusBadRequest)
+		problem.Write(w, r, problem.MalformedRequest)
 		return
 	}
 
-	// Update role if provided
-	if req.Role != nil {
-		if err := h.userSvc.ChangeRole(uint(id), *req.Role); err != nil {
-			if errors.Is(err, user.ErrInvalidRole) {
-				http.Error(w, "Invalid role", http.StatusBadRequest)
-			} else {
-				http.Error(w, "Failed to update role", http.StatusInternalServerError)
-			}
-			return
-		}
-	}
-
-	// Update active status if provided
-	if req.Active != nil {
-		if err := h.userSvc.SetActive(uint(id), *req.Active); err != nil {
-			http.Error(w, "Failed to update active status", http.StatusInternalServerError)
-			return
-		}
-	}
-
-	// Update email verified if provided
-	if req.EmailVerified != nil && *req.EmailVerified {
-		if err := h.userSvc.VerifyEmail(uint(id)); err != nil {
-			http.Error(w, "Failed to verify email", http.StatusInternalServerError)
-			return
-		}
-	}
-
-	// Get updated user
-	u, err := h.userSvc.GetUser(uint(id))
+	u, err := h.userSvc.UpdateUser(r.Context(), id, application.UserChanges{
+		Role:          req.Role,
+		Active:        req.Active,
+		EmailVerified: req.EmailVerified,
+	}, expectedVersion)
 	if err != nil {
-		http.Error(w, "Failed to get updated user", http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]any{
-		"user": userToDTO(u),
-	})
+	w.Header().Set("ETag", userETag(u))
+	writeData(w, http.StatusOK, newUserResponse(u))
 }
 
 func (h *UsersHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
-	id, err := strconv.ParseUint(r.PathValue("id"), 10, 32)
-	if err != nil {
-		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+	id, ok := pathUserID(r)
+	if !ok {
+		problem.Write(w, r, ProblemInvalidUserID)
 		return
 	}
 
 	currentUser := GetUserFromContext(r.Context())
 	if currentUser == nil {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		problem.Write(w, r, problem.Unauthenticated)
 		return
 	}
 
-	if err := h.userSvc.DeleteUser(uint(id), currentUser.ID().Value()); err != nil {
-		if errors.Is(err, application.ErrCannotDeleteSelf) {
-			http.Error(w, "Cannot delete yourself", http.StatusBadRequest)
-		} else {
-			http.Error(w, "Failed to delete user", http.StatusInternalServerError)
-		}
+	if err := h.userSvc.DeleteUser(r.Context(), id, currentUser.ID().Value()); err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"message": "User deleted",
-	})
+	writeData(w, http.StatusOK, MessageResponse{Message: "User deleted"})
+}
+
+// pathUserID reads the {id} path value, rejecting anything that is not a user ID
+func pathUserID(r *http.Request) (string, bool) {
+	id, err := user.NewUserID(r.PathValue("id"))
+	if err != nil {
+		return "", false
+	}
+	return id.Value(), true
+}
+
+// userETag returns the strong entity tag for the user's version
+func userETag(u *user.User) string {
+	return `"` + strconv.FormatInt(u.Version(), 10) + `"`
+}
+
+// parseIfMatch extracts the expected version from an If-Match header.
+// An empty header or "*" yields 0, meaning no precondition.
+func parseIfMatch(header string) (int64, bool) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return 0, true
+	}
+
+	// Weak tags never match under If-Match (RFC 9110 strong comparison)
+	if strings.HasPrefix(header, "W/") || len(header) < 2 || header[0] != '"' || header[len(header)-1] != '"' {
+		return 0, false
+	}
+
+	version, err := strconv.ParseInt(header[1:len(header)-1], 10, 64)
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+	return version, true
 }
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/junghwan16/test-server/internal/identity/application"
+	"github.com/junghwan16/test-server/internal/shared/problem"
 )
 
 type VerificationHandler struct {
@@ -21,49 +22,39 @@ func NewVerificationHandler(verifSvc *application.VerificationService) *Verifica
 func (h *VerificationHandler) RequestVerification(w http.ResponseWriter, r *http.Request) {
 	user := GetUserFromContext(r.Context())
 	if user == nil {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		problem.Write(w, r, problem.Unauthenticated)
 		return
 	}
 
 	if user.EmailVerified() {
-		http.Error(w, "Email already verified", http.StatusBadRequest)
+		problem.Write(w, r, ProblemEmailVerified)
 		return
 	}
 
-	token, err := h.verifSvc.RequestEmailVerification(user.ID().Value())
+	token, err := h.verifSvc.RequestEmailVerification(r.Context(), user.ID().Value())
 	if err != nil {
-		http.Error(w, "Failed to create verification token", http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"message": "Verification email sent",
-		"token":   token, // In production, don't return token - only send via email
-	})
+	// In production, don't return token - only send via email
+	writeData(w, http.StatusOK, TokenResponse{Message: "Verification email sent", Token: token})
 }
 
 // VerifyEmail verifies an email using a token
 func (h *VerificationHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
 	token := r.URL.Query().Get("token")
 	if token == "" {
-		http.Error(w, "Token required", http.StatusBadRequest)
+		problem.Write(w, r, problem.Validation.WithErrors(missingField("token")))
 		return
 	}
 
-	if err := h.verifSvc.VerifyEmail(token); err != nil {
-		if err == application.ErrInvalidToken {
-			http.Error(w, "Invalid or expired token", http.StatusBadRequest)
-		} else {
-			http.Error(w, "Failed to verify email", http.StatusInternalServerError)
-		}
+	if err := h.verifSvc.VerifyEmail(r.Context(), token); err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"message": "Email verified successfully",
-	})
+	writeData(w, http.StatusOK, MessageResponse{Message: "Email verified successfully"})
 }
 
 // RequestPasswordReset requests a password reset
@@ -73,21 +64,18 @@ func (h *VerificationHandler) RequestPasswordReset(w http.ResponseWriter, r *htt
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request", http.StatusBadRequest)
+		problem.Write(w, r, problem.MalformedRequest)
 		return
 	}
 
-	token, err := h.verifSvc.RequestPasswordReset(req.Email)
+	token, err := h.verifSvc.RequestPasswordReset(r.Context(), req.Email)
 	if err != nil {
-		http.Error(w, "Failed to request password reset", http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"message": "Password reset email sent if account exists",
-		"token":   token, // In production, don't return token - only send via email
-	})
+	// In production, don't return token - only send via email
+	writeData(w, http.StatusOK, TokenResponse{Message: "Password reset email sent if account exists", Token: token})
 }
 
 // ResetPassword resets a password using a token
@@ -98,26 +86,26 @@ func (h *VerificationHandler) ResetPassword(w http.ResponseWriter, r *http.Reque
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request", http.StatusBadRequest)
+		problem.Write(w, r, problem.MalformedRequest)
 		return
 	}
 
-	if req.Token == "" || req.NewPassword == "" {
-		http.Error(w, "Token and new password required", http.StatusBadRequest)
+	var missing []problem.FieldError
+	if req.Token == "" {
+		missing = append(missing, missingField("token"))
+	}
+	if req.NewPassword == "" {
+		missing = append(missing, missingField("new_password"))
+	}
+	if len(missing) > 0 {
+		problem.Write(w, r, problem.Validation.WithErrors(missing...))
 		return
 	}
 
-	if err := h.verifSvc.ResetPassword(req.Token, req.NewPassword); err != nil {
-		if err == application.ErrInvalidToken {
-			http.Error(w, "Invalid or expired token", http.StatusBadRequest)
-		} else {
-			http.Error(w, "Failed to reset password", http.StatusInternalServerError)
-		}
+	if err := h.verifSvc.ResetPassword(r.Context(), req.Token, req.NewPassword); err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"message": "Password reset successfully",
-	})
+	writeData(w, http.StatusOK, MessageResponse{Message: "Password reset successfully"})
 }
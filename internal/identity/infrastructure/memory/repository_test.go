@@ -0,0 +1,37 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/junghwan16/test-server/internal/identity/domain/session"
+	"github.com/junghwan16/test-server/internal/identity/domain/session/sessiontest"
+	"github.com/junghwan16/test-server/internal/identity/domain/user"
+	"github.com/junghwan16/test-server/internal/identity/domain/user/usertest"
+	"github.com/junghwan16/test-server/internal/identity/domain/verification"
+	"github.com/junghwan16/test-server/internal/identity/domain/verification/verificationtest"
+	"github.com/junghwan16/test-server/internal/shared/domain"
+)
+
+func TestUserRepository(t *testing.T) {
+	usertest.TestRepository(t, func(t *testing.T) user.Repository {
+		return NewUserRepository(NewStore(), domain.NewSimpleEventBus())
+	})
+}
+
+func TestSessionRepository(t *testing.T) {
+	sessiontest.TestRepository(t, func(t *testing.T) session.Repository {
+		return NewSessionRepository()
+	})
+}
+
+func TestEmailVerificationRepository(t *testing.T) {
+	verificationtest.TestEmailVerificationRepository(t, func(t *testing.T) verification.EmailVerificationRepository {
+		return NewEmailVerificationRepository(NewStore())
+	})
+}
+
+func TestPasswordResetRepository(t *testing.T) {
+	verificationtest.TestPasswordResetRepository(t, func(t *testing.T) verification.PasswordResetRepository {
+		return NewPasswordResetRepository(NewStore())
+	})
+}
@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/junghwan16/test-server/internal/identity/domain/session"
+	"github.com/junghwan16/test-server/internal/identity/domain/user"
+)
+
+type sessionRecord struct {
+	userID    string
+	clientIP  string
+	expiresAt time.Time
+	createdAt time.Time
+}
+
+// SessionRepository implements session.Repository in memory
+type SessionRepository struct {
+	mu       sync.Mutex
+	sessions map[string]sessionRecord
+}
+
+// NewSessionRepository creates a new in-memory SessionRepository
+func NewSessionRepository() *SessionRepository {
+	return &SessionRepository{
+		sessions: make(map[string]sessionRecord),
+	}
+}
+
+func (r *SessionRepository) Save(ctx context.Context, s *session.Session) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !time.Now().Before(s.ExpiresAt()) {
+		return errors.New("session already expired")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sessions[s.ID().Value()] = sessionRecord{
+		userID:    s.UserID().Value(),
+		clientIP:  s.ClientIP(),
+		expiresAt: s.ExpiresAt(),
+		createdAt: s.CreatedAt(),
+	}
+	return nil
+}
+
+func (r *SessionRepository) FindByID(ctx context.Context, id session.SessionID) (*session.Session, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rec, ok := r.sessions[id.Value()]
+	if !ok {
+		return nil, session.ErrSessionNotFound
+	}
+	if !time.Now().Before(rec.expiresAt) {
+		delete(r.sessions, id.Value())
+		return nil, session.ErrSessionNotFound
+	}
+
+	userID, _ := user.NewUserID(rec.userID)
+	return session.ReconstructSession(id, userID, rec.clientIP, rec.expiresAt, rec.createdAt), nil
+}
+
+func (r *SessionRepository) Delete(ctx context.Context, id session.SessionID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.sessions, id.Value())
+	return nil
+}
+
+// DeleteExpired removes all expired sessions
+func (r *SessionRepository) DeleteExpired(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for id, rec := range r.sessions {
+		if !now.Before(rec.expiresAt) {
+			delete(r.sessions, id)
+		}
+	}
+	return nil
+}
+
+// DeleteByUserID removes all sessions for a given user
+func (r *SessionRepository) DeleteByUserID(ctx context.Context, userID user.UserID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, rec := range r.sessions {
+		if rec.userID == userID.Value() {
+			delete(r.sessions, id)
+		}
+	}
+	return nil
+}
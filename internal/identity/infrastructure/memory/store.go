@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"maps"
+	"sync"
+	"time"
+)
+
+type userRecord struct {
+	id            string
+	email         string
+	passwordHash  string
+	role          string
+	emailVerified bool
+	active        bool
+	createdAt     time.Time
+	updatedAt     time.Time
+	version       int64
+}
+
+type tokenRecord struct {
+	token     string
+	userID    string
+	expiresAt time.Time
+	createdAt time.Time
+}
+
+// Store holds the identity data shared by the in-memory repositories.
+// A single mutex serializes access so a UnitOfWork can run atomically.
+type Store struct {
+	mu                 sync.Mutex
+	users              map[string]userRecord
+	usersByEmail       map[string]string
+	emailVerifications map[string]tokenRecord
+	passwordResets     map[string]tokenRecord
+}
+
+// NewStore creates an empty Store
+func NewStore() *Store {
+	return &Store{
+		users:              make(map[string]userRecord),
+		usersByEmail:       make(map[string]string),
+		emailVerifications: make(map[string]tokenRecord),
+		passwordResets:     make(map[string]tokenRecord),
+	}
+}
+
+type snapshot struct {
+	users              map[string]userRecord
+	usersByEmail       map[string]string
+	emailVerifications map[string]tokenRecord
+	passwordResets     map[string]tokenRecord
+}
+
+// snapshot copies the store state; the caller must hold mu
+func (s *Store) snapshot() snapshot {
+	return snapshot{
+		users:              maps.Clone(s.users),
+		usersByEmail:       maps.Clone(s.usersByEmail),
+		emailVerifications: maps.Clone(s.emailVerifications),
+		passwordResets:     maps.Clone(s.passwordResets),
+	}
+}
+
+// restore rolls the store back to a snapshot; the caller must hold mu
+func (s *Store) restore(snap snapshot) {
+	s.users = snap.users
+	s.usersByEmail = snap.usersByEmail
+	s.emailVerifications = snap.emailVerifications
+	s.passwordResets = snap.passwordResets
+}
+
+// locker returns a function that locks the store and returns its unlock,
+// or a no-op when the store is already locked by a UnitOfWork
+func locker(store *Store, inTx bool) func() func() {
+	if inTx {
+		return func() func() { return func() {} }
+	}
+	return func() func() {
+		store.mu.Lock()
+		return store.mu.Unlock
+	}
+}
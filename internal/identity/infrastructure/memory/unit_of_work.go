@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/junghwan16/test-server/internal/identity/application"
+	"github.com/junghwan16/test-server/internal/shared/domain"
+)
+
+// UnitOfWork implements application.UnitOfWork by locking the Store for the
+// duration of the use case and restoring a snapshot if it fails
+type UnitOfWork struct {
+	store    *Store
+	eventBus domain.EventBus
+}
+
+// NewUnitOfWork creates a new in-memory UnitOfWork
+func NewUnitOfWork(store *Store, eventBus domain.EventBus) *UnitOfWork {
+	return &UnitOfWork{
+		store:    store,
+		eventBus: eventBus,
+	}
+}
+
+// Do runs fn atomically and releases collected domain events after commit
+func (u *UnitOfWork) Do(ctx context.Context, fn func(repos application.Repositories) error) error {
+	events := &domain.EventCollector{}
+
+	err := u.run(ctx, func() error {
+		lock := locker(u.store, true)
+		return fn(application.Repositories{
+			Users:              &UserRepository{store: u.store, eventBus: events, lock: lock},
+			EmailVerifications: &EmailVerificationRepository{store: u.store, lock: lock},
+			PasswordResets:     &PasswordResetRepository{store: u.store, lock: lock},
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	_ = events.Release(ctx, u.eventBus)
+	return nil
+}
+
+func (u *UnitOfWork) run(ctx context.Context, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	u.store.mu.Lock()
+	defer u.store.mu.Unlock()
+
+	snap := u.store.snapshot()
+	if err := fn(); err != nil {
+		u.store.restore(snap)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/junghwan16/test-server/internal/identity/application"
+	"github.com/junghwan16/test-server/internal/identity/domain/user"
+	"github.com/junghwan16/test-server/internal/identity/domain/verification"
+	"github.com/junghwan16/test-server/internal/shared/domain"
+)
+
+func TestUnitOfWork(t *testing.T) {
+	newUser := func(ctx context.Context, repo user.Repository) *user.User {
+		password := user.NewPasswordFromHash("hash")
+		u, _ := user.NewUser(repo.NextID(ctx), user.MustNewEmail("uow@example.com"), password)
+		return u
+	}
+
+	t.Run("실패 시 모든 변경 롤백", func(t *testing.T) {
+		// Given: 저장소와 이벤트 버스
+		ctx := t.Context()
+		store := NewStore()
+		bus := domain.NewSimpleEventBus()
+		var published int
+		bus.Subscribe(func(context.Context, domain.DomainEvent) error { published++; return nil })
+		uow := NewUnitOfWork(store, bus)
+
+		// When: 여러 쓰기 후 에러 반환
+		boom := errors.New("boom")
+		err := uow.Do(ctx, func(repos application.Repositories) error {
+			u := newUser(ctx, repos.Users)
+			if err := repos.Users.Save(ctx, u); err != nil {
+				return err
+			}
+			if err := repos.PasswordResets.Save(ctx, verification.NewPasswordReset(u.ID(), time.Hour)); err != nil {
+				return err
+			}
+			return boom
+		})
+
+		// Then: 에러가 전달되고 저장소와 이벤트 모두 변화 없음
+		if !errors.Is(err, boom) {
+			t.Fatalf("expected boom, got %v", err)
+		}
+		if _, err := NewUserRepository(store, bus).FindByEmail(ctx, user.MustNewEmail("uow@example.com")); err == nil {
+			t.Error("expected user write to be rolled back")
+		}
+		if len(store.passwordResets) != 0 {
+			t.Error("expected token write to be rolled back")
+		}
+		if published != 0 {
+			t.Errorf("expected no events, got %d", published)
+		}
+	})
+
+	t.Run("커밋 후 이벤트 발행", func(t *testing.T) {
+		// Given: 저장소와 이벤트 버스
+		ctx := t.Context()
+		store := NewStore()
+		bus := domain.NewSimpleEventBus()
+		var published int
+		bus.Subscribe(func(context.Context, domain.DomainEvent) error { published++; return nil })
+		uow := NewUnitOfWork(store, bus)
+
+		// When: 성공하는 작업 단위
+		err := uow.Do(ctx, func(repos application.Repositories) error {
+			if err := repos.Users.Save(ctx, newUser(ctx, repos.Users)); err != nil {
+				return err
+			}
+			if published != 0 {
+				t.Error("expected events to be held until commit")
+			}
+			return nil
+		})
+
+		// Then: 커밋 후 이벤트가 발행됨
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if published != 1 {
+			t.Errorf("expected 1 event, got %d", published)
+		}
+	})
+}
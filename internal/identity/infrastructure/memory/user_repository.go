@@ -0,0 +1,189 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/junghwan16/test-server/internal/identity/domain/user"
+	"github.com/junghwan16/test-server/internal/shared/domain"
+)
+
+// UserRepository implements user.Repository in memory
+type UserRepository struct {
+	store    *Store
+	eventBus domain.EventBus
+	lock     func() func()
+}
+
+// NewUserRepository creates a new in-memory UserRepository
+func NewUserRepository(store *Store, eventBus domain.EventBus) *UserRepository {
+	return &UserRepository{
+		store:    store,
+		eventBus: eventBus,
+		lock:     locker(store, false),
+	}
+}
+
+// NextID generates a new UserID
+func (r *UserRepository) NextID(ctx context.Context) user.UserID {
+	return user.GenerateUserID()
+}
+
+func (r *UserRepository) Save(ctx context.Context, u *user.User) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if u.ID().IsZero() {
+		return user.ErrInvalidUserID
+	}
+
+	unlock := r.lock()
+
+	rec := toRecord(u)
+	existing, exists := r.store.users[rec.id]
+
+	if u.Version() == 0 {
+		if exists {
+			unlock()
+			return errors.New("user already exists")
+		}
+		rec.version = 1
+	} else {
+		if !exists || existing.version != u.Version() {
+			unlock()
+			return user.ErrConcurrentModification
+		}
+		rec.version = existing.version + 1
+	}
+
+	if ownerID, taken := r.store.usersByEmail[rec.email]; taken && ownerID != rec.id {
+		unlock()
+		return user.ErrDuplicateEmail
+	}
+
+	if exists && existing.email != rec.email {
+		delete(r.store.usersByEmail, existing.email)
+	}
+	r.store.users[rec.id] = rec
+	r.store.usersByEmail[rec.email] = rec.id
+	unlock()
+
+	u.SetVersion(rec.version)
+
+	// Publish domain events
+	for _, event := range u.DomainEvents() {
+		_ = r.eventBus.Publish(ctx, event)
+	}
+	u.ClearEvents()
+
+	return nil
+}
+
+// FindByID retrieves a User by ID
+func (r *UserRepository) FindByID(ctx context.Context, id user.UserID) (*user.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	defer r.lock()()
+
+	rec, ok := r.store.users[id.Value()]
+	if !ok {
+		return nil, user.ErrUserNotFound
+	}
+	return toDomain(rec), nil
+}
+
+// FindByEmail retrieves a User by email
+func (r *UserRepository) FindByEmail(ctx context.Context, email user.Email) (*user.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	defer r.lock()()
+
+	id, ok := r.store.usersByEmail[email.Value()]
+	if !ok {
+		return nil, user.ErrUserNotFound
+	}
+	return toDomain(r.store.users[id]), nil
+}
+
+// FindAll retrieves all users with pagination, newest first
+func (r *UserRepository) FindAll(ctx context.Context, limit, offset int) ([]*user.User, int64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+	defer r.lock()()
+
+	records := make([]userRecord, 0, len(r.store.users))
+	for _, rec := range r.store.users {
+		records = append(records, rec)
+	}
+	slices.SortFunc(records, func(a, b userRecord) int {
+		if c := b.createdAt.Compare(a.createdAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.id, a.id)
+	})
+
+	total := int64(len(records))
+	start := min(max(offset, 0), len(records))
+	end := len(records)
+	if limit >= 0 {
+		end = min(start+limit, len(records))
+	}
+
+	users := make([]*user.User, 0, end-start)
+	for _, rec := range records[start:end] {
+		users = append(users, toDomain(rec))
+	}
+
+	return users, total, nil
+}
+
+func (r *UserRepository) Delete(ctx context.Context, id user.UserID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	defer r.lock()()
+
+	if rec, ok := r.store.users[id.Value()]; ok {
+		delete(r.store.usersByEmail, rec.email)
+		delete(r.store.users, id.Value())
+	}
+	return nil
+}
+
+// Mapping functions
+
+func toRecord(u *user.User) userRecord {
+	return userRecord{
+		id:            u.ID().Value(),
+		email:         u.Email().Value(),
+		passwordHash:  u.Password().Hash(),
+		role:          u.Role().Value(),
+		emailVerified: u.EmailVerified(),
+		active:        u.Active(),
+		createdAt:     u.CreatedAt(),
+		updatedAt:     u.UpdatedAt(),
+	}
+}
+
+func toDomain(rec userRecord) *user.User {
+	id, _ := user.NewUserID(rec.id)
+	email, _ := user.NewEmail(rec.email)
+	role, _ := user.NewRole(rec.role)
+
+	return user.ReconstructUser(
+		id,
+		email,
+		user.NewPasswordFromHash(rec.passwordHash),
+		role,
+		rec.emailVerified,
+		rec.active,
+		rec.createdAt,
+		rec.updatedAt,
+		rec.version,
+	)
+}
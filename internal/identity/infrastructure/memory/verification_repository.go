@@ -0,0 +1,131 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/junghwan16/test-server/internal/identity/domain/user"
+	"github.com/junghwan16/test-server/internal/identity/domain/verification"
+)
+
+// EmailVerificationRepository implements verification.EmailVerificationRepository in memory
+type EmailVerificationRepository struct {
+	store *Store
+	lock  func() func()
+}
+
+// NewEmailVerificationRepository creates a new in-memory EmailVerificationRepository
+func NewEmailVerificationRepository(store *Store) *EmailVerificationRepository {
+	return &EmailVerificationRepository{store: store, lock: locker(store, false)}
+}
+
+func (r *EmailVerificationRepository) Save(ctx context.Context, v *verification.EmailVerification) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	defer r.lock()()
+
+	if _, exists := r.store.emailVerifications[v.Token()]; exists {
+		return verification.ErrDuplicateToken
+	}
+	r.store.emailVerifications[v.Token()] = tokenRecord{
+		token:     v.Token(),
+		userID:    v.UserID().Value(),
+		expiresAt: v.ExpiresAt(),
+		createdAt: v.CreatedAt(),
+	}
+	return nil
+}
+
+func (r *EmailVerificationRepository) FindByToken(ctx context.Context, token string) (*verification.EmailVerification, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	defer r.lock()()
+
+	rec, ok := r.store.emailVerifications[token]
+	if !ok || !time.Now().Before(rec.expiresAt) {
+		return nil, verification.ErrTokenNotFound
+	}
+
+	userID, _ := user.NewUserID(rec.userID)
+	return verification.ReconstructEmailVerification(rec.token, userID, rec.expiresAt, rec.createdAt), nil
+}
+
+func (r *EmailVerificationRepository) Delete(ctx context.Context, token string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	defer r.lock()()
+
+	delete(r.store.emailVerifications, token)
+	return nil
+}
+
+// PasswordResetRepository implements verification.PasswordResetRepository in memory
+type PasswordResetRepository struct {
+	store *Store
+	lock  func() func()
+}
+
+// NewPasswordResetRepository creates a new in-memory PasswordResetRepository
+func NewPasswordResetRepository(store *Store) *PasswordResetRepository {
+	return &PasswordResetRepository{store: store, lock: locker(store, false)}
+}
+
+func (r *PasswordResetRepository) Save(ctx context.Context, p *verification.PasswordReset) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	defer r.lock()()
+
+	if _, exists := r.store.passwordResets[p.Token()]; exists {
+		return verification.ErrDuplicateToken
+	}
+	r.store.passwordResets[p.Token()] = tokenRecord{
+		token:     p.Token(),
+		userID:    p.UserID().Value(),
+		expiresAt: p.ExpiresAt(),
+		createdAt: p.CreatedAt(),
+	}
+	return nil
+}
+
+func (r *PasswordResetRepository) FindByToken(ctx context.Context, token string) (*verification.PasswordReset, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	defer r.lock()()
+
+	rec, ok := r.store.passwordResets[token]
+	if !ok || !time.Now().Before(rec.expiresAt) {
+		return nil, verification.ErrTokenNotFound
+	}
+
+	userID, _ := user.NewUserID(rec.userID)
+	return verification.ReconstructPasswordReset(rec.token, userID, rec.expiresAt, rec.createdAt), nil
+}
+
+func (r *PasswordResetRepository) Delete(ctx context.Context, token string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	defer r.lock()()
+
+	delete(r.store.passwordResets, token)
+	return nil
+}
+
+func (r *PasswordResetRepository) DeleteByUserID(ctx context.Context, userID user.UserID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	defer r.lock()()
+
+	for token, rec := range r.store.passwordResets {
+		if rec.userID == userID.Value() {
+			delete(r.store.passwordResets, token)
+		}
+	}
+	return nil
+}
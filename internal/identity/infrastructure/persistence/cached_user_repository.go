@@ -0,0 +1,258 @@
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/junghwan16/test-server/internal/identity/domain/user"
+	"github.com/junghwan16/test-server/internal/shared/domain"
+	"github.com/junghwan16/test-server/internal/shared/infrastructure/cache"
+	"github.com/junghwan16/test-server/internal/shared/infrastructure/keyring"
+)
+
+// UserCacheNotifier fans user cache invalidations out to other replicas
+type UserCacheNotifier interface {
+	Notify(ctx context.Context, id user.UserID) error
+}
+
+// UserCacheOptions configures a CachedUserRepository
+type UserCacheOptions struct {
+	// Size is the number of users kept in the in-process LRU
+	Size int
+	// TTL bounds how long a cached user may be served
+	TTL time.Duration
+	// Redis adds a cache layer shared by all replicas; nil disables it
+	Redis redis.UniversalClient
+	// Keys encrypts entries in Redis; required with Redis
+	Keys *keyring.Keyring
+	// Notifier tells other replicas to drop their local copies; nil disables it
+	Notifier UserCacheNotifier
+	Logger   *slog.Logger
+}
+
+type cachedUser struct {
+	ID            string    `json:"id"`
+	Email         string    `json:"email"`
+	PasswordHash  string    `json:"password_hash"`
+	Role          string    `json:"role"`
+	EmailVerified bool      `json:"email_verified"`
+	Active        bool      `json:"active"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	Version       int64     `json:"version"`
+}
+
+// CachedUserRepository is a read-through cache in front of FindByID, used to
+// keep session validation off the database. User domain events (see
+// HandleEvent) invalidate cached entries once the write is committed; writes
+// through this repository that raise no event invalidate directly.
+type CachedUserRepository struct {
+	user.Repository
+
+	local    *cache.LRU[string, cachedUser]
+	redis    redis.UniversalClient
+	keys     *keyring.Keyring
+	ttl      time.Duration
+	notifier UserCacheNotifier
+	logger   *slog.Logger
+
+	// generation is bumped on every invalidation so a lookup that raced with
+	// a write does not put the stale user it read back into the cache
+	generation atomic.Uint64
+}
+
+// NewCachedUserRepository wraps inner with a user cache
+func NewCachedUserRepository(inner user.Repository, opts UserCacheOptions) *CachedUserRepository {
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	return &CachedUserRepository{
+		Repository: inner,
+		local:      cache.NewLRU[string, cachedUser](opts.Size, opts.TTL),
+		redis:      opts.Redis,
+		keys:       opts.Keys,
+		ttl:        opts.TTL,
+		notifier:   opts.Notifier,
+		logger:     opts.Logger,
+	}
+}
+
+// FindByID returns the cached user, loading it on a miss
+func (r *CachedUserRepository) FindByID(ctx context.Context, id user.UserID) (*user.User, error) {
+	if c, ok := r.local.Get(id.Value()); ok {
+		return c.toDomain(), nil
+	}
+
+	generation := r.generation.Load()
+
+	if r.redis != nil {
+		if c, ok := r.getShared(ctx, id); ok {
+			r.fill(generation, c)
+			return c.toDomain(), nil
+		}
+	}
+
+	u, err := r.Repository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	c := toCachedUser(u)
+	if r.redis != nil && r.generation.Load() == generation {
+		r.setShared(ctx, c)
+	}
+	r.fill(generation, c)
+
+	return u, nil
+}
+
+func (r *CachedUserRepository) Save(ctx context.Context, u *user.User) error {
+	// The inner repository publishes pending events after the write, and
+	// HandleEvent invalidates on them; invalidating here too would double it
+	announced := len(u.DomainEvents()) > 0
+	if err := r.Repository.Save(ctx, u); err != nil {
+		return err
+	}
+	if !announced {
+		r.Invalidate(ctx, u.ID())
+	}
+	return nil
+}
+
+func (r *CachedUserRepository) Delete(ctx context.Context, id user.UserID) error {
+	if err := r.Repository.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.Invalidate(ctx, id)
+	return nil
+}
+
+// HandleEvent invalidates the user a domain event is about. Subscribe it to
+// the event bus so that exactly one replica handles each event; Invalidate
+// reaches the other replicas itself.
+func (r *CachedUserRepository) HandleEvent(ctx context.Context, event domain.DomainEvent) error {
+	if e, ok := event.(user.Event); ok && !e.AggregateID().IsZero() {
+		r.Invalidate(context.WithoutCancel(ctx), e.AggregateID())
+	}
+	return nil
+}
+
+// Invalidate drops the user from every cache layer and notifies other replicas
+func (r *CachedUserRepository) Invalidate(ctx context.Context, id user.UserID) {
+	r.InvalidateLocal(id)
+
+	if r.redis != nil {
+		if err := r.redis.Del(ctx, userCacheKey(id.Value())).Err(); err != nil {
+			r.logger.WarnContext(ctx, "user cache: redis invalidation failed", "user_id", id.Value(), "error", err)
+		}
+	}
+
+	if r.notifier != nil {
+		if err := r.notifier.Notify(ctx, id); err != nil {
+			r.logger.WarnContext(ctx, "user cache: notify failed", "user_id", id.Value(), "error", err)
+		}
+	}
+}
+
+// InvalidateLocal drops the user from this replica's in-process cache only
+func (r *CachedUserRepository) InvalidateLocal(id user.UserID) {
+	r.generation.Add(1)
+	r.local.Delete(id.Value())
+}
+
+// PurgeLocal empties this replica's in-process cache
+func (r *CachedUserRepository) PurgeLocal() {
+	r.generation.Add(1)
+	r.local.Purge()
+}
+
+func (r *CachedUserRepository) fill(generation uint64, c cachedUser) {
+	if r.generation.Load() == generation {
+		r.local.Set(c.ID, c)
+	}
+}
+
+func (r *CachedUserRepository) getShared(ctx context.Context, id user.UserID) (cachedUser, bool) {
+	var c cachedUser
+
+	sealed, err := r.redis.Get(ctx, userCacheKey(id.Value())).Result()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			r.logger.WarnContext(ctx, "user cache: redis read failed", "user_id", id.Value(), "error", err)
+		}
+		return c, false
+	}
+
+	// Entries under a retired key or from before encryption count as misses
+	data, err := r.keys.Decrypt(sealed, userCacheAAD(id.Value()))
+	if err != nil {
+		return c, false
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, false
+	}
+	return c, true
+}
+
+func (r *CachedUserRepository) setShared(ctx context.Context, c cachedUser) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return
+	}
+	sealed, err := r.keys.Encrypt(data, userCacheAAD(c.ID))
+	if err != nil {
+		r.logger.WarnContext(ctx, "user cache: encryption failed", "user_id", c.ID, "error", err)
+		return
+	}
+	if err := r.redis.Set(ctx, userCacheKey(c.ID), sealed, r.ttl).Err(); err != nil {
+		r.logger.WarnContext(ctx, "user cache: redis write failed", "user_id", c.ID, "error", err)
+	}
+}
+
+func userCacheKey(id string) string {
+	return "user_cache:{" + id + "}"
+}
+
+// userCacheAAD binds an encrypted cache entry to its user
+func userCacheAAD(id string) []byte {
+	return []byte("user_cache:" + id)
+}
+
+func toCachedUser(u *user.User) cachedUser {
+	return cachedUser{
+		ID:            u.ID().Value(),
+		Email:         u.Email().Value(),
+		PasswordHash:  u.Password().Hash(),
+		Role:          u.Role().Value(),
+		EmailVerified: u.EmailVerified(),
+		Active:        u.Active(),
+		CreatedAt:     u.CreatedAt(),
+		UpdatedAt:     u.UpdatedAt(),
+		Version:       u.Version(),
+	}
+}
+
+// toDomain builds a fresh aggregate so callers never share cached state
+func (c cachedUser) toDomain() *user.User {
+	id, _ := user.NewUserID(c.ID)
+	email, _ := user.NewEmail(c.Email)
+	role, _ := user.NewRole(c.Role)
+
+	return user.ReconstructUser(
+		id,
+		email,
+		user.NewPasswordFromHash(c.PasswordHash),
+		role,
+		c.EmailVerified,
+		c.Active,
+		c.CreatedAt,
+		c.UpdatedAt,
+		c.Version,
+	)
+}
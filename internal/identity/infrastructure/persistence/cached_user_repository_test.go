@@ -0,0 +1,226 @@
+package persistence
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/junghwan16/test-server/internal/identity/domain/user"
+	"github.com/junghwan16/test-server/internal/identity/domain/user/usertest"
+	"github.com/junghwan16/test-server/internal/shared/domain"
+)
+
+// countingNotifier counts invalidations fanned out to other replicas
+type countingNotifier struct {
+	notified atomic.Int32
+}
+
+func (n *countingNotifier) Notify(context.Context, user.UserID) error {
+	n.notified.Add(1)
+	return nil
+}
+
+// countingUserRepository counts lookups that reach the wrapped repository
+type countingUserRepository struct {
+	user.Repository
+	finds atomic.Int32
+}
+
+func (r *countingUserRepository) FindByID(ctx context.Context, id user.UserID) (*user.User, error) {
+	r.finds.Add(1)
+	return r.Repository.FindByID(ctx, id)
+}
+
+func TestCachedUserRepository(t *testing.T) {
+	opts := UserCacheOptions{Size: 100, TTL: time.Minute}
+
+	forEachDatabase(t, func(t *testing.T, newDB func(t *testing.T) *gorm.DB) {
+		usertest.TestRepository(t, func(t *testing.T) user.Repository {
+			bus := domain.NewSimpleEventBus()
+			cached := NewCachedUserRepository(NewUserRepository(newDB(t), bus, testKeys), opts)
+			bus.Subscribe(cached.HandleEvent)
+			return cached
+		})
+	})
+
+	newFixture := func(t *testing.T) (*CachedUserRepository, *countingUserRepository, *UserRepository, *user.User) {
+		t.Helper()
+		db := newSQLiteTestDB(t)
+		bus := domain.NewSimpleEventBus()
+		inner := NewUserRepository(db, bus, testKeys)
+		counting := &countingUserRepository{Repository: inner}
+		cached := NewCachedUserRepository(counting, opts)
+		bus.Subscribe(cached.HandleEvent)
+
+		u, _ := user.NewUser(user.GenerateUserID(), user.MustNewEmail("cache@example.com"), user.NewPasswordFromHash("hash"))
+		if err := cached.Save(t.Context(), u); err != nil {
+			t.Fatalf("failed to save user: %v", err)
+		}
+		return cached, counting, inner, u
+	}
+
+	t.Run("반복 조회는 캐시에서 응답", func(t *testing.T) {
+		// Given: 저장된 사용자
+		cached, counting, _, u := newFixture(t)
+
+		// When: 세 번 조회
+		for range 3 {
+			if _, err := cached.FindByID(t.Context(), u.ID()); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+
+		// Then: 저장소는 한 번만 조회됨
+		if got := counting.finds.Load(); got != 1 {
+			t.Errorf("expected 1 repository lookup, got %d", got)
+		}
+	})
+
+	t.Run("도메인 이벤트로 무효화", func(t *testing.T) {
+		// Given: 캐시된 사용자
+		ctx := t.Context()
+		cached, counting, inner, u := newFixture(t)
+		cached.FindByID(ctx, u.ID())
+
+		// When: 캐시를 거치지 않는 쓰기 (작업 단위 등) 후 이벤트 발행
+		loaded, _ := inner.FindByID(ctx, u.ID())
+		loaded.VerifyEmail()
+		if err := inner.Save(ctx, loaded); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		found, _ := cached.FindByID(ctx, u.ID())
+
+		// Then: 새 값을 다시 읽음
+		if !found.EmailVerified() || found.Version() != 2 {
+			t.Errorf("expected fresh user, got verified=%v version=%d", found.EmailVerified(), found.Version())
+		}
+		if got := counting.finds.Load(); got != 2 {
+			t.Errorf("expected 2 repository lookups, got %d", got)
+		}
+	})
+
+	t.Run("쓰기마다 한 번만 무효화", func(t *testing.T) {
+		// Given: 알림 횟수를 세는 캐시와 저장된 사용자
+		ctx := t.Context()
+		bus := domain.NewSimpleEventBus()
+		inner := NewUserRepository(newSQLiteTestDB(t), bus, testKeys)
+		notifier := &countingNotifier{}
+		cached := NewCachedUserRepository(inner, UserCacheOptions{Size: 100, TTL: time.Minute, Notifier: notifier})
+		bus.Subscribe(cached.HandleEvent)
+		u, _ := user.NewUser(user.GenerateUserID(), user.MustNewEmail("once@example.com"), user.NewPasswordFromHash("hash"))
+		if err := cached.Save(ctx, u); err != nil {
+			t.Fatalf("failed to save user: %v", err)
+		}
+		notifier.notified.Store(0)
+
+		// When: 이벤트를 발생시키는 변경을 캐시를 통해 저장
+		u.VerifyEmail()
+		if err := cached.Save(ctx, u); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		// Then: 다른 레플리카에는 한 번만 알림
+		if got := notifier.notified.Load(); got != 1 {
+			t.Errorf("expected 1 notification, got %d", got)
+		}
+
+		// When: 이벤트 없는 삭제
+		if err := cached.Delete(ctx, u.ID()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		// Then: 삭제도 한 번만 알림
+		if got := notifier.notified.Load(); got != 2 {
+			t.Errorf("expected 2 notifications in total, got %d", got)
+		}
+	})
+
+	t.Run("Redis 공유 캐시", func(t *testing.T) {
+		// Given: Redis 를 공유하는 두 레플리카
+		client := newRedisTestClient(t)
+		ctx := t.Context()
+		db := newSQLiteTestDB(t)
+		inner := NewUserRepository(db, domain.NewSimpleEventBus(), testKeys)
+		u, _ := user.NewUser(user.GenerateUserID(), user.MustNewEmail("shared@example.com"), user.NewPasswordFromHash("hash"))
+		inner.Save(ctx, u)
+		client.Del(ctx, userCacheKey(u.ID().Value()))
+
+		withRedis := UserCacheOptions{Size: 100, TTL: time.Minute, Redis: client, Keys: testKeys}
+		first := NewCachedUserRepository(&countingUserRepository{Repository: inner}, withRedis)
+		secondInner := &countingUserRepository{Repository: inner}
+		second := NewCachedUserRepository(secondInner, withRedis)
+
+		// When: 첫 번째 레플리카가 조회한 뒤 두 번째 레플리카가 조회
+		first.FindByID(ctx, u.ID())
+		found, err := second.FindByID(ctx, u.ID())
+
+		// Then: 두 번째 레플리카는 저장소를 거치지 않음
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if found.Email() != u.Email() {
+			t.Errorf("expected %s, got %s", u.Email().Value(), found.Email().Value())
+		}
+		if got := secondInner.finds.Load(); got != 0 {
+			t.Errorf("expected no repository lookup on the second replica, got %d", got)
+		}
+
+		// And: Redis 에는 이메일과 비밀번호 해시가 평문으로 남지 않음
+		stored, _ := client.Get(ctx, userCacheKey(u.ID().Value())).Result()
+		if stored == "" || strings.Contains(stored, "shared@example.com") || strings.Contains(stored, "password_hash") {
+			t.Errorf("expected an encrypted entry, got %q", stored)
+		}
+
+		// When: 첫 번째 레플리카에서 무효화
+		first.Invalidate(ctx, u.ID())
+
+		// Then: 공유 캐시에서도 제거됨
+		if n, _ := client.Exists(ctx, userCacheKey(u.ID().Value())).Result(); n != 0 {
+			t.Error("expected shared entry to be deleted")
+		}
+	})
+}
+
+func TestPostgresUserCacheNotifier(t *testing.T) {
+	dsn := os.Getenv("POSTGRES_TEST_DSN")
+	if dsn == "" {
+		t.Skip("POSTGRES_TEST_DSN not set")
+	}
+
+	// Given: 알림을 듣는 레플리카의 캐시
+	db, err := gorm.Open(postgres.Open(dsn), testGormConfig)
+	if err != nil {
+		t.Fatalf("failed to open postgres: %v", err)
+	}
+	notifier := NewPostgresUserCacheNotifier(db, dsn, slog.New(slog.DiscardHandler))
+	replica := NewCachedUserRepository(nil, UserCacheOptions{Size: 10, TTL: time.Minute})
+	id := user.GenerateUserID()
+	replica.local.Set(id.Value(), cachedUser{ID: id.Value()})
+	if err := notifier.Start(t.Context(), replica); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer notifier.Close()
+
+	// When: 다른 레플리카가 무효화를 알림 (LISTEN 이 준비될 때까지 반복)
+	invalidated := false
+	for deadline := time.Now().Add(5 * time.Second); !invalidated && time.Now().Before(deadline); {
+		if err := notifier.Notify(t.Context(), id); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+		_, cached := replica.local.Get(id.Value())
+		invalidated = !cached
+	}
+
+	// Then: 로컬 캐시에서 제거됨
+	if !invalidated {
+		t.Error("expected local entry to be invalidated")
+	}
+}
@@ -0,0 +1,163 @@
+package persistence
+
+import (
+	"bytes"
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/junghwan16/test-server/internal/shared/infrastructure/keyring"
+	"github.com/junghwan16/test-server/internal/shared/infrastructure/migrate"
+)
+
+var testGormConfig = &gorm.Config{TranslateError: true, Logger: logger.Discard}
+
+// testKeys encrypts user PII in tests
+var testKeys = mustTestKeyring(map[string][]byte{"test-1": bytes.Repeat([]byte{1}, 32)}, "test-1")
+
+func mustTestKeyring(keys map[string][]byte, primary string) *keyring.Keyring {
+	k, err := keyring.New(keys, primary, bytes.Repeat([]byte{9}, 32))
+	if err != nil {
+		panic(err)
+	}
+	return k
+}
+
+// forEachDatabase runs fn once per supported database. newDB returns an empty,
+// migrated database: a fresh SQLite file, or the Postgres database at
+// POSTGRES_TEST_DSN with its tables truncated (skipped when unset).
+func forEachDatabase(t *testing.T, fn func(t *testing.T, newDB func(t *testing.T) *gorm.DB)) {
+	t.Helper()
+
+	t.Run("sqlite", func(t *testing.T) {
+		fn(t, newSQLiteTestDB)
+	})
+
+	t.Run("postgres", func(t *testing.T) {
+		dsn := os.Getenv("POSTGRES_TEST_DSN")
+		if dsn == "" {
+			t.Skip("POSTGRES_TEST_DSN not set")
+		}
+		fn(t, func(t *testing.T) *gorm.DB {
+			db, err := gorm.Open(postgres.Open(dsn), testGormConfig)
+			if err != nil {
+				t.Fatalf("failed to open postgres: %v", err)
+			}
+			migrateTestDB(t, db)
+			err = db.Exec("TRUNCATE users, email_verifications, password_resets, sessions RESTART IDENTITY").Error
+			if err != nil {
+				t.Fatalf("failed to reset postgres: %v", err)
+			}
+			return db
+		})
+	})
+}
+
+// newSQLiteTestDB returns a migrated database in a fresh SQLite file
+func newSQLiteTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := OpenSQLite(filepath.Join(t.TempDir(), "test.db"), testGormConfig)
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+	migrateTestDB(t, db)
+	return db
+}
+
+// newRedisTestClient connects to the Redis at REDIS_TEST_ADDR (default
+// localhost:6379) and skips the test when it is unreachable
+func newRedisTestClient(t *testing.T) *redis.Client {
+	t.Helper()
+
+	addr := os.Getenv("REDIS_TEST_ADDR")
+	if addr == "" {
+		addr = "localhost:6379"
+	}
+
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		t.Skipf("redis not available at %s: %v", addr, err)
+	}
+
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func migrateTestDB(t *testing.T, db *gorm.DB) {
+	t.Helper()
+
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+	if _, err := migrator.Up(t.Context()); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+}
+
+// migrateTestDBTo drops the identity schema and rebuilds it with migrations
+// up to version only, returning a migrator limited to those migrations.
+// Use it to seed rows in an older schema before testing a data migration.
+func migrateTestDBTo(t *testing.T, db *gorm.DB, version int64) *migrate.Migrator {
+	t.Helper()
+
+	for _, table := range []string{"sessions", "password_resets", "email_verifications", "users", "schema_migrations"} {
+		if err := db.Exec("DROP TABLE IF EXISTS " + table).Error; err != nil {
+			t.Fatalf("failed to drop %s: %v", table, err)
+		}
+	}
+
+	migrator := migratorThrough(t, db, version)
+	if _, err := migrator.Up(t.Context()); err != nil {
+		t.Fatalf("failed to migrate to %d: %v", version, err)
+	}
+	return migrator
+}
+
+// migratorThrough returns a migrator that only knows migrations up to version
+func migratorThrough(t *testing.T, db *gorm.DB, version int64) *migrate.Migrator {
+	t.Helper()
+
+	files := fstest.MapFS{}
+	err := fs.WalkDir(Migrations(), ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		prefix, _, _ := strings.Cut(path, "_")
+		if v, err := strconv.ParseInt(prefix, 10, 64); err != nil || v > version {
+			return nil
+		}
+		data, err := fs.ReadFile(Migrations(), path)
+		files[path] = &fstest.MapFile{Data: data}
+		return err
+	})
+	if err != nil {
+		t.Fatalf("failed to read migrations: %v", err)
+	}
+
+	migrator, err := newMigrator(db, files)
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+	return migrator
+}
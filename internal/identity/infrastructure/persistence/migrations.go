@@ -0,0 +1,49 @@
+package persistence
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+
+	"gorm.io/gorm"
+
+	"github.com/junghwan16/test-server/internal/shared/infrastructure/migrate"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// MigrationsDir is the source directory of the migrations, relative to the module root
+const MigrationsDir = "internal/identity/infrastructure/persistence/migrations"
+
+// Migrations returns the versioned SQL migrations for the identity schema
+func Migrations() fs.FS {
+	sub, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+// NewMigrator creates a migrator for the identity schema in db's dialect
+func NewMigrator(db *gorm.DB) (*migrate.Migrator, error) {
+	return newMigrator(db, Migrations())
+}
+
+func newMigrator(db *gorm.DB, fsys fs.FS) (*migrate.Migrator, error) {
+	var dialect migrate.Dialect
+	switch name := db.Dialector.Name(); name {
+	case "postgres":
+		dialect = migrate.Postgres
+	case "sqlite":
+		dialect = migrate.SQLite
+	default:
+		return nil, fmt.Errorf("unsupported database dialect %q", name)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	return migrate.New(sqlDB, fsys, dialect)
+}
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS users (
+    id             INTEGER PRIMARY KEY AUTOINCREMENT,
+    email          TEXT     NOT NULL,
+    password_hash  TEXT     NOT NULL,
+    role           TEXT     NOT NULL DEFAULT 'user',
+    email_verified BOOLEAN  NOT NULL DEFAULT FALSE,
+    active         BOOLEAN  NOT NULL DEFAULT TRUE,
+    created_at     DATETIME,
+    updated_at     DATETIME
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+
+CREATE TABLE IF NOT EXISTS email_verifications (
+    token      TEXT PRIMARY KEY,
+    user_id    INTEGER  NOT NULL,
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_verifications_user_id ON email_verifications (user_id);
+CREATE INDEX IF NOT EXISTS idx_email_verifications_expires_at ON email_verifications (expires_at);
+
+CREATE TABLE IF NOT EXISTS password_resets (
+    token      TEXT PRIMARY KEY,
+    user_id    INTEGER  NOT NULL,
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_resets_user_id ON password_resets (user_id);
+CREATE INDEX IF NOT EXISTS idx_password_resets_expires_at ON password_resets (expires_at);
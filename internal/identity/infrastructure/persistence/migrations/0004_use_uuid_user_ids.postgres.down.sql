@@ -0,0 +1,40 @@
+-- Renumber users in ID (signup) order. The original numbers are not kept,
+-- so IDs handed out before the upgrade are not restored.
+ALTER TABLE users ADD COLUMN old_id BIGINT;
+
+UPDATE users u SET old_id = n.rn
+FROM (SELECT id, row_number() OVER (ORDER BY id) AS rn FROM users) n
+WHERE n.id = u.id;
+
+ALTER TABLE email_verifications ADD COLUMN old_user_id BIGINT;
+UPDATE email_verifications t SET old_user_id = u.old_id FROM users u WHERE u.id = t.user_id;
+DELETE FROM email_verifications WHERE old_user_id IS NULL;
+ALTER TABLE email_verifications DROP COLUMN user_id;
+ALTER TABLE email_verifications RENAME COLUMN old_user_id TO user_id;
+ALTER TABLE email_verifications ALTER COLUMN user_id SET NOT NULL;
+CREATE INDEX idx_email_verifications_user_id ON email_verifications (user_id);
+
+ALTER TABLE password_resets ADD COLUMN old_user_id BIGINT;
+UPDATE password_resets t SET old_user_id = u.old_id FROM users u WHERE u.id = t.user_id;
+DELETE FROM password_resets WHERE old_user_id IS NULL;
+ALTER TABLE password_resets DROP COLUMN user_id;
+ALTER TABLE password_resets RENAME COLUMN old_user_id TO user_id;
+ALTER TABLE password_resets ALTER COLUMN user_id SET NOT NULL;
+CREATE INDEX idx_password_resets_user_id ON password_resets (user_id);
+
+ALTER TABLE sessions ADD COLUMN old_user_id BIGINT;
+UPDATE sessions t SET old_user_id = u.old_id FROM users u WHERE u.id = t.user_id;
+DELETE FROM sessions WHERE old_user_id IS NULL;
+ALTER TABLE sessions DROP COLUMN user_id;
+ALTER TABLE sessions RENAME COLUMN old_user_id TO user_id;
+ALTER TABLE sessions ALTER COLUMN user_id SET NOT NULL;
+CREATE INDEX idx_sessions_user_id ON sessions (user_id);
+
+ALTER TABLE users DROP COLUMN id;
+ALTER TABLE users RENAME COLUMN old_id TO id;
+ALTER TABLE users ALTER COLUMN id SET NOT NULL;
+ALTER TABLE users ADD PRIMARY KEY (id);
+
+CREATE SEQUENCE users_id_seq OWNED BY users.id;
+SELECT setval('users_id_seq', COALESCE((SELECT max(id) FROM users), 0) + 1, false);
+ALTER TABLE users ALTER COLUMN id SET DEFAULT nextval('users_id_seq');
@@ -0,0 +1,42 @@
+-- Replace sequential user IDs with UUIDv7. Existing users get an ID whose
+-- timestamp is their signup time, so IDs still sort in signup order.
+-- Tokens and sessions are re-pointed at the new IDs; orphans are dropped.
+ALTER TABLE users ADD COLUMN new_id UUID;
+
+UPDATE users SET new_id = encode(
+    set_bit(set_bit(
+        overlay(uuid_send(gen_random_uuid())
+            PLACING substring(int8send(floor(extract(epoch FROM COALESCE(created_at, now())) * 1000)::BIGINT) FROM 3)
+            FROM 1 FOR 6),
+        52, 1), 53, 1),
+    'hex')::UUID;
+
+ALTER TABLE email_verifications ADD COLUMN new_user_id UUID;
+UPDATE email_verifications t SET new_user_id = u.new_id FROM users u WHERE u.id = t.user_id;
+DELETE FROM email_verifications WHERE new_user_id IS NULL;
+ALTER TABLE email_verifications DROP COLUMN user_id;
+ALTER TABLE email_verifications RENAME COLUMN new_user_id TO user_id;
+ALTER TABLE email_verifications ALTER COLUMN user_id SET NOT NULL;
+CREATE INDEX idx_email_verifications_user_id ON email_verifications (user_id);
+
+ALTER TABLE password_resets ADD COLUMN new_user_id UUID;
+UPDATE password_resets t SET new_user_id = u.new_id FROM users u WHERE u.id = t.user_id;
+DELETE FROM password_resets WHERE new_user_id IS NULL;
+ALTER TABLE password_resets DROP COLUMN user_id;
+ALTER TABLE password_resets RENAME COLUMN new_user_id TO user_id;
+ALTER TABLE password_resets ALTER COLUMN user_id SET NOT NULL;
+CREATE INDEX idx_password_resets_user_id ON password_resets (user_id);
+
+ALTER TABLE sessions ADD COLUMN new_user_id UUID;
+UPDATE sessions t SET new_user_id = u.new_id FROM users u WHERE u.id = t.user_id;
+DELETE FROM sessions WHERE new_user_id IS NULL;
+ALTER TABLE sessions DROP COLUMN user_id;
+ALTER TABLE sessions RENAME COLUMN new_user_id TO user_id;
+ALTER TABLE sessions ALTER COLUMN user_id SET NOT NULL;
+CREATE INDEX idx_sessions_user_id ON sessions (user_id);
+
+-- Dropping the column also drops its primary key and sequence
+ALTER TABLE users DROP COLUMN id;
+ALTER TABLE users RENAME COLUMN new_id TO id;
+ALTER TABLE users ALTER COLUMN id SET NOT NULL;
+ALTER TABLE users ADD PRIMARY KEY (id);
@@ -0,0 +1,66 @@
+-- Renumber users in ID (signup) order. The original numbers are not kept,
+-- so IDs handed out before the upgrade are not restored.
+CREATE TABLE user_id_map AS
+SELECT id AS new_id, row_number() OVER (ORDER BY id) AS old_id FROM users;
+
+CREATE TABLE users_old (
+    id             INTEGER  PRIMARY KEY AUTOINCREMENT,
+    email          TEXT     NOT NULL,
+    password_hash  TEXT     NOT NULL,
+    role           TEXT     NOT NULL DEFAULT 'user',
+    email_verified BOOLEAN  NOT NULL DEFAULT FALSE,
+    active         BOOLEAN  NOT NULL DEFAULT TRUE,
+    created_at     DATETIME,
+    updated_at     DATETIME,
+    version        INTEGER  NOT NULL DEFAULT 1
+);
+INSERT INTO users_old (id, email, password_hash, role, email_verified, active, created_at, updated_at, version)
+SELECT m.old_id, u.email, u.password_hash, u.role, u.email_verified, u.active, u.created_at, u.updated_at, u.version
+FROM users u JOIN user_id_map m ON m.new_id = u.id;
+DROP TABLE users;
+ALTER TABLE users_old RENAME TO users;
+CREATE UNIQUE INDEX idx_users_email ON users (email);
+
+CREATE TABLE email_verifications_old (
+    token      TEXT PRIMARY KEY,
+    user_id    INTEGER  NOT NULL,
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME
+);
+INSERT INTO email_verifications_old (token, user_id, expires_at, created_at)
+SELECT t.token, m.old_id, t.expires_at, t.created_at
+FROM email_verifications t JOIN user_id_map m ON m.new_id = t.user_id;
+DROP TABLE email_verifications;
+ALTER TABLE email_verifications_old RENAME TO email_verifications;
+CREATE INDEX idx_email_verifications_user_id ON email_verifications (user_id);
+CREATE INDEX idx_email_verifications_expires_at ON email_verifications (expires_at);
+
+CREATE TABLE password_resets_old (
+    token      TEXT PRIMARY KEY,
+    user_id    INTEGER  NOT NULL,
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME
+);
+INSERT INTO password_resets_old (token, user_id, expires_at, created_at)
+SELECT t.token, m.old_id, t.expires_at, t.created_at
+FROM password_resets t JOIN user_id_map m ON m.new_id = t.user_id;
+DROP TABLE password_resets;
+ALTER TABLE password_resets_old RENAME TO password_resets;
+CREATE INDEX idx_password_resets_user_id ON password_resets (user_id);
+CREATE INDEX idx_password_resets_expires_at ON password_resets (expires_at);
+
+CREATE TABLE sessions_old (
+    id         TEXT PRIMARY KEY,
+    user_id    INTEGER  NOT NULL,
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME NOT NULL
+);
+INSERT INTO sessions_old (id, user_id, expires_at, created_at)
+SELECT s.id, m.old_id, s.expires_at, s.created_at
+FROM sessions s JOIN user_id_map m ON m.new_id = s.user_id;
+DROP TABLE sessions;
+ALTER TABLE sessions_old RENAME TO sessions;
+CREATE INDEX idx_sessions_user_id ON sessions (user_id);
+CREATE INDEX idx_sessions_expires_at ON sessions (expires_at);
+
+DROP TABLE user_id_map;
@@ -0,0 +1,75 @@
+-- Replace sequential user IDs with UUIDv7. Existing users get an ID whose
+-- timestamp is their signup time, so IDs still sort in signup order.
+-- SQLite cannot change a column type in place, so every table is rebuilt.
+CREATE TABLE user_id_map AS
+SELECT old_id,
+       lower(substr(ts, 1, 8) || '-' || substr(ts, 9, 4) || '-7' || substr(rnd, 1, 3) || '-' ||
+             substr('89ab', 1 + (random() & 3), 1) || substr(rnd, 4, 3) || '-' || substr(rnd, 7, 12)) AS new_id
+FROM (
+    SELECT id AS old_id,
+           printf('%012x', CAST((julianday(COALESCE(created_at, 'now')) - 2440587.5) * 86400000 AS INTEGER)) AS ts,
+           hex(randomblob(9)) AS rnd
+    FROM users
+);
+
+CREATE TABLE users_new (
+    id             TEXT     PRIMARY KEY,
+    email          TEXT     NOT NULL,
+    password_hash  TEXT     NOT NULL,
+    role           TEXT     NOT NULL DEFAULT 'user',
+    email_verified BOOLEAN  NOT NULL DEFAULT FALSE,
+    active         BOOLEAN  NOT NULL DEFAULT TRUE,
+    created_at     DATETIME,
+    updated_at     DATETIME,
+    version        INTEGER  NOT NULL DEFAULT 1
+);
+INSERT INTO users_new (id, email, password_hash, role, email_verified, active, created_at, updated_at, version)
+SELECT m.new_id, u.email, u.password_hash, u.role, u.email_verified, u.active, u.created_at, u.updated_at, u.version
+FROM users u JOIN user_id_map m ON m.old_id = u.id;
+DROP TABLE users;
+ALTER TABLE users_new RENAME TO users;
+CREATE UNIQUE INDEX idx_users_email ON users (email);
+
+CREATE TABLE email_verifications_new (
+    token      TEXT PRIMARY KEY,
+    user_id    TEXT     NOT NULL,
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME
+);
+INSERT INTO email_verifications_new (token, user_id, expires_at, created_at)
+SELECT t.token, m.new_id, t.expires_at, t.created_at
+FROM email_verifications t JOIN user_id_map m ON m.old_id = t.user_id;
+DROP TABLE email_verifications;
+ALTER TABLE email_verifications_new RENAME TO email_verifications;
+CREATE INDEX idx_email_verifications_user_id ON email_verifications (user_id);
+CREATE INDEX idx_email_verifications_expires_at ON email_verifications (expires_at);
+
+CREATE TABLE password_resets_new (
+    token      TEXT PRIMARY KEY,
+    user_id    TEXT     NOT NULL,
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME
+);
+INSERT INTO password_resets_new (token, user_id, expires_at, created_at)
+SELECT t.token, m.new_id, t.expires_at, t.created_at
+FROM password_resets t JOIN user_id_map m ON m.old_id = t.user_id;
+DROP TABLE password_resets;
+ALTER TABLE password_resets_new RENAME TO password_resets;
+CREATE INDEX idx_password_resets_user_id ON password_resets (user_id);
+CREATE INDEX idx_password_resets_expires_at ON password_resets (expires_at);
+
+CREATE TABLE sessions_new (
+    id         TEXT PRIMARY KEY,
+    user_id    TEXT     NOT NULL,
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME NOT NULL
+);
+INSERT INTO sessions_new (id, user_id, expires_at, created_at)
+SELECT s.id, m.new_id, s.expires_at, s.created_at
+FROM sessions s JOIN user_id_map m ON m.old_id = s.user_id;
+DROP TABLE sessions;
+ALTER TABLE sessions_new RENAME TO sessions;
+CREATE INDEX idx_sessions_user_id ON sessions (user_id);
+CREATE INDEX idx_sessions_expires_at ON sessions (expires_at);
+
+DROP TABLE user_id_map;
@@ -0,0 +1,8 @@
+-- Emails are now encrypted by the application, so uniqueness moves to a
+-- keyed blind index. Existing rows keep their plaintext email with a NULL
+-- index until the re-encryption job rewrites them. idx_users_email stays
+-- until a follow-up migration: it keeps plaintext rows unique and serves the
+-- repository's check against them. There is no down script: SQL cannot
+-- decrypt the emails again.
+ALTER TABLE users ADD COLUMN email_index TEXT;
+CREATE UNIQUE INDEX idx_users_email_index ON users (email_index);
@@ -0,0 +1,3 @@
+-- Sessions remember the client address they were signed in from; sessions
+-- created before this migration have none.
+ALTER TABLE sessions ADD COLUMN client_ip TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,104 @@
+package persistence
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/junghwan16/test-server/internal/identity/domain/session"
+	"github.com/junghwan16/test-server/internal/identity/domain/user"
+	"github.com/junghwan16/test-server/internal/identity/domain/verification"
+)
+
+func TestMigration_UUIDUserIDs(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, newDB func(t *testing.T) *gorm.DB) {
+		// Given: 순번 ID 스키마에 저장된 사용자, 토큰, 세션
+		ctx := t.Context()
+		db := newDB(t)
+		migrateTestDBTo(t, db, 3)
+		migrator := migratorThrough(t, db, 4)
+
+		signup := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+		expires := time.Now().Add(time.Hour).UTC()
+		for _, stmt := range []struct {
+			sql  string
+			args []any
+		}{
+			{"INSERT INTO users (id, email, password_hash, created_at, updated_at) VALUES (1, 'old@example.com', 'hash', ?, ?)", []any{signup, signup}},
+			{"INSERT INTO users (id, email, password_hash, created_at, updated_at) VALUES (2, 'new@example.com', 'hash', ?, ?)", []any{signup.Add(time.Hour), signup.Add(time.Hour)}},
+			{"INSERT INTO email_verifications (token, user_id, expires_at, created_at) VALUES ('verify-1', 1, ?, ?)", []any{expires, signup}},
+			{"INSERT INTO email_verifications (token, user_id, expires_at, created_at) VALUES ('orphan', 99, ?, ?)", []any{expires, signup}},
+			{"INSERT INTO password_resets (token, user_id, expires_at, created_at) VALUES ('reset-2', 2, ?, ?)", []any{expires, signup}},
+			{"INSERT INTO sessions (id, user_id, expires_at, created_at) VALUES ('session-1', 1, ?, ?)", []any{expires, signup}},
+		} {
+			if err := db.Exec(stmt.sql, stmt.args...).Error; err != nil {
+				t.Fatalf("failed to seed legacy rows: %v", err)
+			}
+		}
+
+		// When: UUID 마이그레이션 적용
+		if _, err := migrator.Up(ctx); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		// Then: 가입 시각을 담은 UUIDv7 로 바뀌고 토큰과 세션도 따라옴
+		older, newer := userIDByEmail(t, db, "old@example.com"), userIDByEmail(t, db, "new@example.com")
+
+		parsed := uuid.MustParse(older.Value())
+		if parsed.Version() != 7 || parsed.Variant() != uuid.RFC4122 {
+			t.Errorf("expected RFC 4122 UUIDv7, got version %d variant %v", parsed.Version(), parsed.Variant())
+		}
+		ms := int64(binary.BigEndian.Uint64(append([]byte{0, 0}, parsed[:6]...)))
+		if got := time.UnixMilli(ms).UTC(); !got.Equal(signup) {
+			t.Errorf("expected ID timestamp %v, got %v", signup, got)
+		}
+		if older.Value() >= newer.Value() {
+			t.Errorf("expected IDs in signup order, got %s >= %s", older, newer)
+		}
+
+		verif, err := NewEmailVerificationRepository(db).FindByToken(ctx, "verify-1")
+		if err != nil || !verif.UserID().Equals(older) {
+			t.Errorf("expected verification for %s, got %v (err %v)", older, verif, err)
+		}
+		reset, err := NewPasswordResetRepository(db).FindByToken(ctx, "reset-2")
+		if err != nil || !reset.UserID().Equals(newer) {
+			t.Errorf("expected reset for %s, got %v (err %v)", newer, reset, err)
+		}
+		sessionID, _ := session.NewSessionID("session-1")
+		sess, err := NewSessionRepository(db).FindByID(ctx, sessionID)
+		if err != nil || !sess.UserID().Equals(older) {
+			t.Errorf("expected session for %s, got %v (err %v)", older, sess, err)
+		}
+		if _, err := NewEmailVerificationRepository(db).FindByToken(ctx, "orphan"); !errors.Is(err, verification.ErrTokenNotFound) {
+			t.Errorf("expected orphaned token to be dropped, got %v", err)
+		}
+
+		// 되돌린 뒤 다시 적용해도 동작함
+		if _, err := migrator.Down(ctx, 1); err != nil {
+			t.Fatalf("expected down to succeed, got %v", err)
+		}
+		if _, err := migrator.Up(ctx); err != nil {
+			t.Fatalf("expected re-apply to succeed, got %v", err)
+		}
+		userIDByEmail(t, db, "new@example.com")
+	})
+}
+
+// userIDByEmail reads a user ID straight from the table, bypassing the repository
+func userIDByEmail(t *testing.T, db *gorm.DB, email string) user.UserID {
+	t.Helper()
+
+	var id string
+	if err := db.Raw("SELECT id FROM users WHERE email = ?", email).Scan(&id).Error; err != nil {
+		t.Fatalf("failed to read user %s: %v", email, err)
+	}
+	userID, err := user.NewUserID(id)
+	if err != nil {
+		t.Fatalf("expected UUID for %s, got %q", email, id)
+	}
+	return userID
+}
@@ -12,27 +12,38 @@ import (
 	"github.com/junghwan16/test-server/internal/identity/domain/user"
 )
 
-// RedisSessionRepository implements session.Repository using Redis
+// RedisSessionRepository implements session.Repository using Redis.
+//
+// Each session lives at session:{<user id>}:<session id> and each user's
+// session IDs are indexed at user_sessions:{<user id>}. The hash tag puts a
+// user's sessions and index in one Redis Cluster slot, so they are written
+// and revoked together in MULTI transactions. Lookups only know the session
+// ID, so session:<session id> names its user.
 type RedisSessionRepository struct {
-	client *redis.Client
+	client redis.UniversalClient
 }
 
-// NewRedisSessionRepository creates a new Redis-based SessionRepository
-func NewRedisSessionRepository(client *redis.Client) *RedisSessionRepository {
+// maxWatchRetries bounds how often DeleteByUserID retries when a session is
+// saved for the user while it runs
+const maxWatchRetries = 5
+
+// NewRedisSessionRepository creates a new Redis-based SessionRepository.
+// client may be a single node, Sentinel or Cluster client.
+func NewRedisSessionRepository(client redis.UniversalClient) *RedisSessionRepository {
 	return &RedisSessionRepository{client: client}
 }
 
 type redisSessionData struct {
-	UserID    uint      `json:"user_id"`
+	UserID    string    `json:"user_id"`
+	ClientIP  string    `json:"client_ip,omitempty"`
 	ExpiresAt time.Time `json:"expires_at"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
-func (r *RedisSessionRepository) Save(s *session.Session) error {
-	ctx := context.Background()
-
+func (r *RedisSessionRepository) Save(ctx context.Context, s *session.Session) error {
 	data := redisSessionData{
 		UserID:    s.UserID().Value(),
+		ClientIP:  s.ClientIP(),
 		ExpiresAt: s.ExpiresAt(),
 		CreatedAt: s.CreatedAt(),
 	}
@@ -42,82 +53,161 @@ func (r *RedisSessionRepository) Save(s *session.Session) error {
 		return err
 	}
 
-	key := sessionKey(s.ID().Value())
 	ttl := time.Until(s.ExpiresAt())
 	if ttl <= 0 {
 		return errors.New("session already expired")
 	}
 
-	return r.client.Set(ctx, key, jsonData, ttl).Err()
+	// The session and its index entry are written atomically; the index only
+	// ever lengthens its TTL, keeping it alive as long as the newest session
+	id := s.ID().Value()
+	indexKey := userSessionsKey(data.UserID)
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, sessionKey(data.UserID, id), jsonData, ttl)
+		pipe.SAdd(ctx, indexKey, id)
+		pipe.ExpireNX(ctx, indexKey, ttl)
+		pipe.ExpireGT(ctx, indexKey, ttl)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Written last: a session is never reachable before it is indexed, so
+	// DeleteByUserID always finds it
+	return r.client.Set(ctx, sessionOwnerKey(id), data.UserID, ttl).Err()
 }
 
-func (r *RedisSessionRepository) FindByID(id session.SessionID) (*session.Session, error) {
-	ctx := context.Background()
+func (r *RedisSessionRepository) FindByID(ctx context.Context, id session.SessionID) (*session.Session, error) {
+	ownerKey := sessionOwnerKey(id.Value())
+	owner, err := r.client.Get(ctx, ownerKey).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, session.ErrSessionNotFound
+		}
+		return nil, err
+	}
+
+	// Sessions written before user IDs became UUIDs hold their data here,
+	// with a numeric ID; drop them so the user simply signs in again
+	userID, err := user.NewUserID(owner)
+	if err != nil {
+		r.client.Del(ctx, ownerKey)
+		return nil, session.ErrSessionNotFound
+	}
 
-	key := sessionKey(id.Value())
-	jsonData, err := r.client.Get(ctx, key).Result()
+	jsonData, err := r.client.Get(ctx, sessionKey(owner, id.Value())).Result()
 	if err != nil {
 		if errors.Is(err, redis.Nil) {
-			return nil, errors.New("session not found or expired")
+			// Revoked by DeleteByUserID, which leaves the owner key behind
+			r.client.Del(ctx, ownerKey)
+			return nil, session.ErrSessionNotFound
 		}
 		return nil, err
 	}
 
 	var data redisSessionData
 	if err := json.Unmarshal([]byte(jsonData), &data); err != nil {
-		return nil, err
+		r.delete(ctx, id.Value(), owner)
+		return nil, session.ErrSessionNotFound
 	}
 
 	// Check if expired (double check, though Redis should auto-expire)
 	if time.Now().After(data.ExpiresAt) {
-		r.client.Del(ctx, key) // cleanup
-		return nil, errors.New("session not found or expired")
+		r.delete(ctx, id.Value(), owner) // cleanup
+		return nil, session.ErrSessionNotFound
 	}
 
-	userID, _ := user.NewUserID(data.UserID)
-	return session.ReconstructSession(id, userID, data.ExpiresAt, data.CreatedAt), nil
+	return session.ReconstructSession(id, userID, data.ClientIP, data.ExpiresAt, data.CreatedAt), nil
 }
 
-func (r *RedisSessionRepository) Delete(id session.SessionID) error {
-	ctx := context.Background()
-	key := sessionKey(id.Value())
-	return r.client.Del(ctx, key).Err()
+func (r *RedisSessionRepository) Delete(ctx context.Context, id session.SessionID) error {
+	ownerKey := sessionOwnerKey(id.Value())
+	owner, err := r.client.Get(ctx, ownerKey).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil
+		}
+		return err
+	}
+	if _, err := user.NewUserID(owner); err != nil {
+		return r.client.Del(ctx, ownerKey).Err()
+	}
+	return r.delete(ctx, id.Value(), owner)
 }
 
 // DeleteExpired is a no-op for Redis since it handles expiration automatically
-func (r *RedisSessionRepository) DeleteExpired() error {
-	// Redis automatically removes expired keys
+func (r *RedisSessionRepository) DeleteExpired(ctx context.Context) error {
+	// Redis automatically removes expired keys; index entries for them are
+	// dropped on the next DeleteByUserID or when the index itself expires
 	return nil
 }
 
-// DeleteByUserID removes all sessions for a given user
-func (r *RedisSessionRepository) DeleteByUserID(userID user.UserID) error {
-	ctx := context.Background()
-
-	// Use a scan to find all session keys
-	pattern := "session:*"
-	iter := r.client.Scan(ctx, 0, pattern, 0).Iterator()
-
-	for iter.Next(ctx) {
-		key := iter.Val()
-		jsonData, err := r.client.Get(ctx, key).Result()
-		if err != nil {
-			continue
+// DeleteByUserID removes all sessions for a given user. The index is watched,
+// so a session saved meanwhile makes the transaction retry rather than
+// survive unindexed.
+func (r *RedisSessionRepository) DeleteByUserID(ctx context.Context, userID user.UserID) error {
+	uid := userID.Value()
+	indexKey := userSessionsKey(uid)
+
+	var ids []string
+	revoke := func(tx *redis.Tx) error {
+		var err error
+		if ids, err = tx.SMembers(ctx, indexKey).Result(); err != nil {
+			return err
 		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, id := range ids {
+				pipe.Del(ctx, sessionKey(uid, id))
+			}
+			pipe.Del(ctx, indexKey)
+			return nil
+		})
+		return err
+	}
 
-		var data redisSessionData
-		if err := json.Unmarshal([]byte(jsonData), &data); err != nil {
-			continue
+	var err error
+	for range maxWatchRetries {
+		if err = r.client.Watch(ctx, revoke, indexKey); !errors.Is(err, redis.TxFailedErr) {
+			break
 		}
+	}
+	if err != nil {
+		return err
+	}
 
-		if data.UserID == userID.Value() {
-			r.client.Del(ctx, key)
+	// Owner keys live in other slots. The sessions are already gone, so
+	// leftovers only cost a lookup and expire with their TTL.
+	_, err = r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, id := range ids {
+			pipe.Del(ctx, sessionOwnerKey(id))
 		}
+		return nil
+	})
+	return err
+}
+
+// delete removes a session, its entry in the user's index and its owner key
+func (r *RedisSessionRepository) delete(ctx context.Context, id, userID string) error {
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, sessionKey(userID, id))
+		pipe.SRem(ctx, userSessionsKey(userID), id)
+		return nil
+	})
+	if err != nil {
+		return err
 	}
+	return r.client.Del(ctx, sessionOwnerKey(id)).Err()
+}
 
-	return iter.Err()
+func sessionKey(userID, id string) string {
+	return "session:{" + userID + "}:" + id
 }
 
-func sessionKey(id string) string {
+func sessionOwnerKey(id string) string {
 	return "session:" + id
 }
+
+func userSessionsKey(userID string) string {
+	return "user_sessions:{" + userID + "}"
+}
@@ -0,0 +1,81 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/junghwan16/test-server/internal/identity/domain/session"
+	"github.com/junghwan16/test-server/internal/identity/domain/user"
+)
+
+// SessionModel is the GORM model for Session aggregate
+type SessionModel struct {
+	ID        string    `gorm:"primarykey"`
+	UserID    string    `gorm:"index;not null"`
+	ClientIP  string    `gorm:"not null;default:''"`
+	ExpiresAt time.Time `gorm:"index;not null"`
+	CreatedAt time.Time `gorm:"not null"`
+}
+
+// TableName specifies the table name
+func (SessionModel) TableName() string {
+	return "sessions"
+}
+
+// SessionRepository implements session.Repository using GORM, keeping
+// sessions in Postgres (or SQLite) alongside users so they survive a Redis flush
+type SessionRepository struct {
+	db *gorm.DB
+}
+
+// NewSessionRepository creates a new database-backed SessionRepository
+func NewSessionRepository(db *gorm.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+func (r *SessionRepository) Save(ctx context.Context, s *session.Session) error {
+	if s.IsExpired() {
+		return errors.New("session already expired")
+	}
+
+	model := SessionModel{
+		ID:        s.ID().Value(),
+		UserID:    s.UserID().Value(),
+		ClientIP:  s.ClientIP(),
+		ExpiresAt: s.ExpiresAt().UTC(),
+		CreatedAt: s.CreatedAt().UTC(),
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(&model).Error
+}
+
+func (r *SessionRepository) FindByID(ctx context.Context, id session.SessionID) (*session.Session, error) {
+	var model SessionModel
+	err := r.db.WithContext(ctx).Where("id = ? AND expires_at > ?", id.Value(), time.Now().UTC()).First(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, session.ErrSessionNotFound
+		}
+		return nil, err
+	}
+
+	userID, _ := user.NewUserID(model.UserID)
+	return session.ReconstructSession(id, userID, model.ClientIP, model.ExpiresAt, model.CreatedAt), nil
+}
+
+func (r *SessionRepository) Delete(ctx context.Context, id session.SessionID) error {
+	return r.db.WithContext(ctx).Delete(&SessionModel{}, "id = ?", id.Value()).Error
+}
+
+// DeleteExpired removes sessions past their expiry; the scheduler calls it periodically
+func (r *SessionRepository) DeleteExpired(ctx context.Context) error {
+	return r.db.WithContext(ctx).Delete(&SessionModel{}, "expires_at <= ?", time.Now().UTC()).Error
+}
+
+// DeleteByUserID removes all sessions for a given user
+func (r *SessionRepository) DeleteByUserID(ctx context.Context, userID user.UserID) error {
+	return r.db.WithContext(ctx).Delete(&SessionModel{}, "user_id = ?", userID.Value()).Error
+}
@@ -0,0 +1,114 @@
+package persistence
+
+import (
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/junghwan16/test-server/internal/identity/domain/session"
+	"github.com/junghwan16/test-server/internal/identity/domain/session/sessiontest"
+	"github.com/junghwan16/test-server/internal/identity/domain/user"
+)
+
+func TestSessionRepository(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, newDB func(t *testing.T) *gorm.DB) {
+		sessiontest.TestRepository(t, func(t *testing.T) session.Repository {
+			return NewSessionRepository(newDB(t))
+		})
+
+		t.Run("만료 세션 행 삭제", func(t *testing.T) {
+			// Given: 이미 만료된 세션 행
+			ctx := t.Context()
+			db := newDB(t)
+			repo := NewSessionRepository(db)
+			expired := SessionModel{
+				ID:        session.GenerateSessionID().Value(),
+				UserID:    user.GenerateUserID().Value(),
+				ExpiresAt: time.Now().Add(-time.Minute).UTC(),
+				CreatedAt: time.Now().Add(-time.Hour).UTC(),
+			}
+			db.Create(&expired)
+
+			// When: 만료 세션 정리
+			if err := repo.DeleteExpired(ctx); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			// Then: 행이 삭제됨
+			var count int64
+			db.Model(&SessionModel{}).Where("id = ?", expired.ID).Count(&count)
+			if count != 0 {
+				t.Errorf("expected expired session row to be deleted, got %d", count)
+			}
+		})
+	})
+}
+
+func TestRedisSessionRepository(t *testing.T) {
+	client := newRedisTestClient(t)
+
+	sessiontest.TestRepository(t, func(t *testing.T) session.Repository {
+		return NewRedisSessionRepository(client)
+	})
+
+	t.Run("사용자 인덱스", func(t *testing.T) {
+		// Given: 한 사용자의 세션 두 개
+		repo := NewRedisSessionRepository(client)
+		ctx := t.Context()
+		userID := user.GenerateUserID()
+		first := session.NewSession(session.GenerateSessionID(), userID, "", 60)
+		second := session.NewSession(session.GenerateSessionID(), userID, "", 3600)
+		repo.Save(ctx, first)
+		repo.Save(ctx, second)
+
+		// When: 하나를 로그아웃
+		if err := repo.Delete(ctx, first.ID()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		// Then: 인덱스에는 남은 세션만 있고 가장 늦은 세션만큼 유지됨
+		indexKey := "user_sessions:{" + userID.Value() + "}"
+		members, _ := client.SMembers(ctx, indexKey).Result()
+		if len(members) != 1 || members[0] != second.ID().Value() {
+			t.Errorf("expected index to hold only %s, got %v", second.ID().Value(), members)
+		}
+		if ttl := client.TTL(ctx, indexKey).Val(); ttl <= time.Minute {
+			t.Errorf("expected index TTL above a minute, got %v", ttl)
+		}
+		if n := client.Exists(ctx, "session:{"+userID.Value()+"}:"+second.ID().Value()).Val(); n != 1 {
+			t.Error("expected session stored under the user's hash tag")
+		}
+		if owner := client.Get(ctx, "session:"+second.ID().Value()).Val(); owner != userID.Value() {
+			t.Errorf("expected session:<id> to name user %s, got %q", userID.Value(), owner)
+		}
+		if n := client.Exists(ctx, "session:"+first.ID().Value()).Val(); n != 0 {
+			t.Error("expected the deleted session's owner key to be removed")
+		}
+	})
+
+	t.Run("일괄 로그아웃", func(t *testing.T) {
+		// Given: 한 사용자의 세션 두 개
+		repo := NewRedisSessionRepository(client)
+		ctx := t.Context()
+		userID := user.GenerateUserID()
+		first := session.NewSession(session.GenerateSessionID(), userID, "", 3600)
+		second := session.NewSession(session.GenerateSessionID(), userID, "", 3600)
+		repo.Save(ctx, first)
+		repo.Save(ctx, second)
+
+		// When: 사용자의 모든 세션 삭제
+		if err := repo.DeleteByUserID(ctx, userID); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		// Then: 세션, 인덱스, 소유자 키가 모두 남지 않음
+		keys, _ := client.Keys(ctx, "*"+userID.Value()+"*").Result()
+		for _, s := range []*session.Session{first, second} {
+			keys = append(keys, client.Keys(ctx, "session:"+s.ID().Value()).Val()...)
+		}
+		if len(keys) != 0 {
+			t.Errorf("expected no keys left, got %v", keys)
+		}
+	})
+}
@@ -0,0 +1,21 @@
+package persistence
+
+import (
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// OpenSQLite opens the SQLite database file at path for single-node deployments.
+// Writers take the lock when a transaction begins and wait for each other
+// instead of failing with SQLITE_BUSY. SQLite stores times as text, which is
+// why repositories write and compare timestamps in UTC.
+func OpenSQLite(path string, config *gorm.Config) (*gorm.DB, error) {
+	dsn := "file:" + path +
+		"?_pragma=busy_timeout(5000)" +
+		"&_pragma=journal_mode(WAL)" +
+		"&_pragma=foreign_keys(1)" +
+		"&_txlock=immediate" +
+		"&_time_format=sqlite"
+
+	return gorm.Open(sqlite.Open(dsn), config)
+}
@@ -0,0 +1,47 @@
+package persistence
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/junghwan16/test-server/internal/identity/application"
+	"github.com/junghwan16/test-server/internal/shared/domain"
+	"github.com/junghwan16/test-server/internal/shared/infrastructure/keyring"
+)
+
+// UnitOfWork implements application.UnitOfWork with a GORM transaction
+type UnitOfWork struct {
+	db       *gorm.DB
+	eventBus domain.EventBus
+	keys     *keyring.Keyring
+}
+
+// NewUnitOfWork creates a new UnitOfWork
+func NewUnitOfWork(db *gorm.DB, eventBus domain.EventBus, keys *keyring.Keyring) *UnitOfWork {
+	return &UnitOfWork{
+		db:       db,
+		eventBus: eventBus,
+		keys:     keys,
+	}
+}
+
+// Do runs fn in a transaction and releases collected domain events after commit
+func (u *UnitOfWork) Do(ctx context.Context, fn func(repos application.Repositories) error) error {
+	events := &domain.EventCollector{}
+
+	err := u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(application.Repositories{
+			Users:              NewUserRepository(tx, events, u.keys),
+			EmailVerifications: NewEmailVerificationRepository(tx),
+			PasswordResets:     NewPasswordResetRepository(tx),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	// Subscribers are best-effort, as with UserRepository.Save
+	_ = events.Release(ctx, u.eventBus)
+	return nil
+}
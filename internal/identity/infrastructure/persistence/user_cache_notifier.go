@@ -0,0 +1,125 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+
+	"github.com/junghwan16/test-server/internal/identity/domain/user"
+)
+
+// userCacheChannel is the Postgres NOTIFY channel carrying invalidated user IDs
+const userCacheChannel = "identity_user_cache"
+
+// PostgresUserCacheNotifier fans user cache invalidations out to every
+// replica through Postgres LISTEN/NOTIFY
+type PostgresUserCacheNotifier struct {
+	db     *gorm.DB
+	dsn    string
+	logger *slog.Logger
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewPostgresUserCacheNotifier creates a notifier; dsn is used for the
+// dedicated LISTEN connection, which cannot come from the shared pool
+func NewPostgresUserCacheNotifier(db *gorm.DB, dsn string, logger *slog.Logger) *PostgresUserCacheNotifier {
+	return &PostgresUserCacheNotifier{db: db, dsn: dsn, logger: logger}
+}
+
+// Notify tells every listening replica to drop the user
+func (n *PostgresUserCacheNotifier) Notify(ctx context.Context, id user.UserID) error {
+	return n.db.WithContext(ctx).Exec("SELECT pg_notify(?, ?)", userCacheChannel, id.Value()).Error
+}
+
+// Start listens for invalidations and applies them to the local cache
+func (n *PostgresUserCacheNotifier) Start(ctx context.Context, cache *CachedUserRepository) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.cancel != nil {
+		return errors.New("user cache notifier already started")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	n.cancel = cancel
+
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		n.listen(ctx, cache)
+	}()
+
+	return nil
+}
+
+// Close stops listening
+func (n *PostgresUserCacheNotifier) Close() {
+	n.mu.Lock()
+	cancel := n.cancel
+	n.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	n.wg.Wait()
+}
+
+func (n *PostgresUserCacheNotifier) listen(ctx context.Context, cache *CachedUserRepository) {
+	reconnect := false
+
+	for ctx.Err() == nil {
+		conn, err := pgx.Connect(ctx, n.dsn)
+		if err == nil {
+			_, err = conn.Exec(ctx, "LISTEN "+userCacheChannel)
+		}
+		if err != nil {
+			if ctx.Err() == nil {
+				n.logger.Error("user cache: listen failed", "error", err)
+			}
+			if conn != nil {
+				conn.Close(context.Background())
+			}
+			waitOrDone(ctx, time.Second)
+			continue
+		}
+
+		// Invalidations sent while disconnected were missed
+		if reconnect {
+			cache.PurgeLocal()
+		}
+		reconnect = true
+
+		for {
+			notification, err := conn.WaitForNotification(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					n.logger.Warn("user cache: listener disconnected", "error", err)
+				}
+				break
+			}
+
+			if userID, err := user.NewUserID(notification.Payload); err == nil {
+				cache.InvalidateLocal(userID)
+			}
+		}
+
+		conn.Close(context.Background())
+	}
+}
+
+func waitOrDone(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+	case <-t.C:
+	}
+}
@@ -1,25 +1,35 @@
 package persistence
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
 
 	"github.com/junghwan16/test-server/internal/identity/domain/user"
 	"github.com/junghwan16/test-server/internal/shared/domain"
+	"github.com/junghwan16/test-server/internal/shared/infrastructure/keyring"
 )
 
+// reencryptBatchSize bounds the rows read per query by Reencrypt
+const reencryptBatchSize = 100
+
 // UserModel is the GORM model for User aggregate
 type UserModel struct {
-	ID            uint   `gorm:"primarykey"`
-	Email         string `gorm:"uniqueIndex;not null"`
-	PasswordHash  string `gorm:"not null"`
-	Role          string `gorm:"not null;default:user"`
-	EmailVerified bool   `gorm:"not null;default:false"`
-	Active        bool   `gorm:"not null;default:true"`
+	ID    string `gorm:"primarykey"`
+	Email string `gorm:"not null"` // encrypted; plaintext only on rows from before encryption
+	// EmailIndex is the blind index of the email; NULL marks a row whose email
+	// is still plaintext and waits for Reencrypt
+	EmailIndex    *string `gorm:"uniqueIndex"`
+	PasswordHash  string  `gorm:"not null"`
+	Role          string  `gorm:"not null;default:user"`
+	EmailVerified bool    `gorm:"not null;default:false"`
+	Active        bool    `gorm:"not null;default:true"`
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
+	Version       int64 `gorm:"not null;default:1"`
 }
 
 // TableName specifies the table name
@@ -27,47 +37,73 @@ func (UserModel) TableName() string {
 	return "users"
 }
 
-// UserRepository implements user.Repository using GORM
+// UserRepository implements user.Repository using GORM. Emails are encrypted
+// with keys and found through their blind index.
 type UserRepository struct {
 	db       *gorm.DB
 	eventBus domain.EventBus
+	keys     *keyring.Keyring
 }
 
 // NewUserRepository creates a new UserRepository
-func NewUserRepository(db *gorm.DB, eventBus domain.EventBus) *UserRepository {
+func NewUserRepository(db *gorm.DB, eventBus domain.EventBus, keys *keyring.Keyring) *UserRepository {
 	return &UserRepository{
 		db:       db,
 		eventBus: eventBus,
+		keys:     keys,
 	}
 }
 
-// NextID generates a new UserID
-func (r *UserRepository) NextID() user.UserID {
-	// In auto-increment scenario, return zero and let DB generate
-	return user.UserID{}
+// NextID generates a new UserID. IDs are UUIDv7, so new rows append to the
+// end of the primary key index much like a sequence would.
+func (r *UserRepository) NextID(ctx context.Context) user.UserID {
+	return user.GenerateUserID()
 }
 
-func (r *UserRepository) Save(u *user.User) error {
-	model := r.toModel(u)
-
-	var err error
-	if model.ID == 0 {
-		err = r.db.Create(&model).Error
-		if err == nil && !u.ID().IsZero() {
-			// Update aggregate with generated ID
-			// Note: This is a compromise in DDD - ideally ID should be known before persistence
-		}
-	} else {
-		err = r.db.Save(&model).Error
+func (r *UserRepository) Save(ctx context.Context, u *user.User) error {
+	if u.ID().IsZero() {
+		return user.ErrInvalidUserID
 	}
-
+	model, err := r.toModel(u)
 	if err != nil {
 		return err
 	}
+	if err := r.checkLegacyEmail(ctx, u); err != nil {
+		return err
+	}
+
+	if u.Version() == 0 {
+		model.Version = 1
+		if err := r.db.WithContext(ctx).Create(&model).Error; err != nil {
+			return translateUserError(err)
+		}
+	} else {
+		// Compare-and-swap on version so concurrent writers cannot overwrite each other
+		result := r.db.WithContext(ctx).Model(&UserModel{}).
+			Where("id = ? AND version = ?", model.ID, u.Version()).
+			Updates(map[string]any{
+				"email":          model.Email,
+				"email_index":    model.EmailIndex,
+				"password_hash":  model.PasswordHash,
+				"role":           model.Role,
+				"email_verified": model.EmailVerified,
+				"active":         model.Active,
+				"updated_at":     model.UpdatedAt,
+				"version":        gorm.Expr("version + 1"),
+			})
+		if result.Error != nil {
+			return translateUserError(result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return user.ErrConcurrentModification
+		}
+		model.Version = u.Version() + 1
+	}
+	u.SetVersion(model.Version)
 
 	// Publish domain events
 	for _, event := range u.DomainEvents() {
-		_ = r.eventBus.Publish(event)
+		_ = r.eventBus.Publish(ctx, event)
 	}
 	u.ClearEvents()
 
@@ -75,77 +111,228 @@ func (r *UserRepository) Save(u *user.User) error {
 }
 
 // FindByID retrieves a User by ID
-func (r *UserRepository) FindByID(id user.UserID) (*user.User, error) {
+func (r *UserRepository) FindByID(ctx context.Context, id user.UserID) (*user.User, error) {
 	var model UserModel
-	err := r.db.First(&model, id.Value()).Error
+	err := r.db.WithContext(ctx).First(&model, "id = ?", id.Value()).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, user.ErrUserNotFound
 		}
 		return nil, err
 	}
 
-	return r.toDomain(&model), nil
+	return r.toDomain(&model)
 }
 
 // FindByEmail retrieves a User by email
-func (r *UserRepository) FindByEmail(email user.Email) (*user.User, error) {
+func (r *UserRepository) FindByEmail(ctx context.Context, email user.Email) (*user.User, error) {
 	var model UserModel
-	err := r.db.Where("email = ?", email.Value()).First(&model).Error
+	err := r.db.WithContext(ctx).
+		Where("email_index = ? OR (email_index IS NULL AND email = ?)", r.emailIndex(email.Value()), email.Value()).
+		First(&model).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, user.ErrUserNotFound
 		}
 		return nil, err
 	}
 
-	return r.toDomain(&model), nil
+	return r.toDomain(&model)
 }
 
 // FindAll retrieves all users with pagination
-func (r *UserRepository) FindAll(limit, offset int) ([]*user.User, int64, error) {
+func (r *UserRepository) FindAll(ctx context.Context, limit, offset int) ([]*user.User, int64, error) {
 	var models []UserModel
 	var total int64
 
-	if err := r.db.Model(&UserModel{}).Count(&total).Error; err != nil {
+	if err := r.db.WithContext(ctx).Model(&UserModel{}).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
-	err := r.db.Limit(limit).Offset(offset).Order("created_at DESC").Find(&models).Error
+	err := r.db.WithContext(ctx).Limit(limit).Offset(offset).Order("created_at DESC").Find(&models).Error
 	if err != nil {
 		return nil, 0, err
 	}
 
 	users := make([]*user.User, len(models))
 	for i, model := range models {
-		users[i] = r.toDomain(&model)
+		u, err := r.toDomain(&model)
+		if err != nil {
+			return nil, 0, err
+		}
+		users[i] = u
 	}
 
 	return users, total, nil
 }
 
-func (r *UserRepository) Delete(id user.UserID) error {
-	return r.db.Delete(&UserModel{}, id.Value()).Error
+func (r *UserRepository) Delete(ctx context.Context, id user.UserID) error {
+	return r.db.WithContext(ctx).Delete(&UserModel{}, "id = ?", id.Value()).Error
+}
+
+// Reencrypt rewrites emails that are still plaintext or were encrypted with a
+// key other than the primary one, and returns the number of rows rewritten.
+// Run it after rotating keys; rows that fail are skipped and reported.
+func (r *UserRepository) Reencrypt(ctx context.Context) (int, error) {
+	var (
+		rewritten int
+		failed    int
+		firstErr  error
+		after     string
+	)
+
+	// Compared with substr rather than LIKE, which ignores case on SQLite and
+	// would skip key IDs differing from the primary only in case
+	current := r.keys.Primary() + ":"
+
+	for {
+		// Keyset pagination moves past rows that keep failing
+		var models []UserModel
+		err := r.db.WithContext(ctx).
+			Where("id > ? AND (email_index IS NULL OR substr(email, 1, ?) <> ?)", after, len(current), current).
+			Order("id").Limit(reencryptBatchSize).Find(&models).Error
+		if err != nil {
+			return rewritten, err
+		}
+
+		for _, model := range models {
+			after = model.ID
+			ok, err := r.reencryptRow(ctx, &model)
+			if err != nil {
+				if ctx.Err() != nil {
+					return rewritten, ctx.Err()
+				}
+				failed++
+				if firstErr == nil {
+					firstErr = fmt.Errorf("user %s: %w", model.ID, err)
+				}
+				continue
+			}
+			if ok {
+				rewritten++
+			}
+		}
+
+		if len(models) < reencryptBatchSize {
+			break
+		}
+	}
+
+	if failed > 0 {
+		return rewritten, fmt.Errorf("%d user(s) could not be re-encrypted, first: %w", failed, firstErr)
+	}
+	return rewritten, nil
+}
+
+// reencryptRow rewrites one email under the primary key. The update is
+// conditional on the old ciphertext, so a concurrent Save is never undone;
+// version and updated_at are left alone as the user itself did not change.
+func (r *UserRepository) reencryptRow(ctx context.Context, m *UserModel) (bool, error) {
+	plaintext, err := r.decryptEmail(m)
+	if err != nil {
+		return false, err
+	}
+	email, err := r.keys.Encrypt([]byte(plaintext), emailAAD(m.ID))
+	if err != nil {
+		return false, err
+	}
+
+	result := r.db.WithContext(ctx).Model(&UserModel{}).
+		Where("id = ? AND email = ?", m.ID, m.Email).
+		UpdateColumns(map[string]any{
+			"email":       email,
+			"email_index": r.emailIndex(plaintext),
+		})
+	if result.Error != nil {
+		return false, translateUserError(result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// checkLegacyEmail rejects an email another user still holds as plaintext.
+// The blind index only covers rows written since encryption, so until
+// Reencrypt has rewritten every row this check stands in for it. It counts
+// and then writes without a lock, so it is a best-effort check, not a
+// constraint: the unique indexes enforce uniqueness, idx_users_email among
+// plaintext rows and idx_users_email_index among encrypted ones. It holds
+// because plaintext rows are no longer written; a row Reencrypt rewrites
+// meanwhile gains a blind index that the write then conflicts with. A
+// replica still running a build from before encryption could race it.
+func (r *UserRepository) checkLegacyEmail(ctx context.Context, u *user.User) error {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&UserModel{}).
+		Where("email_index IS NULL AND email = ? AND id <> ?", u.Email().Value(), u.ID().Value()).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return user.ErrDuplicateEmail
+	}
+	return nil
+}
+
+// emailAAD binds an encrypted email to its row
+func emailAAD(id string) []byte {
+	return []byte("users.email:" + id)
+}
+
+// emailIndex returns the blind index of a normalized email
+func (r *UserRepository) emailIndex(email string) *string {
+	index := r.keys.BlindIndex([]byte(email))
+	return &index
+}
+
+// decryptEmail returns the plaintext email of a row
+func (r *UserRepository) decryptEmail(m *UserModel) (string, error) {
+	if m.EmailIndex == nil {
+		return m.Email, nil
+	}
+	plaintext, err := r.keys.Decrypt(m.Email, emailAAD(m.ID))
+	if err != nil {
+		return "", fmt.Errorf("decrypt email of user %s: %w", m.ID, err)
+	}
+	return string(plaintext), nil
+}
+
+// translateUserError maps unique violations (requires gorm.Config.TranslateError)
+func translateUserError(err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return user.ErrDuplicateEmail
+	}
+	return err
 }
 
 // Mapping functions
 
-func (r *UserRepository) toModel(u *user.User) UserModel {
+func (r *UserRepository) toModel(u *user.User) (UserModel, error) {
+	email, err := r.keys.Encrypt([]byte(u.Email().Value()), emailAAD(u.ID().Value()))
+	if err != nil {
+		return UserModel{}, err
+	}
+
 	return UserModel{
 		ID:            u.ID().Value(),
-		Email:         u.Email().Value(),
+		Email:         email,
+		EmailIndex:    r.emailIndex(u.Email().Value()),
 		PasswordHash:  u.Password().Hash(),
 		Role:          u.Role().Value(),
 		EmailVerified: u.EmailVerified(),
 		Active:        u.Active(),
-		CreatedAt:     u.CreatedAt(),
-		UpdatedAt:     u.UpdatedAt(),
-	}
+		CreatedAt:     u.CreatedAt().UTC(),
+		UpdatedAt:     u.UpdatedAt().UTC(),
+		Version:       u.Version(),
+	}, nil
 }
 
-func (r *UserRepository) toDomain(m *UserModel) *user.User {
+func (r *UserRepository) toDomain(m *UserModel) (*user.User, error) {
+	plaintext, err := r.decryptEmail(m)
+	if err != nil {
+		return nil, err
+	}
+
 	id, _ := user.NewUserID(m.ID)
-	email, _ := user.NewEmail(m.Email)
+	email, _ := user.NewEmail(plaintext)
 	password := user.NewPasswordFromHash(m.PasswordHash)
 	role, _ := user.NewRole(m.Role)
 
@@ -158,5 +345,6 @@ func (r *UserRepository) toDomain(m *UserModel) *user.User {
 		m.Active,
 		m.CreatedAt,
 		m.UpdatedAt,
-	)
+		m.Version,
+	), nil
 }
@@ -0,0 +1,204 @@
+package persistence
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/junghwan16/test-server/internal/identity/domain/user"
+	"github.com/junghwan16/test-server/internal/identity/domain/user/usertest"
+	"github.com/junghwan16/test-server/internal/shared/domain"
+	"github.com/junghwan16/test-server/internal/shared/infrastructure/keyring"
+)
+
+func TestUserRepository(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, newDB func(t *testing.T) *gorm.DB) {
+		usertest.TestRepository(t, func(t *testing.T) user.Repository {
+			return NewUserRepository(newDB(t), domain.NewSimpleEventBus(), testKeys)
+		})
+	})
+}
+
+func TestUserRepository_Encryption(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, newDB func(t *testing.T) *gorm.DB) {
+		rotated := mustTestKeyring(map[string][]byte{
+			"test-1": bytes.Repeat([]byte{1}, 32),
+			"test-2": bytes.Repeat([]byte{2}, 32),
+		}, "test-2")
+
+		t.Run("이메일은 암호문과 블라인드 인덱스로 저장", func(t *testing.T) {
+			// Given: 저장소
+			ctx := t.Context()
+			db := newDB(t)
+			repo := NewUserRepository(db, domain.NewSimpleEventBus(), testKeys)
+			u, _ := user.NewUser(repo.NextID(ctx), user.MustNewEmail("secret@example.com"), user.NewPasswordFromHash("hash"))
+
+			// When: 저장
+			if err := repo.Save(ctx, u); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			// Then: 테이블에는 평문이 없고 이메일로 조회됨
+			var row UserModel
+			db.First(&row, "id = ?", u.ID().Value())
+			if strings.Contains(row.Email, "secret") || !strings.HasPrefix(row.Email, "test-1:") {
+				t.Errorf("expected ciphertext under test-1, got %q", row.Email)
+			}
+			if row.EmailIndex == nil || strings.Contains(*row.EmailIndex, "secret") {
+				t.Errorf("expected opaque blind index, got %v", row.EmailIndex)
+			}
+			if _, err := repo.FindByEmail(ctx, user.MustNewEmail("secret@example.com")); err != nil {
+				t.Errorf("expected lookup by email, got %v", err)
+			}
+		})
+
+		t.Run("키 교체 후 재암호화", func(t *testing.T) {
+			// Given: test-1 로 저장된 사용자와 test-2 가 주 키인 저장소
+			ctx := t.Context()
+			db := newDB(t)
+			u, _ := user.NewUser(user.GenerateUserID(), user.MustNewEmail("rotate@example.com"), user.NewPasswordFromHash("hash"))
+			if err := NewUserRepository(db, domain.NewSimpleEventBus(), testKeys).Save(ctx, u); err != nil {
+				t.Fatalf("failed to save user: %v", err)
+			}
+			repo := NewUserRepository(db, domain.NewSimpleEventBus(), rotated)
+
+			// When: 재암호화
+			rewritten, err := repo.Reencrypt(ctx)
+
+			// Then: test-2 로 다시 쓰이고 버전은 그대로이며 다시 돌려도 할 일이 없음
+			if err != nil || rewritten != 1 {
+				t.Fatalf("expected 1 row rewritten, got %d (err %v)", rewritten, err)
+			}
+			var row UserModel
+			db.First(&row, "id = ?", u.ID().Value())
+			if !strings.HasPrefix(row.Email, "test-2:") {
+				t.Errorf("expected ciphertext under test-2, got %q", row.Email)
+			}
+			found, err := repo.FindByEmail(ctx, user.MustNewEmail("rotate@example.com"))
+			if err != nil || found.Version() != 1 {
+				t.Errorf("expected user at version 1, got %v (err %v)", found, err)
+			}
+			if again, _ := repo.Reencrypt(ctx); again != 0 {
+				t.Errorf("expected nothing left to rewrite, got %d", again)
+			}
+		})
+
+		t.Run("대소문자만 다른 키 ID 도 재암호화", func(t *testing.T) {
+			// Given: Test-2 로 저장된 사용자와 test-2 가 주 키인 저장소
+			ctx := t.Context()
+			db := newDB(t)
+			old := mustTestKeyring(map[string][]byte{"Test-2": bytes.Repeat([]byte{3}, 32)}, "Test-2")
+			u, _ := user.NewUser(user.GenerateUserID(), user.MustNewEmail("case@example.com"), user.NewPasswordFromHash("hash"))
+			if err := NewUserRepository(db, domain.NewSimpleEventBus(), old).Save(ctx, u); err != nil {
+				t.Fatalf("failed to save user: %v", err)
+			}
+			both := mustTestKeyring(map[string][]byte{
+				"Test-2": bytes.Repeat([]byte{3}, 32),
+				"test-2": bytes.Repeat([]byte{2}, 32),
+			}, "test-2")
+
+			// When: 재암호화
+			rewritten, err := NewUserRepository(db, domain.NewSimpleEventBus(), both).Reencrypt(ctx)
+
+			// Then: test-2 로 다시 쓰임
+			if err != nil || rewritten != 1 {
+				t.Fatalf("expected 1 row rewritten, got %d (err %v)", rewritten, err)
+			}
+			var row UserModel
+			db.First(&row, "id = ?", u.ID().Value())
+			if !strings.HasPrefix(row.Email, "test-2:") {
+				t.Errorf("expected ciphertext under test-2, got %q", row.Email)
+			}
+		})
+
+		t.Run("복호화할 수 없는 행은 건너뛰고 보고", func(t *testing.T) {
+			// Given: 키링에 없는 키로 저장된 사용자와 교체 대상 사용자
+			ctx := t.Context()
+			db := newDB(t)
+			unknown := mustTestKeyring(map[string][]byte{"lost": bytes.Repeat([]byte{7}, 32)}, "lost")
+			lost, _ := user.NewUser(user.GenerateUserID(), user.MustNewEmail("lost@example.com"), user.NewPasswordFromHash("hash"))
+			NewUserRepository(db, domain.NewSimpleEventBus(), unknown).Save(ctx, lost)
+			kept, _ := user.NewUser(user.GenerateUserID(), user.MustNewEmail("kept@example.com"), user.NewPasswordFromHash("hash"))
+			NewUserRepository(db, domain.NewSimpleEventBus(), testKeys).Save(ctx, kept)
+
+			// When: 재암호화
+			rewritten, err := NewUserRepository(db, domain.NewSimpleEventBus(), rotated).Reencrypt(ctx)
+
+			// Then: 나머지는 다시 쓰이고 실패는 에러로 보고됨
+			if rewritten != 1 {
+				t.Errorf("expected 1 row rewritten, got %d", rewritten)
+			}
+			if !errors.Is(err, keyring.ErrUnknownKey) {
+				t.Errorf("expected ErrUnknownKey, got %v", err)
+			}
+		})
+
+		t.Run("암호화 이전 평문 행", func(t *testing.T) {
+			// Given: 암호화 마이그레이션 전에 저장된 사용자
+			ctx := t.Context()
+			db := newDB(t)
+			migrateTestDBTo(t, db, 4)
+			id := user.GenerateUserID()
+			err := db.Exec("INSERT INTO users (id, email, password_hash, created_at, updated_at) VALUES (?, 'legacy@example.com', 'hash', ?, ?)",
+				id.Value(), time.Now().UTC(), time.Now().UTC()).Error
+			if err != nil {
+				t.Fatalf("failed to seed legacy user: %v", err)
+			}
+			migrateTestDB(t, db)
+			repo := NewUserRepository(db, domain.NewSimpleEventBus(), testKeys)
+
+			// When: 재암호화 전후로 이메일 조회
+			before, errBefore := repo.FindByEmail(ctx, user.MustNewEmail("legacy@example.com"))
+			rewritten, err := repo.Reencrypt(ctx)
+			after, errAfter := repo.FindByEmail(ctx, user.MustNewEmail("legacy@example.com"))
+
+			// Then: 두 번 모두 조회되고 평문이 암호문으로 바뀜
+			if errBefore != nil || !before.ID().Equals(id) {
+				t.Errorf("expected legacy user before re-encryption, got %v", errBefore)
+			}
+			if err != nil || rewritten != 1 {
+				t.Errorf("expected 1 row rewritten, got %d (err %v)", rewritten, err)
+			}
+			if errAfter != nil || after.Email().Value() != "legacy@example.com" {
+				t.Errorf("expected legacy user after re-encryption, got %v", errAfter)
+			}
+			var row UserModel
+			db.First(&row, "id = ?", id.Value())
+			if row.EmailIndex == nil || strings.Contains(row.Email, "legacy") {
+				t.Errorf("expected encrypted email with index, got %q", row.Email)
+			}
+		})
+
+		t.Run("재암호화 전 평문 행과 같은 이메일로 가입", func(t *testing.T) {
+			// Given: 아직 재암호화되지 않은 평문 행
+			ctx := t.Context()
+			db := newDB(t)
+			migrateTestDBTo(t, db, 4)
+			err := db.Exec("INSERT INTO users (id, email, password_hash, created_at, updated_at) VALUES (?, 'legacy@example.com', 'hash', ?, ?)",
+				user.GenerateUserID().Value(), time.Now().UTC(), time.Now().UTC()).Error
+			if err != nil {
+				t.Fatalf("failed to seed legacy user: %v", err)
+			}
+			migrateTestDB(t, db)
+			repo := NewUserRepository(db, domain.NewSimpleEventBus(), testKeys)
+
+			// When: 조회 없이 같은 이메일의 새 사용자를 저장
+			u, _ := user.NewUser(repo.NextID(ctx), user.MustNewEmail("legacy@example.com"), user.NewPasswordFromHash("hash"))
+			err = repo.Save(ctx, u)
+
+			// Then: 중복으로 거부되고 행이 하나뿐
+			if !errors.Is(err, user.ErrDuplicateEmail) {
+				t.Errorf("expected ErrDuplicateEmail, got %v", err)
+			}
+			var count int64
+			db.Model(&UserModel{}).Count(&count)
+			if count != 1 {
+				t.Errorf("expected 1 user, got %d", count)
+			}
+		})
+	})
+}
@@ -1,6 +1,7 @@
 package persistence
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -13,7 +14,7 @@ import (
 // EmailVerificationModel is the GORM model
 type EmailVerificationModel struct {
 	Token     string    `gorm:"primarykey"`
-	UserID    uint      `gorm:"index;not null"`
+	UserID    string    `gorm:"index;not null"`
 	ExpiresAt time.Time `gorm:"index;not null"`
 	CreatedAt time.Time
 }
@@ -25,7 +26,7 @@ func (EmailVerificationModel) TableName() string {
 // PasswordResetModel is the GORM model
 type PasswordResetModel struct {
 	Token     string    `gorm:"primarykey"`
-	UserID    uint      `gorm:"index;not null"`
+	UserID    string    `gorm:"index;not null"`
 	ExpiresAt time.Time `gorm:"index;not null"`
 	CreatedAt time.Time
 }
@@ -43,22 +44,22 @@ func NewEmailVerificationRepository(db *gorm.DB) *EmailVerificationRepository {
 	return &EmailVerificationRepository{db: db}
 }
 
-func (r *EmailVerificationRepository) Save(v *verification.EmailVerification) error {
+func (r *EmailVerificationRepository) Save(ctx context.Context, v *verification.EmailVerification) error {
 	model := EmailVerificationModel{
 		Token:     v.Token(),
 		UserID:    v.UserID().Value(),
-		ExpiresAt: v.ExpiresAt(),
-		CreatedAt: v.CreatedAt(),
+		ExpiresAt: v.ExpiresAt().UTC(),
+		CreatedAt: v.CreatedAt().UTC(),
 	}
-	return r.db.Create(&model).Error
+	return translateTokenError(r.db.WithContext(ctx).Create(&model).Error)
 }
 
-func (r *EmailVerificationRepository) FindByToken(token string) (*verification.EmailVerification, error) {
+func (r *EmailVerificationRepository) FindByToken(ctx context.Context, token string) (*verification.EmailVerification, error) {
 	var model EmailVerificationModel
-	err := r.db.Where("token = ? AND expires_at > ?", token, time.Now()).First(&model).Error
+	err := r.db.WithContext(ctx).Where("token = ? AND expires_at > ?", token, time.Now().UTC()).First(&model).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("verification token not found or expired")
+			return nil, verification.ErrTokenNotFound
 		}
 		return nil, err
 	}
@@ -72,8 +73,8 @@ func (r *EmailVerificationRepository) FindByToken(token string) (*verification.E
 	), nil
 }
 
-func (r *EmailVerificationRepository) Delete(token string) error {
-	return r.db.Delete(&EmailVerificationModel{}, "token = ?", token).Error
+func (r *EmailVerificationRepository) Delete(ctx context.Context, token string) error {
+	return r.db.WithContext(ctx).Delete(&EmailVerificationModel{}, "token = ?", token).Error
 }
 
 // PasswordResetRepository implements the repository
@@ -85,22 +86,22 @@ func NewPasswordResetRepository(db *gorm.DB) *PasswordResetRepository {
 	return &PasswordResetRepository{db: db}
 }
 
-func (r *PasswordResetRepository) Save(p *verification.PasswordReset) error {
+func (r *PasswordResetRepository) Save(ctx context.Context, p *verification.PasswordReset) error {
 	model := PasswordResetModel{
 		Token:     p.Token(),
 		UserID:    p.UserID().Value(),
-		ExpiresAt: p.ExpiresAt(),
-		CreatedAt: p.CreatedAt(),
+		ExpiresAt: p.ExpiresAt().UTC(),
+		CreatedAt: p.CreatedAt().UTC(),
 	}
-	return r.db.Create(&model).Error
+	return translateTokenError(r.db.WithContext(ctx).Create(&model).Error)
 }
 
-func (r *PasswordResetRepository) FindByToken(token string) (*verification.PasswordReset, error) {
+func (r *PasswordResetRepository) FindByToken(ctx context.Context, token string) (*verification.PasswordReset, error) {
 	var model PasswordResetModel
-	err := r.db.Where("token = ? AND expires_at > ?", token, time.Now()).First(&model).Error
+	err := r.db.WithContext(ctx).Where("token = ? AND expires_at > ?", token, time.Now().UTC()).First(&model).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("reset token not found or expired")
+			return nil, verification.ErrTokenNotFound
 		}
 		return nil, err
 	}
@@ -114,10 +115,18 @@ func (r *PasswordResetRepository) FindByToken(token string) (*verification.Passw
 	), nil
 }
 
-func (r *PasswordResetRepository) Delete(token string) error {
-	return r.db.Delete(&PasswordResetModel{}, "token = ?", token).Error
+func (r *PasswordResetRepository) Delete(ctx context.Context, token string) error {
+	return r.db.WithContext(ctx).Delete(&PasswordResetModel{}, "token = ?", token).Error
 }
 
-func (r *PasswordResetRepository) DeleteByUserID(userID user.UserID) error {
-	return r.db.Where("user_id = ?", userID.Value()).Delete(&PasswordResetModel{}).Error
+func (r *PasswordResetRepository) DeleteByUserID(ctx context.Context, userID user.UserID) error {
+	return r.db.WithContext(ctx).Where("user_id = ?", userID.Value()).Delete(&PasswordResetModel{}).Error
+}
+
+// translateTokenError maps unique violations (requires gorm.Config.TranslateError)
+func translateTokenError(err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return verification.ErrDuplicateToken
+	}
+	return err
 }
@@ -0,0 +1,26 @@
+package persistence
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+
+	"github.com/junghwan16/test-server/internal/identity/domain/verification"
+	"github.com/junghwan16/test-server/internal/identity/domain/verification/verificationtest"
+)
+
+func TestEmailVerificationRepository(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, newDB func(t *testing.T) *gorm.DB) {
+		verificationtest.TestEmailVerificationRepository(t, func(t *testing.T) verification.EmailVerificationRepository {
+			return NewEmailVerificationRepository(newDB(t))
+		})
+	})
+}
+
+func TestPasswordResetRepository(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, newDB func(t *testing.T) *gorm.DB) {
+		verificationtest.TestPasswordResetRepository(t, func(t *testing.T) verification.PasswordResetRepository {
+			return NewPasswordResetRepository(newDB(t))
+		})
+	})
+}
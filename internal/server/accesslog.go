@@ -0,0 +1,177 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// AccessLogOptions configures Logging
+type AccessLogOptions struct {
+	// SlowThreshold logs requests that take longer at warn level; 0 disables
+	SlowThreshold time.Duration
+	// HealthSampleRate is the fraction of successful /health requests logged,
+	// from 0 (none) to 1 (all). Failed and slow probes are always logged.
+	HealthSampleRate float64
+}
+
+// accessEntry collects what inner handlers learn about a request, such as
+// the matched route and the signed-in user, for the access log
+type accessEntry struct {
+	route  string
+	userID string
+}
+
+type accessEntryKey struct{}
+
+func accessEntryFrom(ctx context.Context) *accessEntry {
+	e, _ := ctx.Value(accessEntryKey{}).(*accessEntry)
+	return e
+}
+
+// withAccessEntry returns r carrying an accessEntry, reusing one an outer
+// middleware already attached
+func withAccessEntry(r *http.Request) (*http.Request, *accessEntry) {
+	if e := accessEntryFrom(r.Context()); e != nil {
+		return r, e
+	}
+	e := &accessEntry{}
+	return r.WithContext(context.WithValue(r.Context(), accessEntryKey{}, e)), e
+}
+
+// setLogUser records the authenticated user for the access log
+func setLogUser(ctx context.Context, userID string) {
+	if e := accessEntryFrom(ctx); e != nil {
+		e.userID = userID
+	}
+}
+
+// Routed records the pattern the mux matched for the access log, metrics
+// and the server span. Wrap each handler as it is registered.
+func Routed(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if e := accessEntryFrom(r.Context()); e != nil {
+			e.route = r.Pattern
+		}
+		nameSpan(r)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Logging writes one access log record per request once the handler has
+// finished: status, bytes written, latency, route, user and request ID.
+// Server errors log at error level and slow requests at warn.
+func Logging(logger *slog.Logger, opts AccessLogOptions) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			r, entry := withAccessEntry(r)
+			rec := &responseRecorder{ResponseWriter: w}
+
+			next.ServeHTTP(rec, r)
+
+			elapsed := time.Since(start)
+			status := rec.statusCode()
+			streaming := strings.HasPrefix(rec.Header().Get("Content-Type"), "text/event-stream")
+			slow := opts.SlowThreshold > 0 && elapsed > opts.SlowThreshold && !streaming
+
+			level := slog.LevelInfo
+			switch {
+			case status >= http.StatusInternalServerError:
+				level = slog.LevelError
+			case slow:
+				level = slog.LevelWarn
+			case status < http.StatusBadRequest && isHealthCheck(r.URL.Path) &&
+				(opts.HealthSampleRate <= 0 || rand.Float64() >= opts.HealthSampleRate):
+				return
+			}
+
+			attrs := []slog.Attr{
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.String("route", entry.route),
+				slog.Int("status", status),
+				slog.Int64("bytes", rec.bytes),
+				slog.Float64("latency_ms", float64(elapsed.Microseconds())/1000),
+				slog.String("ip", getIP(r)),
+			}
+			if entry.userID != "" {
+				attrs = append(attrs, slog.String("user_id", entry.userID))
+			}
+			if slow {
+				attrs = append(attrs, slog.Bool("slow", true))
+			}
+			logger.LogAttrs(r.Context(), level, "request", attrs...)
+		})
+	}
+}
+
+func isHealthCheck(path string) bool {
+	return path == "/health" || strings.HasPrefix(path, "/health/")
+}
+
+// responseRecorder captures the status and size of a response. It passes
+// Flush and Hijack through so event streams and upgrades keep working, and
+// Unwrap lets http.ResponseController reach the underlying writer.
+type responseRecorder struct {
+	http.ResponseWriter
+	status   int
+	bytes    int64
+	hijacked bool
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 && status >= http.StatusOK {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Flush sends buffered data to the client if the underlying writer supports it
+func (r *responseRecorder) Flush() {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	_ = http.NewResponseController(r.ResponseWriter).Flush()
+}
+
+// Hijack hands the connection to the caller if the underlying writer supports it
+func (r *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(r.ResponseWriter).Hijack()
+	if err == nil {
+		r.hijacked = true
+	}
+	return conn, rw, err
+}
+
+// Unwrap returns the underlying writer for http.ResponseController
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// statusCode is the status sent, 101 for hijacked connections and 200 if
+// the handler wrote nothing
+func (r *responseRecorder) statusCode() int {
+	switch {
+	case r.status != 0:
+		return r.status
+	case r.hijacked:
+		return http.StatusSwitchingProtocols
+	default:
+		return http.StatusOK
+	}
+}
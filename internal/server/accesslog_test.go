@@ -0,0 +1,142 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/junghwan16/test-server/internal/shared/requestid"
+)
+
+// logRecords runs one request through Logging and returns the records written
+func logRecords(t *testing.T, opts AccessLogOptions, h http.Handler, req *http.Request) []map[string]any {
+	t.Helper()
+
+	var buf bytes.Buffer
+	logger := slog.New(requestid.NewLogHandler(slog.NewJSONHandler(&buf, nil)))
+	RequestID()(Logging(logger, opts)(h)).ServeHTTP(httptest.NewRecorder(), req)
+
+	var records []map[string]any
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var rec map[string]any
+		if err := dec.Decode(&rec); err != nil {
+			t.Fatalf("failed to decode log record: %v", err)
+		}
+		records = append(records, rec)
+	}
+	return records
+}
+
+func TestLogging(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("GET /items/{id}", Routed(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		setLogUser(r.Context(), "user-1")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("hello"))
+	})))
+	mux.Handle("GET /slow", Routed(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+	})))
+	mux.Handle("GET /fail", Routed(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	})))
+	mux.Handle("GET /health/live", Routed(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+
+	t.Run("응답 기록", func(t *testing.T) {
+		// When: 핸들러가 상태와 본문을 쓴 요청
+		req := httptest.NewRequest("GET", "/items/42", nil)
+		req.Header.Set(requestid.Header, "req-1")
+		records := logRecords(t, AccessLogOptions{}, mux, req)
+
+		// Then: 핸들러가 끝난 뒤 상태, 크기, 라우트, 사용자, 요청 ID 가 한 줄로 기록됨
+		if len(records) != 1 {
+			t.Fatalf("expected 1 record, got %d", len(records))
+		}
+		rec := records[0]
+		want := map[string]any{
+			"level": "INFO", "status": 201.0, "bytes": 5.0, "route": "GET /items/{id}",
+			"path": "/items/42", "user_id": "user-1", "request_id": "req-1",
+		}
+		for k, v := range want {
+			if rec[k] != v {
+				t.Errorf("expected %s=%v, got %v", k, v, rec[k])
+			}
+		}
+		if _, ok := rec["latency_ms"].(float64); !ok {
+			t.Errorf("expected latency_ms, got %v", rec["latency_ms"])
+		}
+	})
+
+	t.Run("느린 요청과 서버 오류", func(t *testing.T) {
+		opts := AccessLogOptions{SlowThreshold: 10 * time.Millisecond}
+
+		// When: 임계값보다 느린 요청과 500 응답
+		slow := logRecords(t, opts, mux, httptest.NewRequest("GET", "/slow", nil))
+		failed := logRecords(t, opts, mux, httptest.NewRequest("GET", "/fail", nil))
+
+		// Then: 각각 warn, error 레벨
+		if slow[0]["level"] != "WARN" || slow[0]["slow"] != true {
+			t.Errorf("expected slow request at WARN, got %v", slow[0])
+		}
+		if failed[0]["level"] != "ERROR" || failed[0]["status"] != 500.0 {
+			t.Errorf("expected server error at ERROR, got %v", failed[0])
+		}
+	})
+
+	t.Run("헬스 체크 샘플링", func(t *testing.T) {
+		// When: 샘플링 비율 0 과 1 로 헬스 체크
+		none := logRecords(t, AccessLogOptions{}, mux, httptest.NewRequest("GET", "/health/live", nil))
+		all := logRecords(t, AccessLogOptions{HealthSampleRate: 1}, mux, httptest.NewRequest("GET", "/health/live", nil))
+
+		// Then: 0 이면 생략, 1 이면 기록
+		if len(none) != 0 || len(all) != 1 {
+			t.Errorf("expected 0 and 1 records, got %d and %d", len(none), len(all))
+		}
+	})
+}
+
+func TestResponseRecorder_PreservesInterfaces(t *testing.T) {
+	// Given: 실제 연결 위에서 동작하는 서버
+	var flushed, hijacked bool
+	h := Logging(slog.New(slog.DiscardHandler), AccessLogOptions{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/stream" {
+			// When: 스트리밍 핸들러가 Flush 호출
+			w.Write([]byte("data: 1\n\n"))
+			if f, ok := w.(http.Flusher); ok {
+				f.Flush()
+				flushed = true
+			}
+			return
+		}
+		// When: 업그레이드 핸들러가 연결을 가져감
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("expected hijack to succeed, got %v", err)
+			return
+		}
+		hijacked = true
+		conn.Write([]byte("HTTP/1.1 101 Switching Protocols\r\n\r\n"))
+		conn.Close()
+	}))
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/stream")
+	if err != nil {
+		t.Fatalf("GET /stream: %v", err)
+	}
+	resp.Body.Close()
+	if resp, err := http.Get(srv.URL + "/upgrade"); err == nil {
+		resp.Body.Close()
+	}
+
+	// Then: 래퍼가 두 인터페이스를 모두 넘겨줌
+	if !flushed || !hijacked {
+		t.Errorf("expected flush and hijack through the recorder, got %v %v", flushed, hijacked)
+	}
+}
@@ -0,0 +1,149 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSOptions configures CORS
+type CORSOptions struct {
+	// AllowedOrigins lists origins such as "https://app.example.com". A "*"
+	// stands for one or more DNS labels or a port, as in
+	// "https://*.example.com" or "http://localhost:*"; "*" alone allows any
+	// origin and cannot be combined with AllowCredentials.
+	AllowedOrigins []string
+	// AllowCredentials lets browsers send the session cookie and read the response
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache a preflight; 0 leaves it to them
+	MaxAge time.Duration
+}
+
+// corsMethods are offered in preflights for paths no route matches, so the
+// real request reaches the server and gets a readable 404 or 405
+var corsMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// corsRequestHeaders may be sent by cross-origin scripts besides the CORS-safelisted ones
+var corsRequestHeaders = []string{"Content-Type", "If-Match", "API-Version", "X-Request-ID", "X-CSRF-Token"}
+
+// corsExposedHeaders may be read by cross-origin scripts besides the CORS-safelisted ones
+var corsExposedHeaders = []string{
+	"API-Version", "Deprecation", "Link", "ETag", "X-Request-ID", "X-CSRF-Token", "Retry-After",
+	"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy",
+}
+
+// wildcardLabels replaces "*" in origin patterns
+const wildcardLabels = `[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*`
+
+// CORS lets the allowed origins call the API from a browser. Preflight
+// requests are answered here, before routing, with the methods mux routes
+// for the path, since the method-scoped patterns would otherwise reject
+// OPTIONS with 405. Install it outside the rate limiter so rejections stay
+// readable. With no allowed origins it does nothing.
+func CORS(mux *http.ServeMux, opts CORSOptions) (func(http.Handler) http.Handler, error) {
+	allowed, err := compileOrigins(opts.AllowedOrigins, opts.AllowCredentials)
+	if err != nil {
+		return nil, err
+	}
+	exposed := strings.Join(corsExposedHeaders, ", ")
+
+	return func(next http.Handler) http.Handler {
+		if allowed == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+			h := w.Header()
+			h.Add("Vary", "Origin")
+			if preflight {
+				h.Add("Vary", "Access-Control-Request-Method")
+				h.Add("Vary", "Access-Control-Request-Headers")
+			}
+
+			if origin != "" && allowed.MatchString(origin) {
+				h.Set("Access-Control-Allow-Origin", origin)
+				if opts.AllowCredentials {
+					h.Set("Access-Control-Allow-Credentials", "true")
+				}
+				if preflight {
+					writePreflight(h, mux, r, opts.MaxAge)
+				} else {
+					h.Set("Access-Control-Expose-Headers", exposed)
+				}
+			}
+
+			if preflight {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+// writePreflight allows the requested method if mux routes it for the path,
+// and the requested headers if all of them are allowed
+func writePreflight(h http.Header, mux *http.ServeMux, r *http.Request, maxAge time.Duration) {
+	methods := routedMethods(mux, r)
+	if len(methods) == 0 {
+		methods = corsMethods
+	}
+	if !slices.Contains(methods, r.Header.Get("Access-Control-Request-Method")) {
+		return
+	}
+	h.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+
+	if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+		for name := range strings.SplitSeq(requested, ",") {
+			if !slices.ContainsFunc(corsRequestHeaders, func(allowed string) bool {
+				return strings.EqualFold(allowed, strings.TrimSpace(name))
+			}) {
+				return
+			}
+		}
+		h.Set("Access-Control-Allow-Headers", requested)
+	}
+
+	if maxAge > 0 {
+		h.Set("Access-Control-Max-Age", strconv.Itoa(int(maxAge.Seconds())))
+	}
+}
+
+// routedMethods returns the methods mux has a route for at r's path
+func routedMethods(mux *http.ServeMux, r *http.Request) []string {
+	var methods []string
+	for _, method := range corsMethods {
+		probe := r.Clone(r.Context())
+		probe.Method = method
+		if _, pattern := mux.Handler(probe); pattern != "" {
+			methods = append(methods, method)
+		}
+	}
+	return methods
+}
+
+// compileOrigins builds one regexp matching every allowed origin, or nil if there are none
+func compileOrigins(origins []string, credentials bool) (*regexp.Regexp, error) {
+	if len(origins) == 0 {
+		return nil, nil
+	}
+
+	alternatives := make([]string, len(origins))
+	for i, origin := range origins {
+		if origin == "*" {
+			if credentials {
+				return nil, errors.New("cors: allowing any origin with credentials would let every site act as the signed-in user")
+			}
+			alternatives[i] = ".+"
+			continue
+		}
+		alternatives[i] = strings.ReplaceAll(regexp.QuoteMeta(strings.TrimSuffix(origin, "/")), `\*`, wildcardLabels)
+	}
+	return regexp.Compile(`^(?:` + strings.Join(alternatives, "|") + `)$`)
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCORS(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/auth/login", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("GET /v1/admin/users/{id}", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("PATCH /v1/admin/users/{id}", func(w http.ResponseWriter, r *http.Request) {})
+
+	cors, err := CORS(mux, CORSOptions{
+		AllowedOrigins:   []string{"https://app.example.com", "https://*.preview.example.com"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	h := cors(mux)
+
+	preflight := func(origin, path, method, headers string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("OPTIONS", path, nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", method)
+		if headers != "" {
+			req.Header.Set("Access-Control-Request-Headers", headers)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("프리플라이트 허용", func(t *testing.T) {
+		// When: 허용된 출처에서 라우트가 있는 메서드로 프리플라이트
+		rec := preflight("https://app.example.com", "/v1/admin/users/42", "PATCH", "content-type, if-match")
+
+		// Then: 204 와 해당 경로의 메서드, 요청 헤더, 자격 증명 허용
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("expected 204, got %d", rec.Code)
+		}
+		for header, want := range map[string]string{
+			"Access-Control-Allow-Origin":      "https://app.example.com",
+			"Access-Control-Allow-Credentials": "true",
+			"Access-Control-Allow-Methods":     "GET, PATCH",
+			"Access-Control-Allow-Headers":     "content-type, if-match",
+			"Access-Control-Max-Age":           "600",
+		} {
+			if got := rec.Header().Get(header); got != want {
+				t.Errorf("expected %s %q, got %q", header, want, got)
+			}
+		}
+	})
+
+	t.Run("패턴 출처", func(t *testing.T) {
+		// When: 와일드카드 패턴에 맞는 출처와 맞지 않는 출처
+		match := preflight("https://pr-12.preview.example.com", "/v1/auth/login", "POST", "")
+		other := preflight("https://preview.example.com.evil.test", "/v1/auth/login", "POST", "")
+
+		// Then: 패턴에 맞는 출처만 허용
+		if got := match.Header().Get("Access-Control-Allow-Origin"); got != "https://pr-12.preview.example.com" {
+			t.Errorf("expected the preview origin to be allowed, got %q", got)
+		}
+		if got := other.Header().Get("Access-Control-Allow-Origin"); got != "" {
+			t.Errorf("expected a look-alike origin to be refused, got %q", got)
+		}
+	})
+
+	t.Run("허용하지 않는 메서드와 헤더", func(t *testing.T) {
+		// When: 라우트에 없는 메서드, 허용하지 않는 헤더로 프리플라이트
+		method := preflight("https://app.example.com", "/v1/auth/login", "DELETE", "")
+		header := preflight("https://app.example.com", "/v1/auth/login", "POST", "X-Secret")
+
+		// Then: 허용 헤더 없음
+		if got := method.Header().Get("Access-Control-Allow-Methods"); got != "" {
+			t.Errorf("expected DELETE to be refused, got %q", got)
+		}
+		if got := header.Header().Get("Access-Control-Allow-Headers"); got != "" {
+			t.Errorf("expected X-Secret to be refused, got %q", got)
+		}
+	})
+
+	t.Run("실제 요청", func(t *testing.T) {
+		// When: 허용된 출처와 허용되지 않은 출처의 요청
+		allowed := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/v1/auth/login", nil)
+		req.Header.Set("Origin", "https://app.example.com")
+		h.ServeHTTP(allowed, req)
+
+		refused := httptest.NewRecorder()
+		req = httptest.NewRequest("POST", "/v1/auth/login", nil)
+		req.Header.Set("Origin", "https://evil.test")
+		h.ServeHTTP(refused, req)
+
+		// Then: 둘 다 처리되지만 허용된 출처만 응답을 읽을 수 있음
+		if allowed.Code != http.StatusOK || refused.Code != http.StatusOK {
+			t.Fatalf("expected both to reach the handler, got %d and %d", allowed.Code, refused.Code)
+		}
+		if allowed.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" || allowed.Header().Get("Access-Control-Expose-Headers") == "" {
+			t.Errorf("expected CORS headers for the allowed origin, got %v", allowed.Header())
+		}
+		if refused.Header().Get("Access-Control-Allow-Origin") != "" {
+			t.Errorf("expected no CORS headers for another origin, got %v", refused.Header())
+		}
+		if refused.Header().Get("Vary") != "Origin" {
+			t.Errorf("expected Vary: Origin, got %q", refused.Header().Get("Vary"))
+		}
+	})
+
+	t.Run("모든 출처와 자격 증명", func(t *testing.T) {
+		// When: 모든 출처를 자격 증명과 함께 허용
+		_, err := CORS(mux, CORSOptions{AllowedOrigins: []string{"*"}, AllowCredentials: true})
+
+		// Then: 오류
+		if err == nil {
+			t.Error("expected an error for any origin with credentials")
+		}
+	})
+}
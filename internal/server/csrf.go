@@ -0,0 +1,77 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/junghwan16/test-server/internal/shared/csrf"
+	"github.com/junghwan16/test-server/internal/shared/problem"
+)
+
+// ProblemCSRF is returned for a cookie-authenticated request that may have been forged
+var ProblemCSRF = problem.New(http.StatusForbidden, "csrf_failed", "CSRF check failed")
+
+// CSRFOptions configures CSRF
+type CSRFOptions struct {
+	// TrustedOrigins may send cookie-authenticated requests besides the
+	// server's own origin, in the same syntax as CORSOptions.AllowedOrigins.
+	// A bare "*" is ignored.
+	TrustedOrigins []string
+}
+
+// CSRF rejects unsafe requests authenticated by the session cookie unless
+// they come from the server's own or a trusted origin, judged by Origin or
+// else Sec-Fetch-Site, and carry the session's token in the X-CSRF-Token
+// header. Requests without a session cookie pass, since nothing else
+// authenticates them; an Authorization header does not exempt a request that
+// also carries the cookie. Install it on routes that act on the session.
+func CSRF(tokens *csrf.Tokens, opts CSRFOptions) (func(http.Handler) http.Handler, error) {
+	origins := slices.DeleteFunc(slices.Clone(opts.TrustedOrigins), func(o string) bool { return o == "*" })
+	trusted, err := compileOrigins(origins, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cookie, err := r.Cookie("session")
+			if isSafeMethod(r.Method) || err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if origin := r.Header.Get("Origin"); origin != "" {
+				if !sameOrigin(origin, r.Host) && (trusted == nil || !trusted.MatchString(origin)) {
+					problem.Write(w, r, ProblemCSRF.WithDetail("origin "+origin+" is not trusted"))
+					return
+				}
+			} else if site := r.Header.Get("Sec-Fetch-Site"); site == "cross-site" || site == "same-site" {
+				problem.Write(w, r, ProblemCSRF.WithDetail("request was sent from another site"))
+				return
+			}
+
+			if !tokens.Valid(cookie.Value, r.Header.Get(csrf.Header)) {
+				problem.Write(w, r, ProblemCSRF.WithDetail("missing or invalid "+csrf.Header+" header"))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// sameOrigin compares hosts only, since TLS may end at a proxy in front of the server
+func sameOrigin(origin, host string) bool {
+	u, err := url.Parse(origin)
+	return err == nil && u.Host != "" && strings.EqualFold(u.Host, host)
+}
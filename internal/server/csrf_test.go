@@ -0,0 +1,65 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/junghwan16/test-server/internal/shared/csrf"
+)
+
+func TestCSRF(t *testing.T) {
+	tokens, _ := csrf.NewTokens(bytes.Repeat([]byte{1}, csrf.MinKeySize))
+	check, err := CSRF(tokens, CSRFOptions{TrustedOrigins: []string{"https://app.example.com", "*"}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	h := check(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	serve := func(method string, headers map[string]string, withSession bool) int {
+		req := httptest.NewRequest(method, "http://api.example.com/v1/auth/logout", nil)
+		if withSession {
+			req.AddCookie(&http.Cookie{Name: "session", Value: "session-1"})
+		}
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	token := tokens.Issue("session-1")
+
+	tests := []struct {
+		name        string
+		method      string
+		headers     map[string]string
+		withSession bool
+		want        int
+	}{
+		{"안전한 메서드", "GET", nil, true, http.StatusOK},
+		{"세션 쿠키 없음", "POST", nil, false, http.StatusOK},
+		{"토큰 없음", "POST", nil, true, http.StatusForbidden},
+		{"다른 세션의 토큰", "POST", map[string]string{csrf.Header: tokens.Issue("session-2")}, true, http.StatusForbidden},
+		{"유효한 토큰", "DELETE", map[string]string{csrf.Header: token}, true, http.StatusOK},
+		{"같은 출처", "POST", map[string]string{csrf.Header: token, "Origin": "https://api.example.com"}, true, http.StatusOK},
+		{"신뢰 출처", "PATCH", map[string]string{csrf.Header: token, "Origin": "https://app.example.com"}, true, http.StatusOK},
+		{"신뢰하지 않는 출처", "POST", map[string]string{csrf.Header: token, "Origin": "https://evil.test"}, true, http.StatusForbidden},
+		{"같은 사이트 하위 도메인", "POST", map[string]string{csrf.Header: token, "Sec-Fetch-Site": "same-site"}, true, http.StatusForbidden},
+		{"같은 출처 Sec-Fetch-Site", "POST", map[string]string{csrf.Header: token, "Sec-Fetch-Site": "same-origin"}, true, http.StatusOK},
+		{"세션 쿠키와 베어러 토큰", "POST", map[string]string{"Authorization": "Bearer abc"}, true, http.StatusForbidden},
+		{"베어러 토큰만", "POST", map[string]string{"Authorization": "Bearer abc"}, false, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When: 요청 처리
+			got := serve(tt.method, tt.headers, tt.withSession)
+
+			// Then: 기대한 상태
+			if got != tt.want {
+				t.Errorf("expected %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
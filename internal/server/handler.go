@@ -6,31 +6,27 @@ import (
 	"log/slog"
 	"net/http"
 	"time"
-
-	"gorm.io/gorm"
 )
 
+// HealthCheck reports whether a dependency is reachable
+type HealthCheck func(ctx context.Context) error
+
 func HandleLive(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("OK"))
 }
 
-func HandleReady(db *gorm.DB, logger *slog.Logger) http.HandlerFunc {
+func HandleReady(checks map[string]HealthCheck, logger *slog.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
 		defer cancel()
 
-		sqlDB, err := db.DB()
-		if err != nil {
-			logger.Error("health check: failed to get db instance", "error", err)
-			http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
-			return
-		}
-
-		if err := sqlDB.PingContext(ctx); err != nil {
-			logger.Warn("health check: database not ready", "error", err)
-			http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
-			return
+		for name, check := range checks {
+			if err := check(ctx); err != nil {
+				logger.Warn("health check: dependency not ready", "component", name, "error", err)
+				http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+				return
+			}
 		}
 
 		w.WriteHeader(http.StatusOK)
@@ -38,7 +34,7 @@ func HandleReady(db *gorm.DB, logger *slog.Logger) http.HandlerFunc {
 	}
 }
 
-func HandleHealth(db *gorm.DB, logger *slog.Logger) http.HandlerFunc {
+func HandleHealth(checks map[string]HealthCheck, logger *slog.Logger) http.HandlerFunc {
 	type health struct {
 		Status     string            `json:"status"`
 		Timestamp  time.Time         `json:"timestamp"`
@@ -55,19 +51,20 @@ func HandleHealth(db *gorm.DB, logger *slog.Logger) http.HandlerFunc {
 			Components: make(map[string]string),
 		}
 
-		sqlDB, err := db.DB()
-		if err != nil || sqlDB.PingContext(ctx) != nil {
-			h.Status = "unhealthy"
-			h.Components["database"] = "unhealthy"
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusServiceUnavailable)
-			json.NewEncoder(w).Encode(h)
-			return
+		status := http.StatusOK
+		for name, check := range checks {
+			if err := check(ctx); err != nil {
+				logger.Warn("health check: dependency unhealthy", "component", name, "error", err)
+				h.Status = "unhealthy"
+				h.Components[name] = "unhealthy"
+				status = http.StatusServiceUnavailable
+				continue
+			}
+			h.Components[name] = "healthy"
 		}
 
-		h.Components["database"] = "healthy"
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
+		w.WriteHeader(status)
 		json.NewEncoder(w).Encode(h)
 	}
 }
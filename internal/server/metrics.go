@@ -0,0 +1,24 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/junghwan16/test-server/internal/shared/infrastructure/metrics"
+)
+
+// Instrument records the count and latency of every request in m, by method,
+// route pattern and status. Handlers must be wrapped with Routed.
+func Instrument(m *metrics.Metrics) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			r, entry := withAccessEntry(r)
+			rec := &responseRecorder{ResponseWriter: w}
+
+			next.ServeHTTP(rec, r)
+
+			m.ObserveRequest(r.Method, entry.route, rec.statusCode(), time.Since(start))
+		})
+	}
+}
@@ -1,38 +1,79 @@
 package server
 
 import (
-	"log/slog"
+	"context"
+	"net"
 	"net/http"
-	"sync"
+	"regexp"
 	"time"
 
-	"golang.org/x/time/rate"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/junghwan16/test-server/internal/identity/application"
 	"github.com/junghwan16/test-server/internal/identity/handler"
+	"github.com/junghwan16/test-server/internal/shared/clientip"
+	"github.com/junghwan16/test-server/internal/shared/problem"
+	"github.com/junghwan16/test-server/internal/shared/requestid"
 )
 
+// validRequestID limits IDs accepted from clients to ones safe to log and echo
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:/+=-]{1,128}$`)
+
+// RequestID accepts the X-Request-ID a client or proxy sent, or generates one
+// if it is missing or malformed, stores it in the request context and echoes
+// it in the response. Install it outermost so every log record carries it.
+func RequestID() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(requestid.Header)
+			if !validRequestID.MatchString(id) {
+				id = uuid.NewString()
+			}
+
+			w.Header().Set(requestid.Header, id)
+			trace.SpanFromContext(r.Context()).SetAttributes(attribute.String("request.id", id))
+			next.ServeHTTP(w, r.WithContext(requestid.NewContext(r.Context(), id)))
+		})
+	}
+}
+
+// ClientIP resolves the client address with resolver and stores it in the
+// request context, where the access log, rate limits and anything auditing
+// the request read it
+func ClientIP(resolver *clientip.Resolver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := resolver.Resolve(r)
+			trace.SpanFromContext(r.Context()).SetAttributes(attribute.String("client.address", ip))
+			next.ServeHTTP(w, r.WithContext(clientip.NewContext(r.Context(), ip)))
+		})
+	}
+}
+
 // RequireAuth checks if the user is authenticated via session cookie
 func RequireAuth(authSvc *application.AuthService) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			cookie, err := r.Cookie("session")
 			if err != nil {
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				problem.Write(w, r, problem.Unauthenticated)
 				return
 			}
 
-			_, u, err := authSvc.ValidateSession(cookie.Value)
+			_, u, err := authSvc.ValidateSession(r.Context(), cookie.Value)
 			if err != nil {
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				problem.Write(w, r, problem.Unauthenticated)
 				return
 			}
 
 			if !u.Active() {
-				http.Error(w, "Account deactivated", http.StatusForbidden)
+				problem.Write(w, r, handler.ProblemAccountDeactivated)
 				return
 			}
 
+			setLogUser(r.Context(), u.ID().Value())
 			ctx := handler.SetUserInContext(r.Context(), u)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
@@ -46,7 +87,7 @@ func RequireAdmin(authSvc *application.AuthService) func(http.Handler) http.Hand
 		return authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			u := handler.GetUserFromContext(r.Context())
 			if u == nil || !u.IsAdmin() {
-				http.Error(w, "Forbidden", http.StatusForbidden)
+				problem.Write(w, r, problem.Forbidden)
 				return
 			}
 			next.ServeHTTP(w, r)
@@ -54,72 +95,31 @@ func RequireAdmin(authSvc *application.AuthService) func(http.Handler) http.Hand
 	}
 }
 
-// RateLimit implements simple in-memory rate limiting per IP
-func RateLimit(rps float64, burst int) func(http.Handler) http.Handler {
-	type client struct {
-		limiter  *rate.Limiter
-		lastSeen time.Time
-	}
-
-	var (
-		mu      sync.Mutex
-		clients = make(map[string]*client)
-	)
-
-	go func() {
-		for {
-			time.Sleep(5 * time.Minute)
-			mu.Lock()
-			for ip, c := range clients {
-				if time.Since(c.lastSeen) > 10*time.Minute {
-					delete(clients, ip)
-				}
-			}
-			mu.Unlock()
-		}
-	}()
-
+// Timeout bounds each request's context so database and Redis calls are
+// cancelled once the deadline passes. Leave long-lived streams unwrapped.
+func Timeout(d time.Duration) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			ip := getIP(r)
-
-			mu.Lock()
-			c, exists := clients[ip]
-			if !exists {
-				c = &client{limiter: rate.NewLimiter(rate.Limit(rps), burst)}
-				clients[ip] = c
-			}
-			c.lastSeen = time.Now()
-			mu.Unlock()
-
-			if !c.limiter.Allow() {
-				http.Error(w, "Too many requests", http.StatusTooManyRequests)
+			if d <= 0 {
+				next.ServeHTTP(w, r)
 				return
 			}
 
-			next.ServeHTTP(w, r)
-		})
-	}
-}
-
-// Logging logs all requests
-func Logging(logger *slog.Logger) func(http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			logger.Info("request",
-				"method", r.Method,
-				"path", r.URL.Path,
-				"ip", getIP(r),
-			)
-			next.ServeHTTP(w, r)
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
+// getIP returns the client IP resolved by ClientIP, or the peer address
+// without its port if the middleware is not installed
 func getIP(r *http.Request) string {
-	forwarded := r.Header.Get("X-Forwarded-For")
-	if forwarded != "" {
-		return forwarded
+	if ip := clientip.FromContext(r.Context()); ip != "" {
+		return ip
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
 	}
 	return r.RemoteAddr
 }
@@ -0,0 +1,210 @@
+package openapi
+
+import (
+	"bytes"
+	"cmp"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/junghwan16/test-server/internal/shared/problem"
+)
+
+//go:embed docs.html
+var docsTemplate string
+
+// docsPolicy is the Content-Security-Policy of the docs page. The page is
+// rendered on the server and runs no scripts; only its inline styles load.
+const docsPolicy = "default-src 'none'; style-src 'unsafe-inline'; frame-ancestors 'none'"
+
+// docsPage renders the docs page once; the document is embedded, so it never changes
+var docsPage = sync.OnceValues(func() ([]byte, error) {
+	model, err := newDocsModel(spec)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := template.New("docs").Parse(docsTemplate)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, model); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+})
+
+// HandleDocs serves an HTML page rendering the OpenAPI document
+func HandleDocs(w http.ResponseWriter, r *http.Request) {
+	page, err := docsPage()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "openapi: render docs", "error", err)
+		problem.Write(w, r, problem.Internal)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", docsPolicy)
+	w.Write(page)
+}
+
+// docsDocument is the part of the OpenAPI document the docs page shows
+type docsDocument struct {
+	Info struct {
+		Title       string `json:"title"`
+		Version     string `json:"version"`
+		Description string `json:"description"`
+	} `json:"info"`
+	Tags []struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	} `json:"tags"`
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas   map[string]json.RawMessage    `json:"schemas"`
+		Responses map[string]docsResponseObject `json:"responses"`
+	} `json:"components"`
+}
+
+type docsOperationObject struct {
+	Tags        []string              `json:"tags"`
+	Summary     string                `json:"summary"`
+	Description string                `json:"description"`
+	Security    []map[string][]string `json:"security"`
+	Parameters  []docsParameter       `json:"parameters"`
+	RequestBody *struct {
+		Content map[string]struct {
+			Schema struct {
+				Ref  string `json:"$ref"`
+				Type string `json:"type"`
+			} `json:"schema"`
+		} `json:"content"`
+	} `json:"requestBody"`
+	Responses map[string]docsResponseObject `json:"responses"`
+}
+
+type docsResponseObject struct {
+	Ref         string `json:"$ref"`
+	Description string `json:"description"`
+}
+
+type docsModel struct {
+	Title, Version, Description string
+	Tags                        []docsTag
+	Schemas                     []docsSchema
+}
+
+type docsTag struct {
+	Name, Description string
+	Operations        []docsOperation
+}
+
+type docsOperation struct {
+	ID, Method, Path, Summary, Description string
+	Auth                                   bool
+	Parameters                             []docsParameter
+	RequestBody                            []docsBody
+	Responses                              []docsResponse
+}
+
+type docsParameter struct {
+	Name        string `json:"name"`
+	In          string `json:"in"`
+	Description string `json:"description"`
+	Required    bool   `json:"required"`
+}
+
+type docsBody struct {
+	Type, Schema string
+}
+
+type docsResponse struct {
+	Status, Description string
+}
+
+type docsSchema struct {
+	Name, JSON string
+}
+
+// newDocsModel arranges the document's operations by tag, in path order
+func newDocsModel(data []byte) (*docsModel, error) {
+	var doc docsDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("openapi: parse document: %w", err)
+	}
+
+	model := &docsModel{Title: doc.Info.Title, Version: doc.Info.Version, Description: doc.Info.Description}
+	byTag := make(map[string][]docsOperation)
+
+	for path, item := range doc.Paths {
+		var shared []docsParameter
+		if raw, ok := item["parameters"]; ok {
+			if err := json.Unmarshal(raw, &shared); err != nil {
+				return nil, fmt.Errorf("openapi: parameters of %s: %w", path, err)
+			}
+		}
+
+		for key, raw := range item {
+			if !slices.Contains(methods, key) {
+				continue
+			}
+			var op docsOperationObject
+			if err := json.Unmarshal(raw, &op); err != nil {
+				return nil, fmt.Errorf("openapi: %s %s: %w", key, path, err)
+			}
+
+			entry := docsOperation{
+				ID:          strings.Trim(strings.NewReplacer("/", "-", "{", "", "}", "").Replace(key+path), "-"),
+				Method:      key,
+				Path:        path,
+				Summary:     op.Summary,
+				Description: op.Description,
+				Auth:        len(op.Security) > 0,
+				Parameters:  append(slices.Clone(shared), op.Parameters...),
+			}
+			if op.RequestBody != nil {
+				for contentType, media := range op.RequestBody.Content {
+					entry.RequestBody = append(entry.RequestBody, docsBody{
+						Type:   contentType,
+						Schema: strings.TrimPrefix(media.Schema.Ref, "#/components/schemas/"),
+					})
+				}
+			}
+			for status, resp := range op.Responses {
+				if name, ok := strings.CutPrefix(resp.Ref, "#/components/responses/"); ok {
+					resp = doc.Components.Responses[name]
+				}
+				entry.Responses = append(entry.Responses, docsResponse{Status: status, Description: resp.Description})
+			}
+			slices.SortFunc(entry.Responses, func(a, b docsResponse) int { return cmp.Compare(a.Status, b.Status) })
+
+			for _, tag := range op.Tags {
+				byTag[tag] = append(byTag[tag], entry)
+			}
+		}
+	}
+
+	for _, tag := range doc.Tags {
+		ops := byTag[tag.Name]
+		slices.SortFunc(ops, func(a, b docsOperation) int {
+			return cmp.Or(cmp.Compare(a.Path, b.Path), cmp.Compare(slices.Index(methods, a.Method), slices.Index(methods, b.Method)))
+		})
+		model.Tags = append(model.Tags, docsTag{Name: tag.Name, Description: tag.Description, Operations: ops})
+	}
+
+	for name, raw := range doc.Components.Schemas {
+		var pretty bytes.Buffer
+		if err := json.Indent(&pretty, raw, "", "  "); err != nil {
+			return nil, fmt.Errorf("openapi: schema %s: %w", name, err)
+		}
+		model.Schemas = append(model.Schemas, docsSchema{Name: name, JSON: pretty.String()})
+	}
+	slices.SortFunc(model.Schemas, func(a, b docsSchema) int { return cmp.Compare(a.Name, b.Name) })
+
+	return model, nil
+}
@@ -0,0 +1,54 @@
+// Package openapi serves the OpenAPI 3.1 document describing the HTTP API,
+// a docs page rendering it, and middleware validating requests against it.
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+//go:embed openapi.json
+var spec []byte
+
+// methods are the OpenAPI path item keys that name operations
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// document is the part of an OpenAPI document the server needs
+type document struct {
+	Paths map[string]map[string]json.RawMessage `json:"paths"`
+}
+
+// Spec returns the raw OpenAPI document
+func Spec() []byte {
+	return spec
+}
+
+// HandleSpec serves the OpenAPI document
+func HandleSpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(spec)
+}
+
+// Routes returns every operation in the document as a ServeMux pattern
+// such as "GET /admin/users/{id}", sorted
+func Routes() ([]string, error) {
+	var doc document
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return nil, fmt.Errorf("openapi: parse document: %w", err)
+	}
+
+	var routes []string
+	for path, item := range doc.Paths {
+		for key := range item {
+			if slices.Contains(methods, key) {
+				routes = append(routes, strings.ToUpper(key)+" "+path)
+			}
+		}
+	}
+	slices.Sort(routes)
+	return routes, nil
+}
@@ -0,0 +1,367 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	"github.com/junghwan16/test-server/internal/shared/problem"
+)
+
+// maxBodySize bounds the request bodies read for validation
+const maxBodySize = 1 << 20
+
+// resourceURL is the name the document is registered under for $ref resolution
+const resourceURL = "openapi.json"
+
+var printer = message.NewPrinter(language.English)
+
+type operation struct {
+	method       string
+	segments     []string // "{name}" marks a path parameter
+	params       []parameter
+	body         *jsonschema.Schema // nil if the operation takes no body
+	bodyRequired bool
+}
+
+type parameter struct {
+	name     string
+	in       string // path, query or header
+	required bool
+	schema   *jsonschema.Schema
+	typ      string // schema type, used to convert the raw string
+}
+
+type rawParameter struct {
+	Name     string `json:"name"`
+	In       string `json:"in"`
+	Required bool   `json:"required"`
+	Schema   struct {
+		Type string `json:"type"`
+	} `json:"schema"`
+}
+
+type rawOperation struct {
+	Parameters  []rawParameter `json:"parameters"`
+	RequestBody *struct {
+		Required bool                       `json:"required"`
+		Content  map[string]json.RawMessage `json:"content"`
+	} `json:"requestBody"`
+}
+
+// Validate returns middleware that rejects requests whose parameters or JSON
+// body do not match the document, answering with validation_failed problem
+// details. Requests for paths the document does not describe pass through.
+// Parameters must be declared inline; formats are annotations only.
+func Validate() (func(http.Handler) http.Handler, error) {
+	ops, err := compile()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			op, pathValues := match(ops, r.Method, r.URL.Path)
+			if op == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if p := op.validate(r, pathValues); p != nil {
+				problem.Write(w, r, p)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+// compile builds a validator for every operation in the document
+func compile() ([]*operation, error) {
+	root, err := jsonschema.UnmarshalJSON(bytes.NewReader(spec))
+	if err != nil {
+		return nil, fmt.Errorf("openapi: parse document: %w", err)
+	}
+	c := jsonschema.NewCompiler()
+	c.DefaultDraft(jsonschema.Draft2020)
+	if err := c.AddResource(resourceURL, root); err != nil {
+		return nil, err
+	}
+
+	var doc document
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return nil, fmt.Errorf("openapi: parse document: %w", err)
+	}
+
+	var ops []*operation
+	for path, item := range doc.Paths {
+		var shared []rawParameter
+		if raw, ok := item["parameters"]; ok {
+			if err := json.Unmarshal(raw, &shared); err != nil {
+				return nil, fmt.Errorf("openapi: %s parameters: %w", path, err)
+			}
+		}
+		itemPtr := "/paths/" + escapePointer(path)
+
+		for method, raw := range item {
+			if !slices.Contains(methods, method) {
+				continue
+			}
+			var ro rawOperation
+			if err := json.Unmarshal(raw, &ro); err != nil {
+				return nil, fmt.Errorf("openapi: %s %s: %w", method, path, err)
+			}
+
+			op := &operation{method: strings.ToUpper(method), segments: strings.Split(path, "/")}
+			for i, rp := range shared {
+				p, err := compileParameter(c, rp, fmt.Sprintf("%s/parameters/%d/schema", itemPtr, i))
+				if err != nil {
+					return nil, err
+				}
+				op.params = append(op.params, p)
+			}
+			opPtr := itemPtr + "/" + method
+			for i, rp := range ro.Parameters {
+				p, err := compileParameter(c, rp, fmt.Sprintf("%s/parameters/%d/schema", opPtr, i))
+				if err != nil {
+					return nil, err
+				}
+				op.params = append(op.params, p)
+			}
+			if rb := ro.RequestBody; rb != nil {
+				if _, ok := rb.Content["application/json"]; ok {
+					op.body, err = compileAt(c, opPtr+"/requestBody/content/application~1json/schema")
+					if err != nil {
+						return nil, err
+					}
+					op.bodyRequired = rb.Required
+				}
+			}
+			ops = append(ops, op)
+		}
+	}
+
+	// Prefer literal segments over parameters when both match
+	slices.SortFunc(ops, func(a, b *operation) int {
+		return a.paramCount() - b.paramCount()
+	})
+	return ops, nil
+}
+
+func compileParameter(c *jsonschema.Compiler, rp rawParameter, ptr string) (parameter, error) {
+	sch, err := compileAt(c, ptr)
+	if err != nil {
+		return parameter{}, err
+	}
+	return parameter{name: rp.Name, in: rp.In, required: rp.Required || rp.In == "path", schema: sch, typ: rp.Schema.Type}, nil
+}
+
+func compileAt(c *jsonschema.Compiler, ptr string) (*jsonschema.Schema, error) {
+	sch, err := c.Compile(resourceURL + "#" + (&url.URL{Fragment: ptr}).EscapedFragment())
+	if err != nil {
+		return nil, fmt.Errorf("openapi: compile %s: %w", ptr, err)
+	}
+	return sch, nil
+}
+
+// escapePointer escapes a key for use as a JSON pointer token
+func escapePointer(s string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
+
+func (op *operation) paramCount() int {
+	n := 0
+	for _, s := range op.segments {
+		if isTemplate(s) {
+			n++
+		}
+	}
+	return n
+}
+
+func isTemplate(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+// match finds the operation for method and path and extracts path parameters
+func match(ops []*operation, method, path string) (*operation, map[string]string) {
+	segments := strings.Split(path, "/")
+	for _, op := range ops {
+		if op.method != method || len(op.segments) != len(segments) {
+			continue
+		}
+		values := make(map[string]string)
+		matched := true
+		for i, s := range op.segments {
+			if isTemplate(s) {
+				values[s[1:len(s)-1]] = segments[i]
+			} else if s != segments[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return op, values
+		}
+	}
+	return nil, nil
+}
+
+// validate checks r against the operation and returns the problem to report, if any
+func (op *operation) validate(r *http.Request, pathValues map[string]string) *problem.Problem {
+	var errs []problem.FieldError
+
+	query := r.URL.Query()
+	for _, p := range op.params {
+		var raw []string
+		switch p.in {
+		case "path":
+			if v, ok := pathValues[p.name]; ok {
+				raw = []string{v}
+			}
+		case "query":
+			raw = query[p.name]
+		case "header":
+			raw = r.Header.Values(p.name)
+		}
+		if len(raw) == 0 {
+			if p.required {
+				errs = append(errs, problem.FieldError{Field: p.name, Code: "required", Detail: p.name + " is required"})
+			}
+			continue
+		}
+		if err := p.schema.Validate(p.convert(raw)); err != nil {
+			errs = append(errs, schemaErrors(p.name, err)...)
+		}
+	}
+
+	if op.body != nil {
+		p, bodyErrs := op.validateBody(r)
+		if p != nil {
+			return p
+		}
+		errs = append(errs, bodyErrs...)
+	}
+
+	if len(errs) > 0 {
+		return problem.Validation.WithErrors(errs...)
+	}
+	return nil
+}
+
+// validateBody checks the JSON body and puts it back for the handler
+func (op *operation) validateBody(r *http.Request) (*problem.Problem, []problem.FieldError) {
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+	if err != nil {
+		return problem.MalformedRequest, nil
+	}
+	if len(data) > maxBodySize {
+		return problem.MalformedRequest.WithDetail("request body is too large"), nil
+	}
+	r.Body = io.NopCloser(bytes.NewReader(data))
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		if op.bodyRequired {
+			return nil, []problem.FieldError{{Field: "body", Code: "required", Detail: "request body is required"}}
+		}
+		return nil, nil
+	}
+
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		if mt, _, err := mime.ParseMediaType(ct); err != nil || mt != "application/json" {
+			return problem.UnsupportedMediaType.WithDetail("send application/json"), nil
+		}
+	}
+
+	body, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return problem.MalformedRequest, nil
+	}
+	if err := op.body.Validate(body); err != nil {
+		return nil, schemaErrors("", err)
+	}
+	return nil, nil
+}
+
+// convert turns raw parameter strings into the JSON value the schema expects.
+// Values that do not convert are left as strings so the schema reports them.
+func (p parameter) convert(raw []string) any {
+	if p.typ == "array" {
+		var items []any
+		for _, v := range raw {
+			for item := range strings.SplitSeq(v, ",") {
+				items = append(items, strings.TrimSpace(item))
+			}
+		}
+		return items
+	}
+
+	v := raw[0]
+	switch p.typ {
+	case "integer", "number":
+		if _, err := strconv.ParseFloat(v, 64); err == nil {
+			return json.Number(v)
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return v
+}
+
+// schemaErrors flattens a validation error into one FieldError per failed
+// keyword, naming fields by their dotted path under prefix
+func schemaErrors(prefix string, err error) []problem.FieldError {
+	var ve *jsonschema.ValidationError
+	if !errors.As(err, &ve) {
+		return []problem.FieldError{{Field: prefix, Code: "invalid", Detail: err.Error()}}
+	}
+
+	var out []problem.FieldError
+	var walk func(e *jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) > 0 {
+			for _, c := range e.Causes {
+				walk(c)
+			}
+			return
+		}
+		field := strings.Join(append(splitField(prefix), e.InstanceLocation...), ".")
+		switch k := e.ErrorKind.(type) {
+		case *kind.Required:
+			for _, name := range k.Missing {
+				f := strings.Join(append(splitField(field), name), ".")
+				out = append(out, problem.FieldError{Field: f, Code: "required", Detail: f + " is required"})
+			}
+		case *kind.AdditionalProperties:
+			for _, name := range k.Properties {
+				f := strings.Join(append(splitField(field), name), ".")
+				out = append(out, problem.FieldError{Field: f, Code: "unknown_field", Detail: f + " is not allowed"})
+			}
+		default:
+			out = append(out, problem.FieldError{Field: field, Code: "invalid", Detail: e.ErrorKind.LocalizedString(printer)})
+		}
+	}
+	walk(ve)
+	return out
+}
+
+func splitField(field string) []string {
+	if field == "" {
+		return nil
+	}
+	return []string{field}
+}
@@ -0,0 +1,107 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/junghwan16/test-server/internal/shared/problem"
+)
+
+func TestValidate(t *testing.T) {
+	validate, err := Validate()
+	if err != nil {
+		t.Fatalf("expected document to compile, got %v", err)
+	}
+	reached := false
+	h := validate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+	}))
+
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		contentType string
+		header      http.Header
+		body        string
+		wantStatus  int
+		wantField   string
+		wantCode    string
+	}{
+		{name: "올바른 본문", method: "POST", target: "/v1/auth/signup", body: `{"email":"a@example.com","password":"password123"}`},
+		{name: "필수 필드 누락", method: "POST", target: "/v1/auth/signup", body: `{"email":"a@example.com"}`, wantStatus: 400, wantField: "password", wantCode: "required"},
+		{name: "타입 불일치", method: "PATCH", target: "/v1/admin/users/0190a6e4-8a3b-7c2d-9e1f-123456789abc", body: `{"active":"yes"}`, wantStatus: 400, wantField: "active", wantCode: "invalid"},
+		{name: "본문 없음", method: "POST", target: "/v1/password/reset/confirm", wantStatus: 400, wantField: "body", wantCode: "required"},
+		{name: "JSON 아닌 본문", method: "POST", target: "/v1/auth/login", contentType: "text/plain", body: `email=a`, wantStatus: 415},
+		{name: "깨진 JSON", method: "POST", target: "/v1/auth/login", body: `{"email":`, wantStatus: 400},
+		{name: "정수 쿼리", method: "GET", target: "/v1/admin/users?limit=10&offset=0"},
+		{name: "범위 밖 쿼리", method: "GET", target: "/v1/admin/users?limit=500", wantStatus: 400, wantField: "limit", wantCode: "invalid"},
+		{name: "필수 쿼리 누락", method: "GET", target: "/v1/verification/verify", wantStatus: 400, wantField: "token", wantCode: "required"},
+		{name: "잘못된 헤더", method: "GET", target: "/v1/admin/events/stream", header: http.Header{"Last-Event-Id": {"abc"}}, wantStatus: 400, wantField: "Last-Event-ID", wantCode: "invalid"},
+		{name: "문서에 없는 경로", method: "GET", target: "/unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given: 요청
+			reached = false
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.body != "" {
+				ct := tt.contentType
+				if ct == "" {
+					ct = "application/json"
+				}
+				req.Header.Set("Content-Type", ct)
+			}
+			for k, v := range tt.header {
+				req.Header[k] = v
+			}
+
+			// When: 검증 미들웨어 통과
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			// Then: 통과하거나 지정된 필드 오류
+			if tt.wantStatus == 0 {
+				if !reached {
+					t.Errorf("expected request to reach handler, got %d %s", rec.Code, rec.Body)
+				}
+				return
+			}
+			if reached || rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d before handler, got %d (reached %v)", tt.wantStatus, rec.Code, reached)
+			}
+			if tt.wantField == "" {
+				return
+			}
+			var p problem.Problem
+			json.NewDecoder(rec.Body).Decode(&p)
+			if len(p.Errors) != 1 || p.Errors[0].Field != tt.wantField || p.Errors[0].Code != tt.wantCode {
+				t.Errorf("expected %s %s, got %+v", tt.wantField, tt.wantCode, p.Errors)
+			}
+		})
+	}
+}
+
+func TestValidate_KeepsBody(t *testing.T) {
+	// Given: 검증 뒤 본문을 읽는 핸들러
+	validate, _ := Validate()
+	var got string
+	h := validate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct{ Email string }
+		json.NewDecoder(r.Body).Decode(&body)
+		got = body.Email
+	}))
+
+	// When: 올바른 요청
+	req := httptest.NewRequest("POST", "/v1/auth/login", strings.NewReader(`{"email":"a@example.com","password":"password123"}`))
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	// Then: 핸들러가 같은 본문을 받음
+	if got != "a@example.com" {
+		t.Errorf("expected handler to read the body, got %q", got)
+	}
+}
@@ -0,0 +1,153 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/junghwan16/test-server/internal/identity/handler"
+	"github.com/junghwan16/test-server/internal/shared/infrastructure/metrics"
+	"github.com/junghwan16/test-server/internal/shared/infrastructure/ratelimit"
+	"github.com/junghwan16/test-server/internal/shared/problem"
+)
+
+// maxKeyBodySize bounds the request bodies read to find a rate limit key
+const maxKeyBodySize = 1 << 20
+
+// RatePolicy is a named limit counted separately for each key
+type RatePolicy struct {
+	Name  string
+	Limit ratelimit.Limit
+	Key   RateKey
+}
+
+// RateKey picks what a request is counted against; "" exempts the request
+type RateKey func(r *http.Request) string
+
+// ByIP counts requests per client IP
+func ByIP(r *http.Request) string {
+	return "ip:" + getIP(r)
+}
+
+// ByUser counts requests per signed-in user, or per IP before sign-in.
+// Install it inside RequireAuth.
+func ByUser(r *http.Request) string {
+	if u := handler.GetUserFromContext(r.Context()); u != nil {
+		return "user:" + u.ID().Value()
+	}
+	return ByIP(r)
+}
+
+// ByEmail counts requests per email address in the JSON body, so one
+// account cannot be targeted from many IPs
+func ByEmail(r *http.Request) string {
+	email := strings.ToLower(strings.TrimSpace(bodyField(r, "email")))
+	if email == "" {
+		return ""
+	}
+	return "email:" + digest(email)
+}
+
+// ByToken counts requests per verification or reset token, taken from the
+// token query parameter or JSON body field
+func ByToken(r *http.Request) string {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		token = bodyField(r, "token")
+	}
+	if token == "" {
+		return ""
+	}
+	return "token:" + digest(token)
+}
+
+// RateLimit rejects requests over any of policies with 429 and counts them
+// in m. Every policy is checked, so one request spends from each budget.
+// Responses carry RateLimit-* headers for the policy closest to its limit.
+// Health checks are exempt, and requests pass if the limiter fails.
+func RateLimit(limiter ratelimit.Limiter, m *metrics.Metrics, policies ...RatePolicy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isHealthCheck(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			for _, p := range policies {
+				key := p.Key(r)
+				if key == "" {
+					continue
+				}
+				res, err := limiter.Allow(r.Context(), p.Name+":"+key, p.Limit)
+				if err != nil {
+					continue
+				}
+
+				setRateLimitHeaders(w.Header(), p.Limit, res)
+				if !res.Allowed {
+					m.RateLimited(p.Name)
+					w.Header().Set("Retry-After", seconds(res.RetryAfter))
+					problem.Write(w, r, problem.TooManyRequests)
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// setRateLimitHeaders advertises the policy in RateLimit-Policy and reports
+// its state in RateLimit-Limit, -Remaining and -Reset unless an earlier
+// policy has fewer requests remaining
+func setRateLimitHeaders(h http.Header, l ratelimit.Limit, res ratelimit.Result) {
+	h.Add("RateLimit-Policy", fmt.Sprintf("%d;w=%s", l.Burst, seconds(l.Window())))
+
+	if prev, err := strconv.Atoi(h.Get("RateLimit-Remaining")); err == nil && prev < res.Remaining {
+		return
+	}
+	h.Set("RateLimit-Limit", strconv.Itoa(l.Burst))
+	h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	h.Set("RateLimit-Reset", seconds(res.ResetAfter))
+}
+
+// seconds formats d as whole seconds, rounded up
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64((d+time.Second-1)/time.Second), 10)
+}
+
+// digest keeps personal data and secrets out of limiter keys
+func digest(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:16])
+}
+
+// bodyField reads a string field from a JSON body and puts the body back
+// for the handler
+func bodyField(r *http.Request, name string) string {
+	if r.Body == nil {
+		return ""
+	}
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxKeyBodySize))
+	r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(data), r.Body))
+	if err != nil {
+		return ""
+	}
+
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(data, &fields) != nil {
+		return ""
+	}
+	var value string
+	if json.Unmarshal(fields[name], &value) != nil {
+		return ""
+	}
+	return value
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/junghwan16/test-server/internal/shared/infrastructure/metrics"
+	"github.com/junghwan16/test-server/internal/shared/infrastructure/ratelimit"
+)
+
+func TestRateLimit(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	strict := RatePolicy{Name: "strict", Limit: ratelimit.Limit{Rate: 1, Period: time.Minute, Burst: 2}, Key: ByIP}
+	loose := RatePolicy{Name: "loose", Limit: ratelimit.Limit{Rate: 10, Period: time.Second, Burst: 10}, Key: ByIP}
+
+	t.Run("한도 초과 시 429", func(t *testing.T) {
+		// Given: 버스트 2인 정책
+		h := RateLimit(ratelimit.NewMemory(), metrics.New(), strict)(ok)
+
+		// When: 세 번 요청
+		var rec *httptest.ResponseRecorder
+		for range 3 {
+			rec = httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+		}
+
+		// Then: 마지막 요청은 429 와 Retry-After, RateLimit 헤더
+		if rec.Code != http.StatusTooManyRequests {
+			t.Fatalf("expected 429, got %d", rec.Code)
+		}
+		for header, want := range map[string]string{
+			"Retry-After":         "60",
+			"RateLimit-Limit":     "2",
+			"RateLimit-Remaining": "0",
+			"RateLimit-Reset":     "120",
+			"RateLimit-Policy":    "2;w=120",
+		} {
+			if got := rec.Header().Get(header); got != want {
+				t.Errorf("expected %s %q, got %q", header, want, got)
+			}
+		}
+	})
+
+	t.Run("가장 빠듯한 정책을 보고", func(t *testing.T) {
+		// Given: 느슨한 정책과 빠듯한 정책
+		h := RateLimit(ratelimit.NewMemory(), metrics.New(), loose, strict)(ok)
+
+		// When: 한 번 요청
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+		// Then: 남은 수가 가장 적은 정책의 상태와 두 정책 모두를 알림
+		if got := rec.Header().Get("RateLimit-Remaining"); got != "1" {
+			t.Errorf("expected the strict policy's remaining 1, got %q", got)
+		}
+		if got := rec.Header().Values("RateLimit-Policy"); len(got) != 2 {
+			t.Errorf("expected both policies advertised, got %v", got)
+		}
+	})
+
+	t.Run("이메일별 계산 후 본문 유지", func(t *testing.T) {
+		// Given: 이메일 키 정책과 본문을 읽는 핸들러
+		byEmail := RatePolicy{Name: "email", Limit: ratelimit.Limit{Rate: 1, Period: time.Minute, Burst: 1}, Key: ByEmail}
+		var body string
+		h := RateLimit(ratelimit.NewMemory(), metrics.New(), byEmail)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			data, _ := io.ReadAll(r.Body)
+			body = string(data)
+		}))
+		post := func(email string) int {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest("POST", "/", strings.NewReader(`{"email":"`+email+`"}`)))
+			return rec.Code
+		}
+
+		// When: 같은 이메일의 대소문자만 바꿔 두 번, 다른 이메일로 한 번
+		first := post("a@example.com")
+		second := post("A@Example.com")
+		other := post("b@example.com")
+
+		// Then: 같은 주소는 막히고 다른 주소는 통과하며 핸들러는 본문을 그대로 받음
+		if first != http.StatusOK || second != http.StatusTooManyRequests || other != http.StatusOK {
+			t.Errorf("expected 200, 429, 200, got %d, %d, %d", first, second, other)
+		}
+		if body != `{"email":"b@example.com"}` {
+			t.Errorf("expected the handler to read the body, got %q", body)
+		}
+	})
+
+	t.Run("헬스 체크 제외", func(t *testing.T) {
+		// Given: 버스트 2인 정책
+		h := RateLimit(ratelimit.NewMemory(), metrics.New(), strict)(ok)
+
+		// When: 헬스 체크를 세 번
+		var rec *httptest.ResponseRecorder
+		for range 3 {
+			rec = httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest("GET", "/health/ready", nil))
+		}
+
+		// Then: 제한 없음
+		if rec.Code != http.StatusOK {
+			t.Errorf("expected health checks to be exempt, got %d", rec.Code)
+		}
+	})
+}
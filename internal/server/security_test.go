@@ -0,0 +1,50 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	t.Run("프로덕션 설정", func(t *testing.T) {
+		// Given: HSTS 를 포함한 설정
+		h := SecurityHeaders(SecurityOptions{
+			HSTSMaxAge:            365 * 24 * time.Hour,
+			FrameOptions:          "DENY",
+			ContentSecurityPolicy: "default-src 'none'",
+			ReferrerPolicy:        "no-referrer",
+		})(ok)
+
+		// When: 요청 처리
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+		// Then: 모든 보안 헤더
+		for header, want := range map[string]string{
+			"Strict-Transport-Security": "max-age=31536000; includeSubDomains",
+			"X-Content-Type-Options":    "nosniff",
+			"X-Frame-Options":           "DENY",
+			"Content-Security-Policy":   "default-src 'none'",
+			"Referrer-Policy":           "no-referrer",
+		} {
+			if got := rec.Header().Get(header); got != want {
+				t.Errorf("expected %s %q, got %q", header, want, got)
+			}
+		}
+	})
+
+	t.Run("빈 설정", func(t *testing.T) {
+		// When: 아무 것도 설정하지 않은 요청
+		rec := httptest.NewRecorder()
+		SecurityHeaders(SecurityOptions{})(ok).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+		// Then: nosniff 만 전송
+		if rec.Header().Get("X-Content-Type-Options") != "nosniff" || rec.Header().Get("Strict-Transport-Security") != "" {
+			t.Errorf("expected only nosniff, got %v", rec.Header())
+		}
+	})
+}
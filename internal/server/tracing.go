@@ -0,0 +1,37 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Trace starts a server span for every request except health checks and
+// metrics scrapes, continuing the trace named in the W3C traceparent header.
+// Install it outermost so the span covers the whole middleware chain.
+func Trace(next http.Handler) http.Handler {
+	return otelhttp.NewHandler(next, "http.server",
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return r.Method
+		}),
+		otelhttp.WithFilter(func(r *http.Request) bool {
+			return !isHealthCheck(r.URL.Path) && r.URL.Path != "/metrics"
+		}),
+	)
+}
+
+// nameSpan names the server span after the matched route, e.g.
+// "GET /v1/admin/users/{id}", which keeps span names low-cardinality
+func nameSpan(r *http.Request) {
+	if r.Pattern == "" {
+		return
+	}
+	span := trace.SpanFromContext(r.Context())
+	span.SetName(r.Pattern)
+	if _, route, ok := strings.Cut(r.Pattern, " "); ok {
+		span.SetAttributes(attribute.String("http.route", route))
+	}
+}
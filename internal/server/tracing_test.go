@@ -0,0 +1,59 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTrace(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /v1/users/{id}", Routed(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+	mux.Handle("GET /health/live", Routed(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+	h := Trace(RequestID()(mux))
+
+	t.Run("상위 추적 이어받기", func(t *testing.T) {
+		// Given: traceparent 헤더가 있는 요청
+		req := httptest.NewRequest("GET", "/v1/users/42", nil)
+		req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+		// When: 요청 처리
+		h.ServeHTTP(httptest.NewRecorder(), req)
+
+		// Then: 같은 trace 에서 라우트 이름의 서버 스팬이 생김
+		spans := recorder.Ended()
+		if len(spans) != 1 {
+			t.Fatalf("expected 1 span, got %d", len(spans))
+		}
+		span := spans[0]
+		if span.Name() != "GET /v1/users/{id}" {
+			t.Errorf("expected span named after the route, got %q", span.Name())
+		}
+		if span.SpanContext().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+			t.Errorf("expected the incoming trace ID, got %s", span.SpanContext().TraceID())
+		}
+		if span.Parent().SpanID().String() != "00f067aa0ba902b7" {
+			t.Errorf("expected the incoming span as parent, got %s", span.Parent().SpanID())
+		}
+	})
+
+	t.Run("헬스 체크 제외", func(t *testing.T) {
+		// When: 헬스 체크 요청
+		before := len(recorder.Ended())
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/health/live", nil))
+
+		// Then: 스팬 없음
+		if got := len(recorder.Ended()); got != before {
+			t.Errorf("expected no span for health checks, got %d new", got-before)
+		}
+	})
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+
+	"github.com/junghwan16/test-server/internal/shared/problem"
+)
+
+// VersionHeader names the API version a request asks for and a response was served by
+const VersionHeader = "API-Version"
+
+// versionPrefix matches the /v{N} segment that selects an API version
+var versionPrefix = regexp.MustCompile(`^/v([0-9]+)(/|$)`)
+
+// ProblemUnsupportedVersion is returned for an API version the server does not serve
+var ProblemUnsupportedVersion = problem.New(http.StatusNotFound, "unsupported_api_version", "Unsupported API version")
+
+// NegotiateVersion picks the API version for each request. The /v{N} path
+// prefix is authoritative. Unversioned requests for a versioned route are
+// served by the version named in the API-Version header, or latest if there
+// is none, and are marked deprecated so clients pin a version. Breaking
+// changes go in a new version; older ones stay mounted until retired.
+// Routes that exist without a prefix, such as health checks, are left alone.
+func NegotiateVersion(mux *http.ServeMux, latest int, supported ...int) func(http.Handler) http.Handler {
+	if !slices.Contains(supported, latest) {
+		supported = append(supported, latest)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if m := versionPrefix.FindStringSubmatch(r.URL.Path); m != nil {
+				version, err := strconv.Atoi(m[1])
+				if err != nil || !slices.Contains(supported, version) {
+					problem.Write(w, r, ProblemUnsupportedVersion)
+					return
+				}
+				w.Header().Set(VersionHeader, m[1])
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if _, pattern := mux.Handler(r); pattern != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			version := latest
+			if h := r.Header.Get(VersionHeader); h != "" {
+				v, err := strconv.Atoi(h)
+				if err != nil || !slices.Contains(supported, v) {
+					problem.Write(w, r, ProblemUnsupportedVersion.WithDetail("unknown "+VersionHeader+" "+strconv.Quote(h)))
+					return
+				}
+				version = v
+			}
+
+			prefixed := "/v" + strconv.Itoa(version) + r.URL.Path
+			rewritten := r.Clone(r.Context())
+			rewritten.URL.Path = prefixed
+			rewritten.URL.RawPath = ""
+			if _, pattern := mux.Handler(rewritten); pattern == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set(VersionHeader, strconv.Itoa(version))
+			w.Header().Set("Deprecation", "true")
+			w.Header().Set("Link", "<"+prefixed+`>; rel="successor-version"`)
+			next.ServeHTTP(w, rewritten)
+		})
+	}
+}
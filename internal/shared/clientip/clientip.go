@@ -0,0 +1,152 @@
+// Package clientip resolves the address of the client behind trusted reverse
+// proxies and carries it through the request context.
+package clientip
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying ip
+func NewContext(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, contextKey{}, ip)
+}
+
+// FromContext returns the client IP stored in ctx, or "" if there is none
+func FromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(contextKey{}).(string)
+	return ip
+}
+
+// Forwarding headers a Resolver can be told to believe
+const (
+	HeaderForwarded     = "forwarded"
+	HeaderXForwardedFor = "x-forwarded-for"
+	HeaderXRealIP       = "x-real-ip"
+)
+
+// Resolver finds the client IP of a request. Only the one forwarding header
+// the proxies are known to set is believed, and only when the connection
+// comes from a trusted proxy; a client can send the others freely. The
+// header is read right to left, skipping trusted hops, so a client cannot
+// prepend a fake address either.
+type Resolver struct {
+	trusted []netip.Prefix
+	header  string
+}
+
+// NewResolver creates a Resolver trusting header from proxies in the given
+// CIDRs; a bare address trusts that host only. With no proxies, forwarding
+// headers are ignored.
+func NewResolver(proxies []string, header string) (*Resolver, error) {
+	switch header {
+	case HeaderForwarded, HeaderXForwardedFor, HeaderXRealIP:
+	default:
+		return nil, fmt.Errorf("clientip: unsupported forwarding header %q", header)
+	}
+
+	r := &Resolver{header: header}
+	for _, p := range proxies {
+		prefix, err := netip.ParsePrefix(p)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(p)
+			if addrErr != nil {
+				return nil, fmt.Errorf("clientip: invalid trusted proxy %q: %w", p, err)
+			}
+			prefix = netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen())
+		}
+		r.trusted = append(r.trusted, prefix.Masked())
+	}
+	return r, nil
+}
+
+// Resolve returns the client IP of req
+func (r *Resolver) Resolve(req *http.Request) string {
+	peer, ok := parseHost(req.RemoteAddr)
+	if !ok {
+		return req.RemoteAddr
+	}
+	if !r.isTrusted(peer) {
+		return peer.String()
+	}
+
+	var hops []string
+	switch r.header {
+	case HeaderForwarded:
+		hops = forwardedFor(req.Header.Values("Forwarded"))
+	case HeaderXForwardedFor:
+		hops = splitList(req.Header.Values("X-Forwarded-For"))
+	case HeaderXRealIP:
+		if realIP := req.Header.Get("X-Real-IP"); realIP != "" {
+			hops = []string{realIP}
+		}
+	}
+
+	client := peer
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, ok := parseHost(hops[i])
+		if !ok {
+			// Unknown or obfuscated hop: nothing left of it can be trusted
+			break
+		}
+		client = addr
+		if !r.isTrusted(addr) {
+			break
+		}
+	}
+	return client.String()
+}
+
+func (r *Resolver) isTrusted(addr netip.Addr) bool {
+	for _, p := range r.trusted {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedFor extracts the for= parameter of each RFC 7239 Forwarded element
+func forwardedFor(values []string) []string {
+	var hops []string
+	for _, element := range splitList(values) {
+		var node string
+		for pair := range strings.SplitSeq(element, ";") {
+			key, value, _ := strings.Cut(strings.TrimSpace(pair), "=")
+			if strings.EqualFold(key, "for") {
+				node = strings.Trim(value, `"`)
+			}
+		}
+		hops = append(hops, node)
+	}
+	return hops
+}
+
+// splitList splits comma-separated header values across all header lines
+func splitList(values []string) []string {
+	var items []string
+	for _, v := range values {
+		for item := range strings.SplitSeq(v, ",") {
+			items = append(items, strings.TrimSpace(item))
+		}
+	}
+	return items
+}
+
+// parseHost parses an address with or without a port, IPv6 optionally in brackets
+func parseHost(s string) (netip.Addr, bool) {
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+	addr, err := netip.ParseAddr(strings.Trim(s, "[]"))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
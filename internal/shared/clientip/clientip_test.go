@@ -0,0 +1,82 @@
+package clientip
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResolver(t *testing.T) {
+	trusted := []string{"10.0.0.0/8", "2001:db8::1"}
+
+	tests := []struct {
+		name    string
+		header  string
+		remote  string
+		headers map[string]string
+		want    string
+	}{
+		{"프록시 없음", HeaderXForwardedFor, "203.0.113.7:5000", nil, "203.0.113.7"},
+		{"신뢰하지 않는 연결의 헤더 무시", HeaderXForwardedFor, "203.0.113.7:5000", map[string]string{"X-Forwarded-For": "1.2.3.4"}, "203.0.113.7"},
+		{"신뢰 프록시 뒤 X-Forwarded-For", HeaderXForwardedFor, "10.0.0.2:5000", map[string]string{"X-Forwarded-For": "198.51.100.9"}, "198.51.100.9"},
+		{"오른쪽부터 신뢰 홉 건너뜀", HeaderXForwardedFor, "10.0.0.2:5000", map[string]string{"X-Forwarded-For": "1.2.3.4, 198.51.100.9, 10.0.0.3"}, "198.51.100.9"},
+		{"모두 신뢰 홉이면 가장 왼쪽", HeaderXForwardedFor, "10.0.0.2:5000", map[string]string{"X-Forwarded-For": "10.0.0.4, 10.0.0.3"}, "10.0.0.4"},
+		{"알 수 없는 홉에서 중단", HeaderXForwardedFor, "10.0.0.2:5000", map[string]string{"X-Forwarded-For": "1.2.3.4, garbage"}, "10.0.0.2"},
+		{"X-Real-IP", HeaderXRealIP, "10.0.0.2:5000", map[string]string{"X-Real-IP": "198.51.100.9"}, "198.51.100.9"},
+		{"Forwarded", HeaderForwarded, "10.0.0.2:5000", map[string]string{
+			"Forwarded": `for=192.0.2.60;proto=https, for="[2001:db8:cafe::17]:4711";by=10.0.0.2`,
+		}, "2001:db8:cafe::17"},
+		{"Forwarded 난독화 홉", HeaderForwarded, "10.0.0.2:5000", map[string]string{"Forwarded": "for=192.0.2.60, for=_hidden"}, "10.0.0.2"},
+		{"IPv6 프록시", HeaderXForwardedFor, "[2001:db8::1]:443", map[string]string{"X-Forwarded-For": "198.51.100.9"}, "198.51.100.9"},
+		{"IPv4 매핑 주소", HeaderXForwardedFor, "[::ffff:10.0.0.2]:5000", map[string]string{"X-Forwarded-For": "198.51.100.9"}, "198.51.100.9"},
+		// 프록시가 X-Forwarded-For 만 덮어쓰고 다른 헤더는 클라이언트 값 그대로 전달
+		{"설정하지 않은 Forwarded 위조 무시", HeaderXForwardedFor, "10.0.0.2:5000", map[string]string{
+			"Forwarded":       "for=1.2.3.4",
+			"X-Forwarded-For": "198.51.100.9",
+		}, "198.51.100.9"},
+		{"설정하지 않은 X-Real-IP 위조 무시", HeaderXForwardedFor, "10.0.0.2:5000", map[string]string{"X-Real-IP": "1.2.3.4"}, "10.0.0.2"},
+		{"설정하지 않은 X-Forwarded-For 위조 무시", HeaderXRealIP, "10.0.0.2:5000", map[string]string{
+			"X-Forwarded-For": "1.2.3.4",
+			"X-Real-IP":       "198.51.100.9",
+		}, "198.51.100.9"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given: 설정한 헤더를 믿는 Resolver, 연결 주소와 전달 헤더
+			r, err := NewResolver(trusted, tt.header)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tt.remote
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			// When: 클라이언트 IP 확인
+			got := r.Resolve(req)
+
+			// Then: 기대한 주소
+			if got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNewResolver_Invalid(t *testing.T) {
+	// When: 잘못된 CIDR
+	_, err := NewResolver([]string{"10.0.0.0/33"}, HeaderXForwardedFor)
+
+	// Then: 오류
+	if err == nil {
+		t.Error("expected an error for an invalid CIDR")
+	}
+
+	// When: 지원하지 않는 헤더
+	_, err = NewResolver(nil, "x-client-ip")
+
+	// Then: 오류
+	if err == nil {
+		t.Error("expected an error for an unsupported header")
+	}
+}
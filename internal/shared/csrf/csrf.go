@@ -0,0 +1,49 @@
+// Package csrf issues and checks the tokens that prove a cookie-authenticated
+// request was sent by the application rather than by another site.
+package csrf
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+)
+
+// Header is the request header a token is sent back in; login also answers with it
+const Header = "X-CSRF-Token"
+
+// CookieName is the script-readable cookie login stores the token in
+const CookieName = "csrf_token"
+
+// MinKeySize is the shortest accepted signing key
+const MinKeySize = 32
+
+// ErrKeyTooShort is returned for a signing key shorter than MinKeySize
+var ErrKeyTooShort = errors.New("csrf: key must be at least 32 bytes")
+
+// Tokens derives a token from each session ID with a keyed MAC, so tokens
+// need no storage, stay valid exactly as long as their session, and cannot
+// be computed by anyone who does not hold the session cookie and the key
+type Tokens struct {
+	key []byte
+}
+
+// NewTokens creates Tokens signing with key
+func NewTokens(key []byte) (*Tokens, error) {
+	if len(key) < MinKeySize {
+		return nil, ErrKeyTooShort
+	}
+	return &Tokens{key: key}, nil
+}
+
+// Issue returns the token for sessionID
+func (t *Tokens) Issue(sessionID string) string {
+	mac := hmac.New(sha256.New, t.key)
+	mac.Write([]byte("csrf\x00" + sessionID))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Valid reports whether token was issued for sessionID
+func (t *Tokens) Valid(sessionID, token string) bool {
+	return token != "" && hmac.Equal([]byte(token), []byte(t.Issue(sessionID)))
+}
@@ -0,0 +1,53 @@
+package csrf
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestTokens(t *testing.T) {
+	tokens, err := NewTokens(bytes.Repeat([]byte{1}, MinKeySize))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	t.Run("세션에 묶인 토큰", func(t *testing.T) {
+		// When: 세션에 토큰 발급
+		token := tokens.Issue("session-a")
+
+		// Then: 같은 세션에서만 유효
+		if !tokens.Valid("session-a", token) {
+			t.Error("expected the token to be valid for its session")
+		}
+		if tokens.Valid("session-b", token) {
+			t.Error("expected the token to be invalid for another session")
+		}
+		if tokens.Valid("session-a", "") {
+			t.Error("expected an empty token to be invalid")
+		}
+	})
+
+	t.Run("다른 키", func(t *testing.T) {
+		// Given: 다른 키로 만든 Tokens
+		other, _ := NewTokens(bytes.Repeat([]byte{2}, MinKeySize))
+
+		// When: 다른 키로 발급한 토큰 확인
+		valid := tokens.Valid("session-a", other.Issue("session-a"))
+
+		// Then: 무효
+		if valid {
+			t.Error("expected a token signed with another key to be invalid")
+		}
+	})
+
+	t.Run("짧은 키", func(t *testing.T) {
+		// When: 짧은 키
+		_, err := NewTokens([]byte("short"))
+
+		// Then: ErrKeyTooShort
+		if !errors.Is(err, ErrKeyTooShort) {
+			t.Errorf("expected ErrKeyTooShort, got %v", err)
+		}
+	})
+}
@@ -1,8 +1,14 @@
 package domain
 
-// EventBus publishes domain events
+import (
+	"context"
+	"errors"
+)
+
+// EventBus publishes domain events. ctx carries request-scoped values such as
+// the request ID through to subscribers; publishing must not depend on its deadline.
 type EventBus interface {
-	Publish(event DomainEvent) error
+	Publish(ctx context.Context, event DomainEvent) error
 }
 
 // SimpleEventBus is a simple in-memory event bus
@@ -10,8 +16,22 @@ type SimpleEventBus struct {
 	handlers []EventHandler
 }
 
-// EventHandler handles domain events
-type EventHandler func(event DomainEvent) error
+// EventHandler handles domain events; ctx carries the publisher's request-scoped values
+type EventHandler func(ctx context.Context, event DomainEvent) error
+
+type eventIDKey struct{}
+
+// WithEventID returns a copy of ctx carrying the ID the bus stored the event under
+func WithEventID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, eventIDKey{}, id)
+}
+
+// EventIDFromContext returns the ID the bus stored the event under, or "" for
+// buses that do not persist events
+func EventIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(eventIDKey{}).(string)
+	return id
+}
 
 // NewSimpleEventBus creates a new simple event bus
 func NewSimpleEventBus() *SimpleEventBus {
@@ -26,12 +46,37 @@ func (b *SimpleEventBus) Subscribe(handler EventHandler) {
 }
 
 // Publish publishes an event to all handlers
-func (b *SimpleEventBus) Publish(event DomainEvent) error {
+func (b *SimpleEventBus) Publish(ctx context.Context, event DomainEvent) error {
+	ctx = context.WithoutCancel(ctx)
 	for _, handler := range b.handlers {
-		if err := handler(event); err != nil {
+		if err := handler(ctx, event); err != nil {
 			// Log error but continue
 			continue
 		}
 	}
 	return nil
 }
+
+// EventCollector buffers published events so they can be released later,
+// e.g. only after a database transaction commits
+type EventCollector struct {
+	events []DomainEvent
+}
+
+// Publish records the event
+func (c *EventCollector) Publish(_ context.Context, event DomainEvent) error {
+	c.events = append(c.events, event)
+	return nil
+}
+
+// Release publishes all recorded events to bus in order and clears the buffer
+func (c *EventCollector) Release(ctx context.Context, bus EventBus) error {
+	var errs []error
+	for _, event := range c.events {
+		if err := bus.Publish(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	c.events = nil
+	return errors.Join(errs...)
+}
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var ErrUnknownEventType = errors.New("unknown event type")
+
+// EventRegistry maps event types to concrete event structs so that events
+// can be serialized and restored across process boundaries
+type EventRegistry struct {
+	mu       sync.RWMutex
+	decoders map[string]func(occurredAt time.Time, payload []byte) (DomainEvent, error)
+}
+
+// NewEventRegistry creates an empty event registry
+func NewEventRegistry() *EventRegistry {
+	return &EventRegistry{
+		decoders: make(map[string]func(time.Time, []byte) (DomainEvent, error)),
+	}
+}
+
+type occurrenceRestorer interface {
+	restoreOccurredAt(t time.Time)
+}
+
+func (e *BaseEvent) restoreOccurredAt(t time.Time) {
+	e.occurredAt = t
+}
+
+// RegisterEvent registers the concrete event type T with the registry.
+// T must embed BaseEvent so its occurrence time can be restored.
+func RegisterEvent[T DomainEvent](r *EventRegistry) {
+	var zero T
+	eventType := zero.EventType()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.decoders[eventType] = func(occurredAt time.Time, payload []byte) (DomainEvent, error) {
+		var event T
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return nil, fmt.Errorf("decode %s: %w", eventType, err)
+		}
+		if restorer, ok := any(&event).(occurrenceRestorer); ok {
+			restorer.restoreOccurredAt(occurredAt)
+		}
+		return event, nil
+	}
+}
+
+// Encode serializes the event payload
+func (r *EventRegistry) Encode(event DomainEvent) ([]byte, error) {
+	return json.Marshal(event)
+}
+
+// Decode restores an event of the given type from its payload
+func (r *EventRegistry) Decode(eventType string, occurredAt time.Time, payload []byte) (DomainEvent, error) {
+	r.mu.RLock()
+	decode, ok := r.decoders[eventType]
+	r.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEventType, eventType)
+	}
+	return decode(occurredAt, payload)
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type testEvent struct {
+	BaseEvent
+	Name string `json:"name"`
+}
+
+func (e testEvent) EventType() string { return "test.event" }
+
+func TestEventRegistry_RoundTrip(t *testing.T) {
+	// Given: 등록된 이벤트 타입
+	registry := NewEventRegistry()
+	RegisterEvent[testEvent](registry)
+
+	occurredAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	payload, err := registry.Encode(testEvent{BaseEvent: NewBaseEvent(), Name: "alice"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// When: 이벤트 복원
+	event, err := registry.Decode("test.event", occurredAt, payload)
+
+	// Then: 필드와 발생 시각이 복원됨
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	restored, ok := event.(testEvent)
+	if !ok {
+		t.Fatalf("expected testEvent, got %T", event)
+	}
+	if restored.Name != "alice" {
+		t.Errorf("expected name alice, got %s", restored.Name)
+	}
+	if !restored.OccurredAt().Equal(occurredAt) {
+		t.Errorf("expected occurredAt %v, got %v", occurredAt, restored.OccurredAt())
+	}
+}
+
+func TestEventRegistry_UnknownType(t *testing.T) {
+	// Given: 빈 레지스트리
+	registry := NewEventRegistry()
+
+	// When: 등록되지 않은 타입 복원
+	_, err := registry.Decode("unknown", time.Now(), []byte("{}"))
+
+	// Then: ErrUnknownEventType
+	if !errors.Is(err, ErrUnknownEventType) {
+		t.Errorf("expected ErrUnknownEventType, got %v", err)
+	}
+}
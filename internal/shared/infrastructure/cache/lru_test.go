@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRU(t *testing.T) {
+	t.Run("용량 초과 시 가장 오래 안 쓴 항목 제거", func(t *testing.T) {
+		// Given: 용량 2인 캐시에 a, b 저장 후 a 조회
+		c := NewLRU[string, int](2, 0)
+		c.Set("a", 1)
+		c.Set("b", 2)
+		c.Get("a")
+
+		// When: c 저장
+		c.Set("c", 3)
+
+		// Then: b 가 제거되고 a, c 는 남음
+		if _, ok := c.Get("b"); ok {
+			t.Error("expected b to be evicted")
+		}
+		if v, ok := c.Get("a"); !ok || v != 1 {
+			t.Errorf("expected a=1, got %v %v", v, ok)
+		}
+		if v, ok := c.Get("c"); !ok || v != 3 {
+			t.Errorf("expected c=3, got %v %v", v, ok)
+		}
+		if c.Len() != 2 {
+			t.Errorf("expected 2 entries, got %d", c.Len())
+		}
+	})
+
+	t.Run("TTL 만료", func(t *testing.T) {
+		// Given: TTL 이 짧은 캐시
+		c := NewLRU[string, int](10, 20*time.Millisecond)
+		c.Set("a", 1)
+
+		// When: TTL 경과
+		time.Sleep(40 * time.Millisecond)
+
+		// Then: 조회되지 않음
+		if _, ok := c.Get("a"); ok {
+			t.Error("expected a to expire")
+		}
+	})
+
+	t.Run("삭제와 비우기", func(t *testing.T) {
+		// Given: 항목이 있는 캐시
+		c := NewLRU[string, int](10, 0)
+		c.Set("a", 1)
+		c.Set("b", 2)
+
+		// When: a 삭제 후 전체 비우기
+		c.Delete("a")
+		_, okAfterDelete := c.Get("a")
+		c.Purge()
+
+		// Then: 모두 제거됨
+		if okAfterDelete {
+			t.Error("expected a to be deleted")
+		}
+		if c.Len() != 0 {
+			t.Errorf("expected empty cache, got %d", c.Len())
+		}
+	})
+}
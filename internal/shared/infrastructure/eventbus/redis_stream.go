@@ -0,0 +1,429 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/junghwan16/test-server/internal/shared/domain"
+	"github.com/junghwan16/test-server/internal/shared/requestid"
+)
+
+const (
+	fieldType       = "type"
+	fieldOccurredAt = "occurred_at"
+	fieldPayload    = "payload"
+	fieldSealed     = "sealed_payload"
+	fieldRequestID  = "request_id"
+)
+
+// PayloadCipher encrypts event payloads before they reach Redis;
+// *keyring.Keyring implements it
+type PayloadCipher interface {
+	Encrypt(plaintext, additionalData []byte) (string, error)
+	Decrypt(value string, additionalData []byte) ([]byte, error)
+}
+
+// RedisStreamOptions configures a RedisStreamEventBus
+type RedisStreamOptions struct {
+	// Stream is the Redis key of the stream
+	Stream string
+	// MaxLen caps the stream length (approximate trimming); 0 disables trimming
+	MaxLen int64
+	// Consumer names this process within every consumer group
+	Consumer string
+	// BlockTimeout bounds how long a read waits for new entries
+	BlockTimeout time.Duration
+	// ClaimMinIdle is how long an entry may stay pending before another consumer reclaims it
+	ClaimMinIdle time.Duration
+	// MaxDeliveries drops an entry after this many failed deliveries; 0 retries forever
+	MaxDeliveries int64
+	// BatchSize is the maximum number of entries read per call
+	BatchSize int64
+	// Cipher encrypts payloads, which may carry personal data; nil stores them as plain JSON
+	Cipher PayloadCipher
+}
+
+func (o *RedisStreamOptions) setDefaults() {
+	if o.Stream == "" {
+		o.Stream = "events"
+	}
+	if o.Consumer == "" {
+		o.Consumer = "consumer"
+	}
+	if o.BlockTimeout <= 0 {
+		o.BlockTimeout = 5 * time.Second
+	}
+	if o.ClaimMinIdle <= 0 {
+		o.ClaimMinIdle = time.Minute
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = 32
+	}
+}
+
+type subscription struct {
+	group   string // empty for broadcast subscriptions
+	handler domain.EventHandler
+}
+
+// RedisStreamEventBus publishes domain events to a Redis Stream and delivers
+// them to subscribers through consumer groups. Subscribers sharing a group
+// split the stream between replicas; a group per replica receives every event.
+type RedisStreamEventBus struct {
+	client   redis.UniversalClient
+	registry *domain.EventRegistry
+	opts     RedisStreamOptions
+	logger   *slog.Logger
+
+	mu            sync.Mutex
+	subscriptions []subscription
+	cancel        context.CancelFunc
+	wg            sync.WaitGroup
+}
+
+// NewRedisStreamEventBus creates a new Redis Streams backed event bus
+func NewRedisStreamEventBus(
+	client redis.UniversalClient,
+	registry *domain.EventRegistry,
+	opts RedisStreamOptions,
+	logger *slog.Logger,
+) *RedisStreamEventBus {
+	opts.setDefaults()
+	return &RedisStreamEventBus{
+		client:   client,
+		registry: registry,
+		opts:     opts,
+		logger:   logger,
+	}
+}
+
+// Publish appends the event to the stream, along with the request ID in ctx
+// so handlers in other processes can correlate their work with the request
+func (b *RedisStreamEventBus) Publish(ctx context.Context, event domain.DomainEvent) error {
+	payload, err := b.registry.Encode(event)
+	if err != nil {
+		return err
+	}
+
+	values := map[string]any{
+		fieldType:       event.EventType(),
+		fieldOccurredAt: event.OccurredAt().UTC().Format(time.RFC3339Nano),
+	}
+	if b.opts.Cipher != nil {
+		sealed, err := b.opts.Cipher.Encrypt(payload, payloadAAD(event.EventType()))
+		if err != nil {
+			return err
+		}
+		values[fieldSealed] = sealed
+	} else {
+		values[fieldPayload] = payload
+	}
+	if id := requestid.FromContext(ctx); id != "" {
+		values[fieldRequestID] = id
+	}
+	args := &redis.XAddArgs{
+		Stream: b.opts.Stream,
+		Values: values,
+	}
+	if b.opts.MaxLen > 0 {
+		args.MaxLen = b.opts.MaxLen
+		args.Approx = true
+	}
+
+	return b.client.XAdd(context.WithoutCancel(ctx), args).Err()
+}
+
+// Subscribe registers a handler under a consumer group. Must be called before Start.
+func (b *RedisStreamEventBus) Subscribe(group string, handler domain.EventHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscriptions = append(b.subscriptions, subscription{group: group, handler: handler})
+}
+
+// SubscribeBroadcast registers a handler that receives every event appended
+// after Start, without a consumer group or acknowledgements. Use it for
+// per-instance concerns such as cache invalidation or live dashboards.
+// Must be called before Start.
+func (b *RedisStreamEventBus) SubscribeBroadcast(handler domain.EventHandler) {
+	b.Subscribe("", handler)
+}
+
+// Start creates the consumer groups and starts one consumer loop per subscription
+func (b *RedisStreamEventBus) Start(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.cancel != nil {
+		return errors.New("event bus already started")
+	}
+
+	for _, sub := range b.subscriptions {
+		if sub.group == "" {
+			continue
+		}
+		err := b.client.XGroupCreateMkStream(ctx, b.opts.Stream, sub.group, "$").Err()
+		if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+			return fmt.Errorf("create consumer group %s: %w", sub.group, err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	b.cancel = cancel
+
+	lastID, err := b.lastEntryID(ctx)
+	if err != nil {
+		cancel()
+		b.cancel = nil
+		return err
+	}
+
+	for _, sub := range b.subscriptions {
+		b.wg.Add(1)
+		go func() {
+			defer b.wg.Done()
+			if sub.group == "" {
+				b.broadcast(ctx, sub, lastID)
+			} else {
+				b.consume(ctx, sub)
+			}
+		}()
+	}
+
+	return nil
+}
+
+// Close stops all consumer loops and waits for in-flight handlers to finish
+func (b *RedisStreamEventBus) Close() {
+	b.mu.Lock()
+	cancel := b.cancel
+	b.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	b.wg.Wait()
+}
+
+func (b *RedisStreamEventBus) consume(ctx context.Context, sub subscription) {
+	lastClaim := time.Time{}
+
+	for ctx.Err() == nil {
+		if time.Since(lastClaim) >= b.opts.ClaimMinIdle/2 {
+			b.reclaim(ctx, sub)
+			lastClaim = time.Now()
+		}
+
+		streams, err := b.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    sub.group,
+			Consumer: b.opts.Consumer,
+			Streams:  []string{b.opts.Stream, ">"},
+			Count:    b.opts.BatchSize,
+			Block:    b.opts.BlockTimeout,
+		}).Result()
+		if err != nil {
+			if errors.Is(err, redis.Nil) || ctx.Err() != nil {
+				continue
+			}
+			b.logger.Error("event bus: read failed", "group", sub.group, "error", err)
+			sleep(ctx, time.Second)
+			continue
+		}
+
+		for _, stream := range streams {
+			for _, msg := range stream.Messages {
+				b.handle(ctx, sub, msg)
+			}
+		}
+	}
+}
+
+// broadcast reads every entry after lastID without a consumer group
+func (b *RedisStreamEventBus) broadcast(ctx context.Context, sub subscription, lastID string) {
+	for ctx.Err() == nil {
+		streams, err := b.client.XRead(ctx, &redis.XReadArgs{
+			Streams: []string{b.opts.Stream, lastID},
+			Count:   b.opts.BatchSize,
+			Block:   b.opts.BlockTimeout,
+		}).Result()
+		if err != nil {
+			if errors.Is(err, redis.Nil) || ctx.Err() != nil {
+				continue
+			}
+			b.logger.Error("event bus: broadcast read failed", "error", err)
+			sleep(ctx, time.Second)
+			continue
+		}
+
+		for _, stream := range streams {
+			for _, msg := range stream.Messages {
+				lastID = msg.ID
+
+				event, err := b.decode(msg)
+				if err != nil {
+					b.logger.Warn("event bus: skipping undecodable event", "id", msg.ID, "error", err)
+					continue
+				}
+				hctx := entryContext(ctx, msg)
+				if err := sub.handler(hctx, event); err != nil {
+					b.logger.WarnContext(hctx, "event bus: broadcast handler failed",
+						"id", msg.ID,
+						"event_type", event.EventType(),
+						"error", err,
+					)
+				}
+			}
+		}
+	}
+}
+
+// lastEntryID returns the ID of the newest stream entry, so broadcast
+// subscribers start after it without missing entries between reads
+func (b *RedisStreamEventBus) lastEntryID(ctx context.Context) (string, error) {
+	msgs, err := b.client.XRevRangeN(ctx, b.opts.Stream, "+", "-", 1).Result()
+	if err != nil {
+		return "", fmt.Errorf("read stream tail: %w", err)
+	}
+	if len(msgs) == 0 {
+		return "0-0", nil
+	}
+	return msgs[0].ID, nil
+}
+
+// reclaim takes over entries left pending by crashed or stalled consumers
+func (b *RedisStreamEventBus) reclaim(ctx context.Context, sub subscription) {
+	pending, err := b.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: b.opts.Stream,
+		Group:  sub.group,
+		Idle:   b.opts.ClaimMinIdle,
+		Start:  "-",
+		End:    "+",
+		Count:  b.opts.BatchSize,
+	}).Result()
+	if err != nil {
+		if ctx.Err() == nil {
+			b.logger.Error("event bus: pending lookup failed", "group", sub.group, "error", err)
+		}
+		return
+	}
+
+	ids := make([]string, 0, len(pending))
+	for _, p := range pending {
+		if b.opts.MaxDeliveries > 0 && p.RetryCount >= b.opts.MaxDeliveries {
+			b.logger.Error("event bus: dropping event after max deliveries",
+				"group", sub.group,
+				"id", p.ID,
+				"deliveries", p.RetryCount,
+			)
+			b.ack(ctx, sub, p.ID)
+			continue
+		}
+		ids = append(ids, p.ID)
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	msgs, err := b.client.XClaim(ctx, &redis.XClaimArgs{
+		Stream:   b.opts.Stream,
+		Group:    sub.group,
+		Consumer: b.opts.Consumer,
+		MinIdle:  b.opts.ClaimMinIdle,
+		Messages: ids,
+	}).Result()
+	if err != nil {
+		if ctx.Err() == nil {
+			b.logger.Error("event bus: claim failed", "group", sub.group, "error", err)
+		}
+		return
+	}
+
+	for _, msg := range msgs {
+		b.handle(ctx, sub, msg)
+	}
+}
+
+func (b *RedisStreamEventBus) handle(ctx context.Context, sub subscription, msg redis.XMessage) {
+	event, err := b.decode(msg)
+	if err != nil {
+		// Undecodable entries can never succeed; acknowledge so they don't block the group
+		b.logger.Warn("event bus: skipping undecodable event", "group", sub.group, "id", msg.ID, "error", err)
+		b.ack(ctx, sub, msg.ID)
+		return
+	}
+
+	hctx := entryContext(ctx, msg)
+	if err := sub.handler(hctx, event); err != nil {
+		// Leave pending; it will be redelivered once ClaimMinIdle elapses
+		b.logger.WarnContext(hctx, "event bus: handler failed",
+			"group", sub.group,
+			"id", msg.ID,
+			"event_type", event.EventType(),
+			"error", err,
+		)
+		return
+	}
+
+	b.ack(ctx, sub, msg.ID)
+}
+
+// ack acknowledges an entry for the group. A failed ack leaves it pending, so
+// it is logged; the entry is redelivered once ClaimMinIdle elapses.
+func (b *RedisStreamEventBus) ack(ctx context.Context, sub subscription, id string) {
+	if err := b.client.XAck(ctx, b.opts.Stream, sub.group, id).Err(); err != nil && ctx.Err() == nil {
+		b.logger.Error("event bus: ack failed", "group", sub.group, "id", id, "error", err)
+	}
+}
+
+func (b *RedisStreamEventBus) decode(msg redis.XMessage) (domain.DomainEvent, error) {
+	eventType, _ := msg.Values[fieldType].(string)
+	occurredAtRaw, _ := msg.Values[fieldOccurredAt].(string)
+
+	occurredAt, err := time.Parse(time.RFC3339Nano, occurredAtRaw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid occurred_at: %w", err)
+	}
+
+	plain, _ := msg.Values[fieldPayload].(string)
+	payload := []byte(plain)
+	if sealed, ok := msg.Values[fieldSealed].(string); ok {
+		if b.opts.Cipher == nil {
+			return nil, errors.New("encrypted payload but no cipher configured")
+		}
+		if payload, err = b.opts.Cipher.Decrypt(sealed, payloadAAD(eventType)); err != nil {
+			return nil, fmt.Errorf("decrypt payload: %w", err)
+		}
+	}
+
+	return b.registry.Decode(eventType, occurredAt, payload)
+}
+
+// payloadAAD binds an encrypted payload to its event type
+func payloadAAD(eventType string) []byte {
+	return []byte("event:" + eventType)
+}
+
+// entryContext returns ctx carrying the entry ID and the request ID the entry
+// was published with
+func entryContext(ctx context.Context, msg redis.XMessage) context.Context {
+	ctx = domain.WithEventID(ctx, msg.ID)
+	if id, _ := msg.Values[fieldRequestID].(string); id != "" {
+		return requestid.NewContext(ctx, id)
+	}
+	return ctx
+}
+
+func sleep(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+	case <-t.C:
+	}
+}
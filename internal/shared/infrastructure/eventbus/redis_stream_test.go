@@ -0,0 +1,317 @@
+package eventbus
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/junghwan16/test-server/internal/shared/domain"
+	"github.com/junghwan16/test-server/internal/shared/infrastructure/keyring"
+	"github.com/junghwan16/test-server/internal/shared/requestid"
+)
+
+type pingEvent struct {
+	domain.BaseEvent
+	Seq int `json:"seq"`
+}
+
+func (e pingEvent) EventType() string { return "test.ping" }
+
+// newTestClient connects to the Redis at REDIS_TEST_ADDR (default localhost:6379)
+// and skips the test when it is unreachable
+func newTestClient(t *testing.T) *redis.Client {
+	t.Helper()
+
+	addr := os.Getenv("REDIS_TEST_ADDR")
+	if addr == "" {
+		addr = "localhost:6379"
+	}
+
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		t.Skipf("redis not available at %s: %v", addr, err)
+	}
+
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func newTestBus(t *testing.T, client *redis.Client, stream, consumer string) *RedisStreamEventBus {
+	t.Helper()
+
+	registry := domain.NewEventRegistry()
+	domain.RegisterEvent[pingEvent](registry)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewRedisStreamEventBus(client, registry, RedisStreamOptions{
+		Stream:       stream,
+		Consumer:     consumer,
+		BlockTimeout: 100 * time.Millisecond,
+		ClaimMinIdle: 200 * time.Millisecond,
+	}, logger)
+}
+
+func testStream(t *testing.T, client *redis.Client) string {
+	stream := fmt.Sprintf("test:events:%s:%d", t.Name(), time.Now().UnixNano())
+	t.Cleanup(func() { client.Del(context.Background(), stream) })
+	return stream
+}
+
+type collector struct {
+	mu         sync.Mutex
+	events     []pingEvent
+	requestIDs []string
+	eventIDs   []string
+}
+
+func (c *collector) handle(ctx context.Context, event domain.DomainEvent) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.events = append(c.events, event.(pingEvent))
+	c.requestIDs = append(c.requestIDs, requestid.FromContext(ctx))
+	c.eventIDs = append(c.eventIDs, domain.EventIDFromContext(ctx))
+	return nil
+}
+
+func (c *collector) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.events)
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatal("condition not met before deadline")
+}
+
+func TestRedisStreamEventBus_Delivery(t *testing.T) {
+	// Given: 서로 다른 그룹의 두 구독자
+	client := newTestClient(t)
+	stream := testStream(t, client)
+	bus := newTestBus(t, client, stream, "c1")
+
+	var a, b collector
+	bus.Subscribe("group-a", a.handle)
+	bus.Subscribe("group-b", b.handle)
+	if err := bus.Start(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer bus.Close()
+
+	// When: 이벤트 발행
+	for i := range 3 {
+		if err := bus.Publish(context.Background(), pingEvent{BaseEvent: domain.NewBaseEvent(), Seq: i}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	// Then: 각 그룹이 모든 이벤트를 순서대로 받고 ack 함
+	waitFor(t, func() bool { return a.count() == 3 && b.count() == 3 })
+	for i, e := range a.events {
+		if e.Seq != i {
+			t.Errorf("expected seq %d, got %d", i, e.Seq)
+		}
+	}
+
+	pending, err := client.XPending(context.Background(), stream, "group-a").Result()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if pending.Count != 0 {
+		t.Errorf("expected no pending entries, got %d", pending.Count)
+	}
+}
+
+func TestRedisStreamEventBus_RequestID(t *testing.T) {
+	// Given: 그룹 구독자와 브로드캐스트 구독자
+	client := newTestClient(t)
+	stream := testStream(t, client)
+	bus := newTestBus(t, client, stream, "c1")
+
+	var grouped, broadcast collector
+	bus.Subscribe("group", grouped.handle)
+	bus.SubscribeBroadcast(broadcast.handle)
+	if err := bus.Start(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer bus.Close()
+
+	// When: 요청 ID 가 담긴 컨텍스트로 발행
+	ctx := requestid.NewContext(context.Background(), "req-42")
+	if err := bus.Publish(ctx, pingEvent{BaseEvent: domain.NewBaseEvent(), Seq: 1}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := bus.Publish(context.Background(), pingEvent{BaseEvent: domain.NewBaseEvent(), Seq: 2}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Then: 핸들러 컨텍스트에서 같은 요청 ID 를 읽을 수 있음
+	waitFor(t, func() bool { return grouped.count() == 2 && broadcast.count() == 2 })
+	for _, c := range []*collector{&grouped, &broadcast} {
+		if c.requestIDs[0] != "req-42" || c.requestIDs[1] != "" {
+			t.Errorf("expected request IDs [req-42 \"\"], got %q", c.requestIDs)
+		}
+	}
+}
+
+func TestRedisStreamEventBus_EncryptedPayload(t *testing.T) {
+	// Given: 페이로드를 암호화하는 버스와 구독자
+	client := newTestClient(t)
+	stream := testStream(t, client)
+	keys, err := keyring.New(map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)}, "k1", bytes.Repeat([]byte{2}, 32))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	bus := newTestBus(t, client, stream, "c1")
+	bus.opts.Cipher = keys
+
+	var c collector
+	bus.SubscribeBroadcast(c.handle)
+	if err := bus.Start(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer bus.Close()
+
+	// When: 이벤트 발행
+	if err := bus.Publish(context.Background(), pingEvent{BaseEvent: domain.NewBaseEvent(), Seq: 7}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Then: 스트림에는 암호문만 남고 구독자는 원래 이벤트를 받음
+	waitFor(t, func() bool { return c.count() == 1 })
+	if c.events[0].Seq != 7 {
+		t.Errorf("expected seq 7, got %d", c.events[0].Seq)
+	}
+	entries, err := client.XRange(context.Background(), stream, "-", "+").Result()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	sealed, _ := entries[0].Values[fieldSealed].(string)
+	if _, plain := entries[0].Values[fieldPayload]; plain || sealed == "" || strings.Contains(sealed, "seq") {
+		t.Errorf("expected only an encrypted payload, got %v", entries[0].Values)
+	}
+}
+
+func TestRedisStreamEventBus_Broadcast(t *testing.T) {
+	// Given: 이전 이벤트가 있는 스트림과 두 인스턴스의 브로드캐스트 구독자
+	client := newTestClient(t)
+	stream := testStream(t, client)
+
+	old := newTestBus(t, client, stream, "publisher")
+	if err := old.Publish(context.Background(), pingEvent{BaseEvent: domain.NewBaseEvent(), Seq: -1}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var a, b collector
+	busA := newTestBus(t, client, stream, "a")
+	busA.SubscribeBroadcast(a.handle)
+	busB := newTestBus(t, client, stream, "b")
+	busB.SubscribeBroadcast(b.handle)
+	for _, bus := range []*RedisStreamEventBus{busA, busB} {
+		if err := bus.Start(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer bus.Close()
+	}
+
+	// When: 새 이벤트 발행
+	if err := busA.Publish(context.Background(), pingEvent{BaseEvent: domain.NewBaseEvent(), Seq: 1}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Then: 두 인스턴스 모두 새 이벤트만 같은 엔트리 ID 로 받음
+	waitFor(t, func() bool { return a.count() == 1 && b.count() == 1 })
+	if a.events[0].Seq != 1 || b.events[0].Seq != 1 {
+		t.Errorf("expected only the new event, got %v and %v", a.events, b.events)
+	}
+	last, err := client.XRevRangeN(context.Background(), stream, "+", "-", 1).Result()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if a.eventIDs[0] != last[0].ID || b.eventIDs[0] != last[0].ID {
+		t.Errorf("expected event ID %s, got %q and %q", last[0].ID, a.eventIDs[0], b.eventIDs[0])
+	}
+}
+
+func TestRedisStreamEventBus_ReclaimsPendingEntries(t *testing.T) {
+	// Given: 핸들러가 실패하는 컨슈머와 정상 컨슈머가 같은 그룹을 공유
+	client := newTestClient(t)
+	stream := testStream(t, client)
+
+	crashed := newTestBus(t, client, stream, "crashed")
+	crashed.Subscribe("group", func(context.Context, domain.DomainEvent) error {
+		return errors.New("boom")
+	})
+	if err := crashed.Start(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := crashed.Publish(context.Background(), pingEvent{BaseEvent: domain.NewBaseEvent(), Seq: 1}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	waitFor(t, func() bool {
+		p, _ := client.XPending(context.Background(), stream, "group").Result()
+		return p != nil && p.Count == 1
+	})
+	crashed.Close()
+
+	// When: 정상 컨슈머 시작
+	healthy := newTestBus(t, client, stream, "healthy")
+	var c collector
+	healthy.Subscribe("group", c.handle)
+	if err := healthy.Start(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer healthy.Close()
+
+	// Then: 대기 중인 항목을 회수하여 처리
+	waitFor(t, func() bool { return c.count() == 1 })
+	waitFor(t, func() bool {
+		p, _ := client.XPending(context.Background(), stream, "group").Result()
+		return p != nil && p.Count == 0
+	})
+}
+
+func TestRedisStreamEventBus_MaxLen(t *testing.T) {
+	// Given: 길이 제한이 있는 스트림
+	client := newTestClient(t)
+	stream := testStream(t, client)
+	bus := newTestBus(t, client, stream, "c1")
+	bus.opts.MaxLen = 10
+
+	// When: 제한보다 많은 이벤트 발행
+	for i := range 1000 {
+		if err := bus.Publish(context.Background(), pingEvent{BaseEvent: domain.NewBaseEvent(), Seq: i}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	// Then: 스트림이 잘려 있음 (근사 트리밍)
+	length, err := client.XLen(context.Background(), stream).Result()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if length >= 1000 {
+		t.Errorf("expected stream to be trimmed, got length %d", length)
+	}
+}
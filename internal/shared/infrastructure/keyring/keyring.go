@@ -0,0 +1,208 @@
+// Package keyring encrypts individual field values with rotating keys and
+// derives blind indexes so encrypted fields can still be looked up.
+package keyring
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrUnknownKey        = errors.New("value was encrypted with a key not in the keyring")
+	ErrMalformedValue    = errors.New("malformed encrypted value")
+	ErrDecryptionFailure = errors.New("value could not be decrypted")
+)
+
+// keyIDPattern keeps key IDs free of the ":" separator and of SQL LIKE wildcards
+var keyIDPattern = regexp.MustCompile(`^[A-Za-z0-9.-]+$`)
+
+const (
+	keySize      = 32 // AES-256
+	minIndexSize = 32
+)
+
+// Keyring encrypts with AES-256-GCM under its primary key and decrypts values
+// written under any key it holds, so a key can be rotated without rewriting
+// every value at once. Encrypted values look like "<key id>:<base64>".
+type Keyring struct {
+	primary  string
+	aeads    map[string]cipher.AEAD
+	indexKey []byte
+}
+
+// New creates a Keyring from raw 32-byte keys. indexKey keys the blind index;
+// it must stay the same across rotations or existing indexes stop matching.
+func New(keys map[string][]byte, primary string, indexKey []byte) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("keyring: no encryption keys")
+	}
+	if _, ok := keys[primary]; !ok {
+		return nil, fmt.Errorf("keyring: primary key %q not found", primary)
+	}
+	if len(indexKey) < minIndexSize {
+		return nil, fmt.Errorf("keyring: blind index key must be at least %d bytes", minIndexSize)
+	}
+
+	aeads := make(map[string]cipher.AEAD, len(keys))
+	for id, key := range keys {
+		if !keyIDPattern.MatchString(id) {
+			return nil, fmt.Errorf("keyring: invalid key ID %q", id)
+		}
+		if len(key) != keySize {
+			return nil, fmt.Errorf("keyring: key %q must be %d bytes, got %d", id, keySize, len(key))
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		aeads[id] = aead
+	}
+
+	return &Keyring{primary: primary, aeads: aeads, indexKey: slices.Clone(indexKey)}, nil
+}
+
+// Parse creates a Keyring from "id:base64key,id:base64key" and a base64 index
+// key. An empty primary selects the last key listed.
+func Parse(keys, primary, indexKey string) (*Keyring, error) {
+	decoded := make(map[string][]byte)
+	var last string
+	for entry := range strings.SplitSeq(keys, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, errors.New("keyring: key entry must be id:base64key")
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("keyring: key %q is not valid base64", id)
+		}
+		decoded[id] = key
+		last = id
+	}
+	if primary == "" {
+		primary = last
+	}
+
+	index, err := base64.StdEncoding.DecodeString(indexKey)
+	if err != nil {
+		return nil, errors.New("keyring: blind index key is not valid base64")
+	}
+
+	return New(decoded, primary, index)
+}
+
+// keyFile is the JSON layout read by LoadFile
+type keyFile struct {
+	Primary       string            `json:"primary"`
+	Keys          map[string]string `json:"keys"`
+	BlindIndexKey string            `json:"blind_index_key"`
+}
+
+// LoadFile reads a Keyring from a JSON file such as a mounted secret:
+//
+//	{"primary": "2025-06", "keys": {"2025-01": "<base64>", "2025-06": "<base64>"}, "blind_index_key": "<base64>"}
+func LoadFile(path string) (*Keyring, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f keyFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("keyring: parse %s: %w", path, err)
+	}
+	if f.Primary == "" {
+		return nil, fmt.Errorf("keyring: %s has no primary key", path)
+	}
+
+	keys := make(map[string][]byte, len(f.Keys))
+	for id, encoded := range f.Keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("keyring: key %q is not valid base64", id)
+		}
+		keys[id] = key
+	}
+	index, err := base64.StdEncoding.DecodeString(f.BlindIndexKey)
+	if err != nil {
+		return nil, errors.New("keyring: blind index key is not valid base64")
+	}
+
+	return New(keys, f.Primary, index)
+}
+
+// Primary returns the ID of the key new values are encrypted with
+func (k *Keyring) Primary() string {
+	return k.primary
+}
+
+// Encrypt seals plaintext under the primary key. additionalData binds the
+// value to its location (e.g. table, column and row ID), so a ciphertext
+// copied to another row fails to decrypt.
+func (k *Keyring) Encrypt(plaintext, additionalData []byte) (string, error) {
+	aead := k.aeads[k.primary]
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, additionalData)
+
+	return k.primary + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value produced by Encrypt with any key in the keyring
+func (k *Keyring) Decrypt(value string, additionalData []byte) ([]byte, error) {
+	id, encoded, ok := strings.Cut(value, ":")
+	if !ok {
+		return nil, ErrMalformedValue
+	}
+	aead, ok := k.aeads[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, id)
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return nil, ErrMalformedValue
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, ErrDecryptionFailure
+	}
+	return plaintext, nil
+}
+
+// IsCurrent returns true if value was encrypted with the primary key
+func (k *Keyring) IsCurrent(value string) bool {
+	return strings.HasPrefix(value, k.primary+":")
+}
+
+// BlindIndex returns a keyed hash of value for equality lookups and unique
+// constraints on an encrypted column. Normalize value before hashing.
+func (k *Keyring) BlindIndex(value []byte) string {
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write(value)
+	return hex.EncodeToString(mac.Sum(nil))
+}
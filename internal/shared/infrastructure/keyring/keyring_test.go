@@ -0,0 +1,178 @@
+package keyring
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 32)
+}
+
+func TestKeyring(t *testing.T) {
+	index := testKey(9)
+
+	t.Run("암호화 후 복호화", func(t *testing.T) {
+		// Given: 키링
+		k, _ := New(map[string][]byte{"k1": testKey(1)}, "k1", index)
+
+		// When: 같은 값을 두 번 암호화
+		first, err := k.Encrypt([]byte("alice@example.com"), []byte("users.email:1"))
+		second, _ := k.Encrypt([]byte("alice@example.com"), []byte("users.email:1"))
+
+		// Then: 키 ID 가 붙고 매번 다른 암호문이지만 원문으로 복호화됨
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !strings.HasPrefix(first, "k1:") || first == second {
+			t.Errorf("expected distinct k1 ciphertexts, got %q and %q", first, second)
+		}
+		plain, err := k.Decrypt(first, []byte("users.email:1"))
+		if err != nil || string(plain) != "alice@example.com" {
+			t.Errorf("expected alice@example.com, got %q (err %v)", plain, err)
+		}
+	})
+
+	t.Run("다른 행으로 옮긴 암호문은 복호화 실패", func(t *testing.T) {
+		// Given: 1번 행에 묶인 암호문
+		k, _ := New(map[string][]byte{"k1": testKey(1)}, "k1", index)
+		value, _ := k.Encrypt([]byte("alice@example.com"), []byte("users.email:1"))
+
+		// When: 2번 행으로 복호화
+		_, err := k.Decrypt(value, []byte("users.email:2"))
+
+		// Then: ErrDecryptionFailure
+		if !errors.Is(err, ErrDecryptionFailure) {
+			t.Errorf("expected ErrDecryptionFailure, got %v", err)
+		}
+	})
+
+	t.Run("키 교체 후 이전 키로 쓴 값 복호화", func(t *testing.T) {
+		// Given: k1 으로 암호화한 값과 k2 가 주 키인 새 키링
+		old, _ := New(map[string][]byte{"k1": testKey(1)}, "k1", index)
+		value, _ := old.Encrypt([]byte("bob@example.com"), nil)
+		rotated, _ := New(map[string][]byte{"k1": testKey(1), "k2": testKey(2)}, "k2", index)
+
+		// When: 새 키링으로 복호화
+		plain, err := rotated.Decrypt(value, nil)
+
+		// Then: 복호화되지만 현재 키로 쓴 값은 아님
+		if err != nil || string(plain) != "bob@example.com" {
+			t.Errorf("expected bob@example.com, got %q (err %v)", plain, err)
+		}
+		if rotated.IsCurrent(value) {
+			t.Error("expected value written with k1 not to be current")
+		}
+		reencrypted, _ := rotated.Encrypt(plain, nil)
+		if !rotated.IsCurrent(reencrypted) {
+			t.Error("expected re-encrypted value to be current")
+		}
+	})
+
+	t.Run("없는 키", func(t *testing.T) {
+		// Given: k2 로 암호화한 값
+		k2, _ := New(map[string][]byte{"k2": testKey(2)}, "k2", index)
+		value, _ := k2.Encrypt([]byte("x"), nil)
+		k1, _ := New(map[string][]byte{"k1": testKey(1)}, "k1", index)
+
+		// When: k1 만 가진 키링으로 복호화
+		_, err := k1.Decrypt(value, nil)
+
+		// Then: ErrUnknownKey
+		if !errors.Is(err, ErrUnknownKey) {
+			t.Errorf("expected ErrUnknownKey, got %v", err)
+		}
+	})
+
+	t.Run("블라인드 인덱스는 키 교체와 무관", func(t *testing.T) {
+		// Given: 주 키만 다른 두 키링
+		a, _ := New(map[string][]byte{"k1": testKey(1)}, "k1", index)
+		b, _ := New(map[string][]byte{"k1": testKey(1), "k2": testKey(2)}, "k2", index)
+
+		// When: 같은 값의 인덱스 계산
+		ia := a.BlindIndex([]byte("alice@example.com"))
+		ib := b.BlindIndex([]byte("alice@example.com"))
+
+		// Then: 같고, 다른 값과는 다름
+		if ia != ib {
+			t.Errorf("expected equal indexes, got %s and %s", ia, ib)
+		}
+		if ia == a.BlindIndex([]byte("bob@example.com")) {
+			t.Error("expected different values to have different indexes")
+		}
+	})
+}
+
+func TestNew_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    map[string][]byte
+		primary string
+		index   []byte
+	}{
+		{name: "키 없음", keys: map[string][]byte{}, primary: "k1", index: testKey(9)},
+		{name: "없는 주 키", keys: map[string][]byte{"k1": testKey(1)}, primary: "k2", index: testKey(9)},
+		{name: "짧은 키", keys: map[string][]byte{"k1": testKey(1)[:16]}, primary: "k1", index: testKey(9)},
+		{name: "짧은 인덱스 키", keys: map[string][]byte{"k1": testKey(1)}, primary: "k1", index: testKey(9)[:8]},
+		{name: "구분자가 든 키 ID", keys: map[string][]byte{"k:1": testKey(1)}, primary: "k:1", index: testKey(9)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When: 키링 생성
+			_, err := New(tt.keys, tt.primary, tt.index)
+
+			// Then: 에러
+			if err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	// Given: 환경 변수 형식의 키 목록
+	b64 := base64.StdEncoding.EncodeToString
+	spec := "2025-01:" + b64(testKey(1)) + ", 2025-06:" + b64(testKey(2))
+
+	// When: 주 키 없이 파싱
+	k, err := Parse(spec, "", b64(testKey(9)))
+
+	// Then: 마지막 키가 주 키
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if k.Primary() != "2025-06" {
+		t.Errorf("expected primary 2025-06, got %s", k.Primary())
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	// Given: JSON 키 파일
+	b64 := base64.StdEncoding.EncodeToString
+	path := filepath.Join(t.TempDir(), "keys.json")
+	content := `{"primary": "2025-01", "keys": {"2025-01": "` + b64(testKey(1)) + `", "2025-06": "` + b64(testKey(2)) +
+		`"}, "blind_index_key": "` + b64(testKey(9)) + `"}`
+	os.WriteFile(path, []byte(content), 0o600)
+
+	// When: 파일에서 로드
+	k, err := LoadFile(path)
+
+	// Then: 지정한 주 키로 암호화하고 두 키 모두 복호화 가능
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if k.Primary() != "2025-01" {
+		t.Errorf("expected primary 2025-01, got %s", k.Primary())
+	}
+	other, _ := New(map[string][]byte{"2025-06": testKey(2)}, "2025-06", testKey(9))
+	value, _ := other.Encrypt([]byte("x"), nil)
+	if _, err := k.Decrypt(value, nil); err != nil {
+		t.Errorf("expected secondary key to decrypt, got %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+// Package metrics exports the server's Prometheus metrics
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/junghwan16/test-server/internal/shared/domain"
+)
+
+// unmatchedRoute labels requests no route matched, keeping label values bounded
+const unmatchedRoute = "unmatched"
+
+// otherMethod labels requests with a method outside the HTTP standard, which
+// a client can otherwise pick freely
+const otherMethod = "OTHER"
+
+// knownMethods are the methods recorded under their own name
+var knownMethods = map[string]bool{
+	http.MethodGet: true, http.MethodHead: true, http.MethodPost: true,
+	http.MethodPut: true, http.MethodPatch: true, http.MethodDelete: true,
+	http.MethodConnect: true, http.MethodOptions: true, http.MethodTrace: true,
+}
+
+// Metrics holds the collectors the server exports. Its registry is private,
+// so tests and multiple servers in one process do not collide.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests   *prometheus.CounterVec
+	httpDuration   *prometheus.HistogramVec
+	rateLimited    *prometheus.CounterVec
+	events         *prometheus.CounterVec
+	logins         *prometheus.CounterVec
+	handlerFailure *prometheus.CounterVec
+}
+
+// New creates Metrics with Go runtime and process collectors registered
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "HTTP requests by method, route pattern and status.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency by method, route pattern and status.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		rateLimited: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_rate_limited_total",
+			Help: "Requests rejected by the rate limiter, by policy.",
+		}, []string{"policy"}),
+		events: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "domain_events_total",
+			Help: "Domain events by type.",
+		}, []string{"type"}),
+		logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "auth_logins_total",
+			Help: "Login attempts by result and failure reason.",
+		}, []string{"result", "reason"}),
+		handlerFailure: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "event_handler_failures_total",
+			Help: "Event bus handler errors by subscriber and event type.",
+		}, []string{"handler", "type"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.rateLimited,
+		m.events,
+		m.logins,
+		m.handlerFailure,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus text format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// ObserveRequest records a completed HTTP request. route is the matched
+// pattern, or "" if none matched; non-standard methods count as OTHER.
+func (m *Metrics) ObserveRequest(method, route string, status int, elapsed time.Duration) {
+	if route == "" {
+		route = unmatchedRoute
+	}
+	if !knownMethods[method] {
+		method = otherMethod
+	}
+	code := strconv.Itoa(status)
+	m.httpRequests.WithLabelValues(method, route, code).Inc()
+	m.httpDuration.WithLabelValues(method, route, code).Observe(elapsed.Seconds())
+}
+
+// RateLimited records a request rejected by the named rate limit policy
+func (m *Metrics) RateLimited(policy string) {
+	m.rateLimited.WithLabelValues(policy).Inc()
+}
+
+// CountEvent records a domain event
+func (m *Metrics) CountEvent(event domain.DomainEvent) {
+	m.events.WithLabelValues(event.EventType()).Inc()
+}
+
+// LoginSucceeded records a successful login
+func (m *Metrics) LoginSucceeded() {
+	m.logins.WithLabelValues("success", "").Inc()
+}
+
+// LoginFailed records a rejected login
+func (m *Metrics) LoginFailed(reason string) {
+	m.logins.WithLabelValues("failure", reason).Inc()
+}
+
+// InstrumentHandler counts the errors h returns under name
+func (m *Metrics) InstrumentHandler(name string, h domain.EventHandler) domain.EventHandler {
+	return func(ctx context.Context, event domain.DomainEvent) error {
+		err := h(ctx, event)
+		if err != nil {
+			m.handlerFailure.WithLabelValues(name, event.EventType()).Inc()
+		}
+		return err
+	}
+}
+
+// RegisterDB exports the connection pool statistics of db
+func (m *Metrics) RegisterDB(name string, db *sql.DB) error {
+	return m.registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// RegisterRedis exports the connection pool statistics of client
+func (m *Metrics) RegisterRedis(client redis.UniversalClient) error {
+	return m.registry.Register(&redisPoolCollector{client: client})
+}
+
+var (
+	redisHits     = prometheus.NewDesc("redis_pool_hits_total", "Times a free connection was found in the pool.", nil, nil)
+	redisMisses   = prometheus.NewDesc("redis_pool_misses_total", "Times a free connection was not found in the pool.", nil, nil)
+	redisTimeouts = prometheus.NewDesc("redis_pool_timeouts_total", "Times a wait for a connection timed out.", nil, nil)
+	redisTotal    = prometheus.NewDesc("redis_pool_connections", "Connections in the pool.", nil, nil)
+	redisIdle     = prometheus.NewDesc("redis_pool_idle_connections", "Idle connections in the pool.", nil, nil)
+	redisStale    = prometheus.NewDesc("redis_pool_stale_connections_total", "Stale connections removed from the pool.", nil, nil)
+)
+
+// redisPoolCollector reads the pool statistics once per scrape. For a
+// cluster client they are summed over every node.
+type redisPoolCollector struct {
+	client redis.UniversalClient
+}
+
+func (c *redisPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{redisHits, redisMisses, redisTimeouts, redisTotal, redisIdle, redisStale} {
+		ch <- d
+	}
+}
+
+func (c *redisPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.client.PoolStats()
+	ch <- prometheus.MustNewConstMetric(redisHits, prometheus.CounterValue, float64(s.Hits))
+	ch <- prometheus.MustNewConstMetric(redisMisses, prometheus.CounterValue, float64(s.Misses))
+	ch <- prometheus.MustNewConstMetric(redisTimeouts, prometheus.CounterValue, float64(s.Timeouts))
+	ch <- prometheus.MustNewConstMetric(redisTotal, prometheus.GaugeValue, float64(s.TotalConns))
+	ch <- prometheus.MustNewConstMetric(redisIdle, prometheus.GaugeValue, float64(s.IdleConns))
+	ch <- prometheus.MustNewConstMetric(redisStale, prometheus.CounterValue, float64(s.StaleConns))
+}
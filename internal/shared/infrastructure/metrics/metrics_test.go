@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/junghwan16/test-server/internal/shared/domain"
+)
+
+type testEvent struct {
+	domain.BaseEvent
+}
+
+func (e testEvent) EventType() string { return "test.event" }
+
+func TestMetrics_ObserveRequest(t *testing.T) {
+	// Given: 새 지표
+	m := New()
+
+	// When: 라우트가 있는 요청과 없는 요청
+	m.ObserveRequest("GET", "GET /items/{id}", 200, 10*time.Millisecond)
+	m.ObserveRequest("GET", "", 404, time.Millisecond)
+
+	// Then: 라우트 패턴 또는 unmatched 로 집계됨
+	if got := testutil.ToFloat64(m.httpRequests.WithLabelValues("GET", "GET /items/{id}", "200")); got != 1 {
+		t.Errorf("expected 1 matched request, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.httpRequests.WithLabelValues("GET", "unmatched", "404")); got != 1 {
+		t.Errorf("expected 1 unmatched request, got %v", got)
+	}
+
+	t.Run("표준이 아닌 메서드", func(t *testing.T) {
+		// When: 임의의 메서드로 요청
+		m.ObserveRequest("FOO", "", 405, time.Millisecond)
+		m.ObserveRequest("BAR", "", 405, time.Millisecond)
+
+		// Then: 메서드 라벨이 OTHER 하나로 묶임
+		if got := testutil.ToFloat64(m.httpRequests.WithLabelValues("OTHER", "unmatched", "405")); got != 2 {
+			t.Errorf("expected 2 requests labelled OTHER, got %v", got)
+		}
+		if got := testutil.CollectAndCount(m.httpRequests); got != 3 {
+			t.Errorf("expected 3 label sets, got %d", got)
+		}
+	})
+}
+
+func TestMetrics_InstrumentHandler(t *testing.T) {
+	// Given: 실패하는 핸들러
+	m := New()
+	h := m.InstrumentHandler("cache", func(context.Context, domain.DomainEvent) error {
+		return errors.New("boom")
+	})
+
+	// When: 이벤트 전달
+	err := h(context.Background(), testEvent{BaseEvent: domain.NewBaseEvent()})
+
+	// Then: 오류를 그대로 돌려주고 실패가 집계됨
+	if err == nil {
+		t.Error("expected the handler error to be returned")
+	}
+	if got := testutil.ToFloat64(m.handlerFailure.WithLabelValues("cache", "test.event")); got != 1 {
+		t.Errorf("expected 1 failure, got %v", got)
+	}
+}
+
+func TestMetrics_Handler(t *testing.T) {
+	// Given: Redis 풀 지표를 등록한 지표
+	m := New()
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:0"})
+	defer client.Close()
+	if err := m.RegisterRedis(client); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	m.LoginFailed("wrong_password")
+
+	// When: 스크레이프
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	// Then: Prometheus 텍스트 형식으로 노출됨
+	body := rec.Body.String()
+	for _, want := range []string{
+		`auth_logins_total{reason="wrong_password",result="failure"} 1`,
+		"redis_pool_connections 0",
+		"go_goroutines",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected metrics to contain %q", want)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package migrate
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	_ "github.com/glebarez/go-sqlite"
+)
+
+func TestLoad(t *testing.T) {
+	t.Run("버전 순으로 정렬", func(t *testing.T) {
+		// Given: 순서가 섞인 마이그레이션 파일
+		fsys := fstest.MapFS{
+			"0002_add_index.up.sql":     {Data: []byte("CREATE INDEX ...")},
+			"0002_add_index.down.sql":   {Data: []byte("DROP INDEX ...")},
+			"0001_create_users.up.sql":  {Data: []byte("CREATE TABLE users ...")},
+			"0010_backfill.up.sql":      {Data: []byte("UPDATE users ...")},
+			"README.md":                 {Data: []byte("ignored")},
+			"0001_create_users.down.sq": {Data: []byte("ignored")},
+		}
+
+		// When: 로드
+		migrations, err := Load(fsys, "postgres")
+
+		// Then: 버전 순서대로 반환
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(migrations) != 3 {
+			t.Fatalf("expected 3 migrations, got %d", len(migrations))
+		}
+		want := []int64{1, 2, 10}
+		for i, m := range migrations {
+			if m.Version != want[i] {
+				t.Errorf("expected version %d at %d, got %d", want[i], i, m.Version)
+			}
+		}
+		if migrations[1].Down == "" {
+			t.Error("expected down script for version 2")
+		}
+		if migrations[2].Down != "" {
+			t.Error("expected no down script for version 10")
+		}
+	})
+
+	t.Run("중복 버전", func(t *testing.T) {
+		// Given: 같은 버전의 다른 이름
+		fsys := fstest.MapFS{
+			"0001_a.up.sql": {Data: []byte("SELECT 1")},
+			"0001_b.up.sql": {Data: []byte("SELECT 1")},
+		}
+
+		// When: 로드
+		_, err := Load(fsys, "postgres")
+
+		// Then: 에러 발생
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+
+	t.Run("방언별 스크립트 우선", func(t *testing.T) {
+		// Given: 공용 스크립트와 방언별 변형
+		fsys := fstest.MapFS{
+			"0001_create_users.up.sql":           {Data: []byte("shared up")},
+			"0001_create_users.down.sql":         {Data: []byte("shared down")},
+			"0001_create_users.sqlite.up.sql":    {Data: []byte("sqlite up")},
+			"0001_create_users.postgres.up.sql":  {Data: []byte("postgres up")},
+			"0002_only_postgres.postgres.up.sql": {Data: []byte("postgres only")},
+			"0002_only_postgres.sqlite.up.sql":   {Data: []byte("sqlite only")},
+		}
+
+		// When: sqlite 방언으로 로드
+		migrations, err := Load(fsys, "sqlite")
+
+		// Then: 변형이 공용 스크립트를 대체하고 다른 방언은 무시됨
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(migrations) != 2 {
+			t.Fatalf("expected 2 migrations, got %d", len(migrations))
+		}
+		if migrations[0].Up != "sqlite up" {
+			t.Errorf("expected sqlite variant, got %q", migrations[0].Up)
+		}
+		if migrations[0].Down != "shared down" {
+			t.Errorf("expected shared down script, got %q", migrations[0].Down)
+		}
+		if migrations[1].Up != "sqlite only" {
+			t.Errorf("expected sqlite variant, got %q", migrations[1].Up)
+		}
+	})
+
+	t.Run("up 스크립트 누락", func(t *testing.T) {
+		// Given: down 만 있는 마이그레이션
+		fsys := fstest.MapFS{
+			"0001_a.down.sql": {Data: []byte("SELECT 1")},
+		}
+
+		// When: 로드
+		_, err := Load(fsys, "postgres")
+
+		// Then: 에러 발생
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
+
+func TestCreate(t *testing.T) {
+	// Given: 기존 마이그레이션이 있는 디렉터리
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "0003_existing.up.sql"), []byte("SELECT 1"), 0o644)
+
+	// When: 새 마이그레이션 생성
+	up, down, err := Create(dir, "Add User Version")
+
+	// Then: 다음 버전으로 up/down 파일 생성
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if filepath.Base(up) != "0004_add_user_version.up.sql" {
+		t.Errorf("unexpected up file %s", up)
+	}
+	if filepath.Base(down) != "0004_add_user_version.down.sql" {
+		t.Errorf("unexpected down file %s", down)
+	}
+
+	migrations, err := Load(os.DirFS(dir), "postgres")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(migrations) != 2 {
+		t.Errorf("expected 2 migrations, got %d", len(migrations))
+	}
+}
+
+func TestMigrator_SQLite(t *testing.T) {
+	// Given: 빈 SQLite 데이터베이스와 두 개의 마이그레이션
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	fsys := fstest.MapFS{
+		"0001_create_items.up.sql":   {Data: []byte("CREATE TABLE items (id INTEGER PRIMARY KEY)")},
+		"0001_create_items.down.sql": {Data: []byte("DROP TABLE items")},
+		"0002_add_name.up.sql":       {Data: []byte("ALTER TABLE items ADD COLUMN name TEXT")},
+		"0002_add_name.down.sql":     {Data: []byte("ALTER TABLE items DROP COLUMN name")},
+	}
+	migrator, err := New(db, fsys, SQLite)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	ctx := t.Context()
+
+	// When: 모두 적용
+	applied, err := migrator.Up(ctx)
+
+	// Then: 순서대로 적용되고 최신 상태
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(applied) != 2 {
+		t.Fatalf("expected 2 applied, got %d", len(applied))
+	}
+	if err := migrator.CheckCurrent(ctx); err != nil {
+		t.Errorf("expected schema to be current, got %v", err)
+	}
+	if _, err := db.ExecContext(ctx, "INSERT INTO items (name) VALUES ('a')"); err != nil {
+		t.Errorf("expected migrated table, got %v", err)
+	}
+
+	// When: 한 단계 롤백
+	reverted, err := migrator.Down(ctx, 1)
+
+	// Then: 마지막 마이그레이션만 대기 상태
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(reverted) != 1 || reverted[0].Version != 2 {
+		t.Fatalf("expected version 2 reverted, got %+v", reverted)
+	}
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !statuses[0].Applied || statuses[0].AppliedAt.IsZero() {
+		t.Errorf("expected version 1 applied with timestamp, got %+v", statuses[0])
+	}
+	if statuses[1].Applied {
+		t.Error("expected version 2 pending")
+	}
+}
@@ -0,0 +1,157 @@
+// Package ratelimit counts requests against limits using the generic cell
+// rate algorithm (GCRA), in process memory or shared through Redis
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Limit allows Rate requests per Period on average, and up to Burst at once
+type Limit struct {
+	Rate   float64
+	Period time.Duration
+	Burst  int
+}
+
+// Interval is the time one request's allowance takes to replenish
+func (l Limit) Interval() time.Duration {
+	return time.Duration(float64(l.Period) / l.Rate)
+}
+
+// Window is the time an empty bucket takes to refill the full burst
+func (l Limit) Window() time.Duration {
+	return time.Duration(l.Burst) * l.Interval()
+}
+
+// Result is the outcome of counting one request
+type Result struct {
+	Allowed    bool
+	Remaining  int           // requests still allowed right now
+	RetryAfter time.Duration // until the next request is allowed; 0 if this one was
+	ResetAfter time.Duration // until the full burst is available again
+}
+
+// Limiter counts a request under key against l
+type Limiter interface {
+	Allow(ctx context.Context, key string, l Limit) (Result, error)
+}
+
+// gcra counts a request arriving at now against a key whose theoretical
+// arrival time is tat, returning the result and the key's new tat
+func gcra(now, tat time.Time, l Limit) (Result, time.Time) {
+	interval := l.Interval()
+	if tat.Before(now) {
+		tat = now
+	}
+	newTAT := tat.Add(interval)
+	allowAt := newTAT.Add(-l.Window())
+
+	diff := now.Sub(allowAt)
+	if diff < 0 {
+		return Result{RetryAfter: -diff, ResetAfter: tat.Sub(now)}, tat
+	}
+	return Result{Allowed: true, Remaining: int(diff / interval), ResetAfter: newTAT.Sub(now)}, newTAT
+}
+
+// Memory keeps limits in process memory, so each replica counts on its own.
+// Call Prune periodically to drop idle keys.
+type Memory struct {
+	now func() time.Time
+
+	mu  sync.Mutex
+	tat map[string]time.Time
+}
+
+// NewMemory creates an empty in-memory limiter
+func NewMemory() *Memory {
+	return &Memory{now: time.Now, tat: make(map[string]time.Time)}
+}
+
+// Allow counts a request under key against l
+func (m *Memory) Allow(_ context.Context, key string, l Limit) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	res, tat := gcra(m.now(), m.tat[key], l)
+	m.tat[key] = tat
+	return res, nil
+}
+
+// Prune drops keys whose bucket has fully refilled; they behave as new keys
+func (m *Memory) Prune(_ context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	for key, tat := range m.tat {
+		if !tat.After(now) {
+			delete(m.tat, key)
+		}
+	}
+	return nil
+}
+
+// Fallback limits through primary and switches to secondary when primary
+// fails, trying primary again once retry has passed
+type Fallback struct {
+	primary   Limiter
+	secondary Limiter
+	retry     time.Duration
+	logger    *slog.Logger
+
+	mu        sync.Mutex
+	downUntil time.Time
+	down      bool
+}
+
+// NewFallback creates a Fallback from primary to secondary
+func NewFallback(primary, secondary Limiter, retry time.Duration, logger *slog.Logger) *Fallback {
+	return &Fallback{primary: primary, secondary: secondary, retry: retry, logger: logger}
+}
+
+// Allow counts a request under key against l
+func (f *Fallback) Allow(ctx context.Context, key string, l Limit) (Result, error) {
+	if f.usePrimary() {
+		res, err := f.primary.Allow(ctx, key, l)
+		if err == nil {
+			f.recovered(ctx)
+			return res, nil
+		}
+		// A cancelled request says nothing about the primary's health
+		if ctx.Err() != nil {
+			return Result{}, err
+		}
+		f.failed(ctx, err)
+	}
+	return f.secondary.Allow(ctx, key, l)
+}
+
+func (f *Fallback) usePrimary() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return !f.down || !time.Now().Before(f.downUntil)
+}
+
+func (f *Fallback) failed(ctx context.Context, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.down {
+		f.logger.WarnContext(ctx, "rate limiter unavailable, limiting in memory", "error", err)
+	}
+	f.down = true
+	f.downUntil = time.Now().Add(f.retry)
+}
+
+func (f *Fallback) recovered(ctx context.Context) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.down {
+		f.logger.InfoContext(ctx, "rate limiter recovered")
+	}
+	f.down = false
+}
@@ -0,0 +1,196 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+var perSecond = Limit{Rate: 1, Period: time.Second, Burst: 3}
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("버스트 후 거부", func(t *testing.T) {
+		// Given: 시간이 멈춘 리미터
+		now := time.Now()
+		m := NewMemory()
+		m.now = func() time.Time { return now }
+
+		// When: 버스트만큼 요청
+		for i := range perSecond.Burst {
+			res, _ := m.Allow(ctx, "k", perSecond)
+			if !res.Allowed || res.Remaining != perSecond.Burst-1-i {
+				t.Fatalf("request %d: expected allowed with %d remaining, got %+v", i, perSecond.Burst-1-i, res)
+			}
+		}
+		res, _ := m.Allow(ctx, "k", perSecond)
+
+		// Then: 다음 요청은 거부되고 1초 뒤 재시도
+		if res.Allowed {
+			t.Fatal("expected the request over the burst to be rejected")
+		}
+		if res.RetryAfter != time.Second {
+			t.Errorf("expected retry after 1s, got %v", res.RetryAfter)
+		}
+		if res.ResetAfter != 3*time.Second {
+			t.Errorf("expected reset after 3s, got %v", res.ResetAfter)
+		}
+	})
+
+	t.Run("시간이 지나면 회복", func(t *testing.T) {
+		// Given: 버스트를 모두 쓴 키
+		now := time.Now()
+		m := NewMemory()
+		m.now = func() time.Time { return now }
+		for range perSecond.Burst {
+			m.Allow(ctx, "k", perSecond)
+		}
+
+		// When: 한 간격이 지남
+		now = now.Add(time.Second)
+		res, _ := m.Allow(ctx, "k", perSecond)
+
+		// Then: 한 요청 허용
+		if !res.Allowed || res.Remaining != 0 {
+			t.Errorf("expected one request allowed, got %+v", res)
+		}
+	})
+
+	t.Run("키마다 따로 계산", func(t *testing.T) {
+		// Given: 버스트를 모두 쓴 키
+		m := NewMemory()
+		for range perSecond.Burst {
+			m.Allow(ctx, "a", perSecond)
+		}
+
+		// When: 다른 키로 요청
+		res, _ := m.Allow(ctx, "b", perSecond)
+
+		// Then: 허용
+		if !res.Allowed {
+			t.Error("expected another key to have its own budget")
+		}
+	})
+
+	t.Run("다 찬 키 정리", func(t *testing.T) {
+		// Given: 한 키는 가득 찼고 다른 키는 아직 회복 중
+		now := time.Now()
+		m := NewMemory()
+		m.now = func() time.Time { return now }
+		m.Allow(ctx, "idle", Limit{Rate: 1, Period: time.Second, Burst: 1})
+		m.Allow(ctx, "busy", Limit{Rate: 1, Period: time.Minute, Burst: 1})
+
+		// When: 2초 뒤 정리
+		now = now.Add(2 * time.Second)
+		m.Prune(ctx)
+
+		// Then: 가득 찬 키만 삭제
+		if _, ok := m.tat["idle"]; ok {
+			t.Error("expected the refilled key to be pruned")
+		}
+		if _, ok := m.tat["busy"]; !ok {
+			t.Error("expected the draining key to be kept")
+		}
+	})
+}
+
+type failingLimiter struct{ calls int }
+
+func (f *failingLimiter) Allow(context.Context, string, Limit) (Result, error) {
+	f.calls++
+	return Result{}, errors.New("connection refused")
+}
+
+func TestFallback(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("주 저장소 장애 시 메모리 사용", func(t *testing.T) {
+		// Given: 실패하는 주 리미터
+		primary := &failingLimiter{}
+		f := NewFallback(primary, NewMemory(), time.Minute, logger)
+
+		// When: 요청 두 번
+		res, err := f.Allow(ctx, "k", perSecond)
+		f.Allow(ctx, "k", perSecond)
+
+		// Then: 메모리로 허용하고 재시도 전까지 주 리미터를 건너뜀
+		if err != nil || !res.Allowed {
+			t.Fatalf("expected allowed from memory, got %+v, %v", res, err)
+		}
+		if primary.calls != 1 {
+			t.Errorf("expected the primary to be skipped after failing, got %d calls", primary.calls)
+		}
+	})
+
+	t.Run("재시도 시간 후 주 저장소 재시도", func(t *testing.T) {
+		// Given: 재시도 간격이 0인 Fallback
+		primary := &failingLimiter{}
+		f := NewFallback(primary, NewMemory(), 0, logger)
+
+		// When: 요청 두 번
+		f.Allow(ctx, "k", perSecond)
+		f.Allow(ctx, "k", perSecond)
+
+		// Then: 매번 주 리미터를 시도
+		if primary.calls != 2 {
+			t.Errorf("expected the primary to be retried, got %d calls", primary.calls)
+		}
+	})
+}
+
+func TestRedis(t *testing.T) {
+	addr := os.Getenv("REDIS_TEST_ADDR")
+	if addr == "" {
+		addr = "localhost:6379"
+	}
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	t.Cleanup(func() { client.Close() })
+	ctx := context.Background()
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skipf("redis not available at %s: %v", addr, err)
+	}
+
+	t.Run("버스트 후 거부", func(t *testing.T) {
+		// Given: 새 키
+		r := NewRedis(client)
+		key := fmt.Sprintf("test:%s", uuid.NewString())
+		t.Cleanup(func() { client.Del(ctx, keyPrefix+key) })
+		slow := Limit{Rate: 1, Period: time.Minute, Burst: 3}
+
+		// When: 버스트만큼 요청한 뒤 한 번 더
+		for i := range slow.Burst {
+			res, err := r.Allow(ctx, key, slow)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !res.Allowed || res.Remaining != slow.Burst-1-i {
+				t.Fatalf("request %d: expected allowed with %d remaining, got %+v", i, slow.Burst-1-i, res)
+			}
+		}
+		res, err := r.Allow(ctx, key, slow)
+
+		// Then: 거부되고 약 1분 뒤 재시도
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if res.Allowed {
+			t.Fatal("expected the request over the burst to be rejected")
+		}
+		if res.RetryAfter <= 59*time.Second || res.RetryAfter > time.Minute {
+			t.Errorf("expected retry after about 1m, got %v", res.RetryAfter)
+		}
+		if ttl := client.PTTL(ctx, keyPrefix+key).Val(); ttl <= 0 || ttl > 3*time.Minute {
+			t.Errorf("expected the key to expire once refilled, got TTL %v", ttl)
+		}
+	})
+}
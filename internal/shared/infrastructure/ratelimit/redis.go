@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// keyPrefix namespaces limiter keys in Redis
+const keyPrefix = "ratelimit:"
+
+// gcraScript is gcra in Lua, run atomically against the key's stored
+// theoretical arrival time. Times are microseconds from the Redis clock so
+// replicas with skewed clocks still agree.
+var gcraScript = redis.NewScript(`
+local interval = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+
+local tat = tonumber(redis.call("GET", KEYS[1])) or now
+if tat < now then
+  tat = now
+end
+local new_tat = tat + interval
+local diff = now - (new_tat - window)
+
+if diff < 0 then
+  return {0, 0, -diff, tat - now}
+end
+redis.call("SET", KEYS[1], string.format("%.0f", new_tat), "PX", math.ceil((new_tat - now) / 1000))
+return {1, math.floor(diff / interval), 0, new_tat - now}
+`)
+
+// Redis keeps limits in Redis, so every replica counts against the same budget
+type Redis struct {
+	client redis.UniversalClient
+}
+
+// NewRedis creates a limiter backed by client
+func NewRedis(client redis.UniversalClient) *Redis {
+	return &Redis{client: client}
+}
+
+// Allow counts a request under key against l
+func (r *Redis) Allow(ctx context.Context, key string, l Limit) (Result, error) {
+	vals, err := gcraScript.Run(ctx, r.client, []string{keyPrefix + key},
+		l.Interval().Microseconds(), l.Window().Microseconds()).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{
+		Allowed:    vals[0] == 1,
+		Remaining:  int(vals[1]),
+		RetryAfter: time.Duration(vals[2]) * time.Microsecond,
+		ResetAfter: time.Duration(vals[3]) * time.Microsecond,
+	}, nil
+}
@@ -0,0 +1,108 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Job is a unit of periodic background work
+type Job func(ctx context.Context) error
+
+type entry struct {
+	name     string
+	interval time.Duration
+	job      Job
+}
+
+// Scheduler runs registered jobs at fixed intervals until closed.
+// A job never overlaps with itself; a slow run delays the next one.
+type Scheduler struct {
+	logger  *slog.Logger
+	timeout time.Duration
+
+	mu      sync.Mutex
+	entries []entry
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// New creates a Scheduler; each run is cancelled after timeout (0 disables it)
+func New(logger *slog.Logger, timeout time.Duration) *Scheduler {
+	return &Scheduler{logger: logger, timeout: timeout}
+}
+
+// Every registers job to run once per interval. Must be called before Start.
+func (s *Scheduler) Every(name string, interval time.Duration, job Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entry{name: name, interval: interval, job: job})
+}
+
+// Start launches one loop per registered job
+func (s *Scheduler) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		return errors.New("scheduler already started")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+
+	for _, e := range s.entries {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.loop(ctx, e)
+		}()
+	}
+
+	return nil
+}
+
+// Close stops all loops and waits for running jobs to finish
+func (s *Scheduler) Close() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, e entry) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.run(ctx, e)
+		}
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, e entry) {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	if err := e.job(ctx); err != nil {
+		if ctx.Err() == nil || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			s.logger.Error("scheduler: job failed", "job", e.name, "error", err)
+		}
+		return
+	}
+	s.logger.Debug("scheduler: job finished", "job", e.name, "duration", time.Since(start))
+}
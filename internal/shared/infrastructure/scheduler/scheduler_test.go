@@ -0,0 +1,57 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScheduler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("주기적으로 실행", func(t *testing.T) {
+		// Given: 10ms 마다 실행되는 작업 (실패해도 계속 실행)
+		s := New(logger, time.Second)
+		var runs atomic.Int32
+		s.Every("count", 10*time.Millisecond, func(ctx context.Context) error {
+			runs.Add(1)
+			return errors.New("boom")
+		})
+
+		// When: 시작 후 잠시 대기
+		if err := s.Start(t.Context()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		time.Sleep(100 * time.Millisecond)
+		s.Close()
+
+		// Then: 여러 번 실행되고 Close 이후에는 멈춤
+		got := runs.Load()
+		if got < 3 {
+			t.Errorf("expected at least 3 runs, got %d", got)
+		}
+		time.Sleep(30 * time.Millisecond)
+		if runs.Load() != got {
+			t.Error("expected no runs after Close")
+		}
+	})
+
+	t.Run("중복 시작", func(t *testing.T) {
+		// Given: 이미 시작된 스케줄러
+		s := New(logger, 0)
+		s.Start(t.Context())
+		defer s.Close()
+
+		// When: 다시 시작
+		err := s.Start(t.Context())
+
+		// Then: 에러 발생
+		if err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,48 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+)
+
+func TestSetup(t *testing.T) {
+	t.Run("파일 내보내기", func(t *testing.T) {
+		// Given: 파일 내보내기 설정
+		path := filepath.Join(t.TempDir(), "traces.jsonl")
+		shutdown, err := Setup(context.Background(), Options{Exporter: "file", File: path, SampleRatio: 1, ServiceName: "test"})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		// When: 스팬을 만들고 종료
+		_, span := otel.Tracer("test").Start(context.Background(), "work")
+		span.End()
+		if err := shutdown(context.Background()); err != nil {
+			t.Fatalf("expected no error on shutdown, got %v", err)
+		}
+
+		// Then: 스팬이 파일에 기록됨
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("expected trace file, got %v", err)
+		}
+		if !strings.Contains(string(data), `"Name":"work"`) {
+			t.Errorf("expected span in file, got %s", data)
+		}
+	})
+
+	t.Run("알 수 없는 내보내기", func(t *testing.T) {
+		// When: 지원하지 않는 내보내기
+		_, err := Setup(context.Background(), Options{Exporter: "zipkin"})
+
+		// Then: 오류
+		if err == nil {
+			t.Error("expected an error for an unknown exporter")
+		}
+	})
+}
@@ -0,0 +1,86 @@
+// Package problem writes error responses as RFC 7807 problem details
+// (application/problem+json), so clients can branch on a stable code
+// instead of matching human-readable messages.
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/junghwan16/test-server/internal/shared/requestid"
+)
+
+// ContentType is the media type of a problem details response
+const ContentType = "application/problem+json"
+
+// typePrefix turns a code into the problem type URI
+const typePrefix = "urn:problem-type:"
+
+// Problem is an RFC 7807 problem details object. Code, Errors and RequestID
+// are extension members; Code is stable and safe to branch on.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	Errors    []FieldError `json:"errors,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+}
+
+// FieldError describes one invalid request field
+type FieldError struct {
+	Field  string `json:"field"`
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+}
+
+// New creates a Problem with the given status, stable code and short title
+func New(status int, code, title string) *Problem {
+	return &Problem{
+		Type:   typePrefix + code,
+		Title:  title,
+		Status: status,
+		Code:   code,
+	}
+}
+
+// WithDetail returns a copy of p explaining this occurrence of the problem
+func (p *Problem) WithDetail(detail string) *Problem {
+	c := *p
+	c.Detail = detail
+	return &c
+}
+
+// WithErrors returns a copy of p listing the invalid fields
+func (p *Problem) WithErrors(errs ...FieldError) *Problem {
+	c := *p
+	c.Errors = errs
+	return &c
+}
+
+// Write sends p as the response, filling in the request path and ID
+func Write(w http.ResponseWriter, r *http.Request, p *Problem) {
+	c := *p
+	if c.Instance == "" {
+		c.Instance = r.URL.Path
+	}
+	c.RequestID = requestid.FromContext(r.Context())
+
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(c.Status)
+	json.NewEncoder(w).Encode(c)
+}
+
+// Common problems shared by every module
+var (
+	MalformedRequest     = New(http.StatusBadRequest, "malformed_request", "Request body is not valid JSON")
+	Validation           = New(http.StatusBadRequest, "validation_failed", "Request has invalid fields")
+	Unauthenticated      = New(http.StatusUnauthorized, "unauthenticated", "Authentication required")
+	Forbidden            = New(http.StatusForbidden, "forbidden", "Not allowed")
+	UnsupportedMediaType = New(http.StatusUnsupportedMediaType, "unsupported_media_type", "Unsupported content type")
+	TooManyRequests      = New(http.StatusTooManyRequests, "rate_limited", "Too many requests")
+	Internal             = New(http.StatusInternalServerError, "internal_error", "Internal server error")
+)
@@ -0,0 +1,56 @@
+// Package requestid carries the ID that correlates a request across logs,
+// error responses and everything the request triggers.
+package requestid
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Header is the HTTP header the request ID travels in
+const Header = "X-Request-ID"
+
+// LogKey is the attribute the request ID is logged under
+const LogKey = "request_id"
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying id
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID stored in ctx, or "" if there is none
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// LogHandler adds the request ID found in the record's context to every
+// record. Log with the *Context methods so the context reaches it.
+type LogHandler struct {
+	slog.Handler
+}
+
+// NewLogHandler wraps h
+func NewLogHandler(h slog.Handler) *LogHandler {
+	return &LogHandler{Handler: h}
+}
+
+// Handle adds the request ID and passes the record on
+func (h *LogHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := FromContext(ctx); id != "" {
+		r.AddAttrs(slog.String(LogKey, id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs keeps the wrapper around the derived handler
+func (h *LogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &LogHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup keeps the wrapper around the derived handler
+func (h *LogHandler) WithGroup(name string) slog.Handler {
+	return &LogHandler{Handler: h.Handler.WithGroup(name)}
+}
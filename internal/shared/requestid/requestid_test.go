@@ -0,0 +1,36 @@
+package requestid
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewLogHandler(slog.NewTextHandler(&buf, nil))).With("component", "test")
+
+	t.Run("요청 ID 있음", func(t *testing.T) {
+		// When: 요청 ID 가 담긴 컨텍스트로 로그
+		buf.Reset()
+		logger.InfoContext(NewContext(context.Background(), "req-1"), "hello")
+
+		// Then: 레코드에 request_id 가 붙고 파생 속성도 유지됨
+		if !strings.Contains(buf.String(), "request_id=req-1") || !strings.Contains(buf.String(), "component=test") {
+			t.Errorf("expected request_id and component, got %q", buf.String())
+		}
+	})
+
+	t.Run("요청 ID 없음", func(t *testing.T) {
+		// When: 빈 컨텍스트로 로그
+		buf.Reset()
+		logger.InfoContext(context.Background(), "hello")
+
+		// Then: request_id 없음
+		if strings.Contains(buf.String(), "request_id") {
+			t.Errorf("expected no request_id, got %q", buf.String())
+		}
+	})
+}